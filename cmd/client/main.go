@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 	"log/slog"
-	"net"
 	"os"
 
 	"github.com/tmvrus/key-value-storage/pkg/client"
@@ -15,16 +14,10 @@ func main() {
 	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
 	var serverAddr string
-	flag.StringVar(&serverAddr, "address", defaultConnectionAddr, "")
+	flag.StringVar(&serverAddr, "address", defaultConnectionAddr, "host:port or unix:///path/to.sock")
 	flag.Parse()
 
-	remoteAddr, err := net.ResolveTCPAddr("tcp", serverAddr)
-	if err != nil {
-		log.Error("failed to resolve server address", "address", serverAddr, "error", err.Error())
-		os.Exit(1)
-	}
-
-	con, err := net.DialTCP("tcp", nil, remoteAddr)
+	con, err := client.Dial(serverAddr)
 	if err != nil {
 		log.Error("failed to dial", "address", serverAddr, "error", err.Error())
 		os.Exit(1)
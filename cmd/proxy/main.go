@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/logger"
+	"github.com/tmvrus/key-value-storage/internal/proxy"
+)
+
+func main() {
+	cxt, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer cancel()
+
+	cmdline := config.NewCommandLine(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := cmdline.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err.Error())
+		os.Exit(1)
+	}
+	if cmdline.Print {
+		data, err := cfg.Marshal()
+		if err != nil {
+			slog.Error("failed to marshal config", "error", err.Error())
+			os.Exit(1)
+		}
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+
+	log := logger.New(cfg)
+	defer log.Close()
+
+	usr1 := make(chan os.Signal, 1)
+	signal.Notify(usr1, syscall.SIGUSR1)
+	defer signal.Stop(usr1)
+	go log.ReopenOnSignal(cxt, usr1)
+
+	p, err := proxy.New(cfg, log.Logger)
+	if err != nil {
+		log.Error("failed to create proxy", "error", err.Error())
+		_ = log.Close()
+		os.Exit(1)
+	}
+
+	if err = p.Run(cxt); err != nil {
+		log.Error("failed to run proxy", "error", err.Error())
+		_ = log.Close()
+		os.Exit(1)
+	}
+}
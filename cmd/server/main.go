@@ -12,33 +12,90 @@ import (
 	"github.com/tmvrus/key-value-storage/internal/logger"
 	"github.com/tmvrus/key-value-storage/internal/server"
 	"github.com/tmvrus/key-value-storage/internal/storage"
+	"github.com/tmvrus/key-value-storage/internal/tracing"
 )
 
-const defaultConfigFile = "./config.yml"
-
 func main() {
 	cxt, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM)
 	defer cancel()
 
-	var configFile string
-	flag.StringVar(&configFile, "config", defaultConfigFile, "")
+	cmdline := config.NewCommandLine(flag.CommandLine)
 	flag.Parse()
 
-	cfg := config.NewConfigWithDefaults()
-	err := config.FillWithFile(cfg, configFile)
+	cfg, err := cmdline.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err.Error())
+		os.Exit(1)
+	}
+	if cmdline.Print {
+		data, err := cfg.Marshal()
+		if err != nil {
+			slog.Error("failed to marshal config", "error", err.Error())
+			os.Exit(1)
+		}
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+
+	log := logger.New(cfg)
+	defer log.Close()
+
+	shutdownTracing, err := tracing.Setup(cxt, cfg, os.Stdout)
 	if err != nil {
-		slog.Error("failed to fill config, use default values", "error", err.Error())
+		log.Error("failed to set up tracing", "error", err.Error())
+		_ = log.Close()
+		os.Exit(1)
 	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error("failed to shut down tracing", "error", err.Error())
+		}
+	}()
 
-	log := logger.New(cfg.Logging.Output, cfg.Logging.Level)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
-	err = server.
-		New(cfg, storage.New(cfg), log).
-		Run(cxt)
+	usr1 := make(chan os.Signal, 1)
+	signal.Notify(usr1, syscall.SIGUSR1)
+	defer signal.Stop(usr1)
+	go log.ReopenOnSignal(cxt, usr1)
+
+	srv := server.New(cfg, storage.New(cfg), log.Logger)
+	go reload(cxt, hup, cmdline, srv, log)
+
+	err = srv.Run(cxt)
 
 	if err != nil {
 		log.Error("failed to run application", "error", err.Error())
+		_ = log.Close()
 		os.Exit(1)
 	}
 
 }
+
+// reload reloads the config on every signal, flags and environment variables
+// keep overriding the file. An invalid config is logged and ignored.
+func reload(cxt context.Context, signals <-chan os.Signal, cmdline *config.CommandLine, srv server.Server, log *logger.Logger) {
+	for {
+		select {
+		case <-cxt.Done():
+			return
+		case <-signals:
+		}
+
+		cfg, err := cmdline.Load()
+		if err != nil {
+			log.Error("reloaded config is invalid, keep the current one", "error", err.Error())
+			continue
+		}
+
+		l, err := logger.ParseLevel(cfg.Logging.Level)
+		if err != nil {
+			log.Error("reloaded config is invalid, keep the current one", "error", err.Error())
+			continue
+		}
+		log.Level.Set(l)
+		srv.Reload(cfg)
+	}
+}
@@ -0,0 +1,129 @@
+// Package audit records who changed what to an append-only file, one JSON
+// object per line, apart from the operational log.
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+const hashPrefix = "hmac-sha256:"
+
+// Record is an executed command.
+type Record struct {
+	Time   time.Time
+	Client string
+	// User is empty unless the session is authenticated.
+	User    string
+	Command string
+	Keys    []string
+	// Args are arguments of the command besides keys, values among them.
+	Args []string
+	// Err is the error the command failed with.
+	Err error
+}
+
+type entry struct {
+	Time    string   `json:"time"`
+	Client  string   `json:"client"`
+	User    string   `json:"user,omitempty"`
+	Command string   `json:"command"`
+	Keys    []string `json:"keys,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Result  string   `json:"result"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Log writes records to the file, it is safe for concurrent use.
+type Log struct {
+	hashValues bool
+	hashKey    []byte
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the audit file of the config for appending.
+func Open(cfg *config.Config) (*Log, error) {
+	f, err := os.OpenFile(cfg.Audit.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit file: %w", err)
+	}
+	return &Log{
+		hashValues: cfg.Audit.Values != config.AuditValuesInclude,
+		hashKey:    []byte(cfg.Audit.HashKey),
+		file:       f,
+	}, nil
+}
+
+// Write appends the record with a single write, so a record is either
+// written entirely or not at all when the process crashes.
+func (l *Log) Write(r Record) error {
+	e := entry{
+		Time:    r.Time.UTC().Format(time.RFC3339Nano),
+		Client:  r.Client,
+		User:    r.User,
+		Command: r.Command,
+		Keys:    r.Keys,
+		Args:    r.Args,
+		Result:  "ok",
+	}
+	if l.hashValues {
+		e.Args = hashAll(l.hashKey, r.Args)
+	}
+	if r.Err != nil {
+		e.Result = "error"
+		e.Error = r.Err.Error()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return os.ErrClosed
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+	return nil
+}
+
+// Close syncs the file and closes it.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := errors.Join(l.file.Sync(), l.file.Close())
+	l.file = nil
+	return err
+}
+
+func hashAll(key []byte, values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	res := make([]string, len(values))
+	for i, v := range values {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(v))
+		res[i] = hashPrefix + hex.EncodeToString(mac.Sum(nil))
+	}
+	return res
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	write := func(t *testing.T, values string, r Record) string {
+		cfg := config.NewConfigWithDefaults()
+		cfg.Audit.Output = filepath.Join(t.TempDir(), "audit.log")
+		cfg.Audit.Values = values
+		cfg.Audit.HashKey = "secret"
+
+		l, err := Open(cfg)
+		require.NoError(t, err)
+		require.NoError(t, l.Write(r))
+		require.NoError(t, l.Close())
+		require.ErrorIs(t, l.Write(r), os.ErrClosed)
+
+		info, err := os.Stat(cfg.Audit.Output)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		data, err := os.ReadFile(cfg.Audit.Output)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	r := Record{
+		Time:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Client:  "10.0.0.1:5000",
+		Command: "SET",
+		Keys:    []string{"k"},
+		Args:    []string{"v"},
+	}
+
+	t.Run("hash values", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t,
+			`{"time":"2024-05-01T10:00:00Z","client":"10.0.0.1:5000","command":"SET","keys":["k"],`+
+				`"args":["hmac-sha256:38ff772159e49b67fc04d7f1727ceaca870ae13a29242fcd1f841adff0c782be"],"result":"ok"}`+"\n",
+			write(t, config.AuditValuesHash, r))
+	})
+
+	t.Run("include values", func(t *testing.T) {
+		t.Parallel()
+
+		failed := r
+		failed.User = "alice"
+		failed.Err = errors.New("out of memory")
+		require.Equal(t,
+			`{"time":"2024-05-01T10:00:00Z","client":"10.0.0.1:5000","user":"alice","command":"SET","keys":["k"],`+
+				`"args":["v"],"result":"error","error":"out of memory"}`+"\n",
+			write(t, config.AuditValuesInclude, failed))
+	})
+}
@@ -0,0 +1,250 @@
+// Package cluster assigns hash slots of the keyspace to server nodes and
+// decides whether a command is served locally or redirected.
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCrossSlot   = errors.New("CROSSSLOT keys in request don't hash to the same slot")
+	ErrClusterDown = errors.New("CLUSTERDOWN hash slot is not served")
+	ErrUnknownNode = errors.New("unknown cluster node")
+)
+
+// RedirectError tells the client to send the command to another node: MOVED
+// means the slot is owned by that node, ASK means only the next command should
+// go there while the slot is migrating.
+type RedirectError struct {
+	Ask     bool
+	Slot    int
+	Address string
+}
+
+func (e RedirectError) Error() string {
+	kind := "MOVED"
+	if e.Ask {
+		kind = "ASK"
+	}
+	return fmt.Sprintf("%s %d %s", kind, e.Slot, e.Address)
+}
+
+type Node struct {
+	ID      string
+	Address string
+}
+
+type NodeSlots struct {
+	Node
+	Slots []SlotRange
+}
+
+type Cluster struct {
+	self string
+
+	lock   sync.RWMutex
+	nodes  map[string]Node
+	owners [SlotCount]string
+	// migrating keeps target nodes of slots moving away from this node,
+	// importing keeps source nodes of slots moving to this node.
+	migrating map[int]string
+	importing map[int]string
+	// stateFile keeps assignments and migration states across restarts when set.
+	stateFile string
+
+	guards [SlotCount]sync.RWMutex
+}
+
+func New(self string, nodes []NodeSlots) (*Cluster, error) {
+	c := &Cluster{
+		self:      self,
+		nodes:     make(map[string]Node, len(nodes)),
+		migrating: make(map[int]string),
+		importing: make(map[int]string),
+	}
+
+	for _, n := range nodes {
+		if _, ok := c.nodes[n.ID]; ok {
+			return nil, fmt.Errorf("duplicate node %q", n.ID)
+		}
+		c.nodes[n.ID] = n.Node
+
+		for _, r := range n.Slots {
+			for slot := r.Start; slot <= r.End; slot++ {
+				if owner := c.owners[slot]; owner != "" {
+					return nil, fmt.Errorf("slot %d is assigned to %q and %q", slot, owner, n.ID)
+				}
+				c.owners[slot] = n.ID
+			}
+		}
+	}
+
+	if _, ok := c.nodes[self]; !ok {
+		return nil, fmt.Errorf("node %q: %w", self, ErrUnknownNode)
+	}
+
+	return c, nil
+}
+
+func (c *Cluster) Self() Node {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.nodes[c.self]
+}
+
+func (c *Cluster) Node(id string) (Node, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	n, ok := c.nodes[id]
+	return n, ok
+}
+
+// Nodes returns all nodes ordered by ID.
+func (c *Cluster) Nodes() []Node {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	nodes := slices.Collect(maps.Values(c.nodes))
+	slices.SortFunc(nodes, func(a, b Node) int { return strings.Compare(a.ID, b.ID) })
+	return nodes
+}
+
+// Owner returns the node owning the slot, false when the slot is not assigned.
+func (c *Cluster) Owner(slot int) (Node, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	n, ok := c.nodes[c.owners[slot]]
+	return n, ok
+}
+
+// Slots returns nodes with contiguous ranges of slots they own, ordered by slot.
+func (c *Cluster) Slots() []NodeSlots {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.slots()
+}
+
+func (c *Cluster) slots() []NodeSlots {
+	var result []NodeSlots
+	for slot := 0; slot < SlotCount; slot++ {
+		owner := c.owners[slot]
+		if owner == "" {
+			continue
+		}
+
+		last := len(result) - 1
+		if last >= 0 && result[last].ID == owner && result[last].Slots[0].End == slot-1 {
+			result[last].Slots[0].End = slot
+			continue
+		}
+		result = append(result, NodeSlots{Node: c.nodes[owner], Slots: []SlotRange{{Start: slot, End: slot}}})
+	}
+	return result
+}
+
+// SetMigrating marks the slot as moving from this node to the target node.
+func (c *Cluster) SetMigrating(slot int, target string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.nodes[target]; !ok {
+		return fmt.Errorf("node %q: %w", target, ErrUnknownNode)
+	}
+	if c.owners[slot] != c.self {
+		return fmt.Errorf("slot %d is not owned by this node", slot)
+	}
+
+	c.migrating[slot] = target
+	return c.save()
+}
+
+// SetImporting marks the slot as moving from the source node to this node.
+// It does nothing for a slot owned by this node already, so an interrupted
+// migration can repeat the step after the ownership was flipped on this side.
+func (c *Cluster) SetImporting(slot int, source string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.nodes[source]; !ok {
+		return fmt.Errorf("node %q: %w", source, ErrUnknownNode)
+	}
+	if c.owners[slot] == c.self {
+		return nil
+	}
+
+	c.importing[slot] = source
+	return c.save()
+}
+
+// SetOwner assigns the slot to the node and clears its migration state.
+func (c *Cluster) SetOwner(slot int, owner string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.nodes[owner]; !ok {
+		return fmt.Errorf("node %q: %w", owner, ErrUnknownNode)
+	}
+
+	c.owners[slot] = owner
+	delete(c.migrating, slot)
+	delete(c.importing, slot)
+	return c.save()
+}
+
+// SetStable clears the migration state of the slot.
+func (c *Cluster) SetStable(slot int) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.migrating, slot)
+	delete(c.importing, slot)
+	return c.save()
+}
+
+// Enter blocks while the slot is frozen and returns the func to leave it.
+// Commands stay inside the slot from the routing decision until executed.
+func (c *Cluster) Enter(slot int) func() {
+	c.guards[slot].RLock()
+	return c.guards[slot].RUnlock
+}
+
+// Freeze waits for commands inside the slot to leave and keeps new ones out
+// until the returned func is called.
+func (c *Cluster) Freeze(slot int) func() {
+	c.guards[slot].Lock()
+	return c.guards[slot].Unlock
+}
+
+// Route returns nil when the command for the slot is served by this node and
+// a RedirectError otherwise. The exists func reports whether all keys of the
+// command are stored locally, it is called only for migrating slots, and asking
+// tells the client sent ASKING before the command.
+func (c *Cluster) Route(slot int, asking bool, exists func() bool) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	owner := c.owners[slot]
+	switch {
+	case owner == "":
+		return ErrClusterDown
+	case owner == c.self:
+		if target, ok := c.migrating[slot]; ok && !exists() {
+			return RedirectError{Ask: true, Slot: slot, Address: c.nodes[target].Address}
+		}
+		return nil
+	default:
+		if _, ok := c.importing[slot]; ok && asking {
+			return nil
+		}
+		return RedirectError{Slot: slot, Address: c.nodes[owner].Address}
+	}
+}
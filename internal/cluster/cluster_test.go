@@ -0,0 +1,183 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySlot(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 12739, KeySlot("123456789"))
+	require.Equal(t, 12182, KeySlot("foo"))
+	require.Equal(t, KeySlot("user1000"), KeySlot("{user1000}.following"))
+	require.Equal(t, KeySlot("{user1000}.followers"), KeySlot("{user1000}.following"))
+	require.Equal(t, KeySlot("{}key"), KeySlot("{}key"))
+	require.NotEqual(t, KeySlot(""), KeySlot("{}key"))
+}
+
+func TestParseSlotRange(t *testing.T) {
+	t.Parallel()
+
+	r, err := ParseSlotRange("0-5460")
+	require.NoError(t, err)
+	require.Equal(t, SlotRange{Start: 0, End: 5460}, r)
+
+	r, err = ParseSlotRange("42")
+	require.NoError(t, err)
+	require.Equal(t, SlotRange{Start: 42, End: 42}, r)
+
+	for _, s := range []string{"", "a", "-1", "16384", "10-5", "1-2-3"} {
+		_, err = ParseSlotRange(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("a", []NodeSlots{
+		{Node: Node{ID: "a"}, Slots: []SlotRange{{Start: 0, End: 10}}},
+		{Node: Node{ID: "b"}, Slots: []SlotRange{{Start: 10, End: 20}}},
+	})
+	require.Error(t, err)
+
+	_, err = New("c", []NodeSlots{{Node: Node{ID: "a"}}})
+	require.ErrorIs(t, err, ErrUnknownNode)
+}
+
+func TestCluster_Route(t *testing.T) {
+	t.Parallel()
+
+	c, err := New("a", []NodeSlots{
+		{Node: Node{ID: "a", Address: "10.0.0.1:3223"}, Slots: []SlotRange{{Start: 0, End: 99}}},
+		{Node: Node{ID: "b", Address: "10.0.0.2:3223"}, Slots: []SlotRange{{Start: 100, End: 199}}},
+	})
+	require.NoError(t, err)
+
+	exists := func() bool { return true }
+	missing := func() bool { return false }
+
+	require.NoError(t, c.Route(5, false, missing))
+	require.Equal(t, RedirectError{Slot: 150, Address: "10.0.0.2:3223"}, c.Route(150, false, exists))
+	require.ErrorIs(t, c.Route(500, false, exists), ErrClusterDown)
+
+	require.NoError(t, c.SetMigrating(5, "b"))
+	require.NoError(t, c.Route(5, false, exists))
+	require.Equal(t, RedirectError{Ask: true, Slot: 5, Address: "10.0.0.2:3223"}, c.Route(5, false, missing))
+	require.EqualError(t, c.Route(5, false, missing), "ASK 5 10.0.0.2:3223")
+
+	require.NoError(t, c.SetImporting(150, "b"))
+	require.NoError(t, c.Route(150, true, missing))
+	require.EqualError(t, c.Route(150, false, missing), "MOVED 150 10.0.0.2:3223")
+
+	require.NoError(t, c.SetOwner(5, "b"))
+	require.EqualError(t, c.Route(5, false, exists), "MOVED 5 10.0.0.2:3223")
+
+	require.Error(t, c.SetMigrating(150, "b"))
+	require.ErrorIs(t, c.SetImporting(5, "z"), ErrUnknownNode)
+}
+
+func TestCluster_Slots(t *testing.T) {
+	t.Parallel()
+
+	a := Node{ID: "a", Address: "10.0.0.1:3223"}
+	b := Node{ID: "b", Address: "10.0.0.2:3223"}
+	c, err := New("a", []NodeSlots{
+		{Node: a, Slots: []SlotRange{{Start: 0, End: 9}, {Start: 20, End: 29}}},
+		{Node: b, Slots: []SlotRange{{Start: 10, End: 19}}},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []NodeSlots{
+		{Node: a, Slots: []SlotRange{{Start: 0, End: 9}}},
+		{Node: b, Slots: []SlotRange{{Start: 10, End: 19}}},
+		{Node: a, Slots: []SlotRange{{Start: 20, End: 29}}},
+	}, c.Slots())
+}
+
+func TestCluster_Persist(t *testing.T) {
+	t.Parallel()
+
+	nodes := []NodeSlots{
+		{Node: Node{ID: "a", Address: "10.0.0.1:3223"}, Slots: []SlotRange{{Start: 0, End: 8191}}},
+		{Node: Node{ID: "b", Address: "10.0.0.2:3223"}, Slots: []SlotRange{{Start: 8192, End: 16383}}},
+	}
+	path := filepath.Join(t.TempDir(), "cluster-state.json")
+
+	c, err := New("a", nodes)
+	require.NoError(t, err)
+	require.NoError(t, c.Persist(path))
+	require.NoError(t, c.SetOwner(100, "b"))
+	require.NoError(t, c.SetMigrating(200, "b"))
+
+	restarted, err := New("a", nodes)
+	require.NoError(t, err)
+	require.NoError(t, restarted.Persist(path))
+
+	owner, ok := restarted.Owner(100)
+	require.True(t, ok)
+	require.Equal(t, "b", owner.ID)
+	require.EqualError(t, restarted.Route(200, false, func() bool { return false }), "ASK 200 10.0.0.2:3223")
+	require.Equal(t, c.Slots(), restarted.Slots())
+
+	require.NoError(t, restarted.SetStable(200))
+	require.NoError(t, restarted.Route(200, false, func() bool { return false }))
+
+	for _, slots := range []string{`[{"Start":-1,"End":5}]`, `[{"Start":0,"End":16384}]`, `[{"Start":9,"End":5}]`} {
+		corrupted := filepath.Join(t.TempDir(), "cluster-state.json")
+		require.NoError(t, os.WriteFile(corrupted, []byte(`{"slots":{"a":`+slots+`}}`), 0o600))
+
+		c, err := New("a", nodes)
+		require.NoError(t, err)
+		require.ErrorContains(t, c.Persist(corrupted), "invalid slot range", slots)
+	}
+
+	overlapping := filepath.Join(t.TempDir(), "cluster-state.json")
+	state := `{"slots":{"a":[{"Start":0,"End":100}],"b":[{"Start":100,"End":16383}]}}`
+	require.NoError(t, os.WriteFile(overlapping, []byte(state), 0o600))
+	c, err = New("a", nodes)
+	require.NoError(t, err)
+	require.ErrorContains(t, c.Persist(overlapping), "cluster state slot 100 is assigned to")
+}
+
+func TestCluster_Freeze(t *testing.T) {
+	t.Parallel()
+
+	c, err := New("a", []NodeSlots{{Node: Node{ID: "a", Address: "10.0.0.1:3223"}, Slots: []SlotRange{{Start: 0, End: 16383}}}})
+	require.NoError(t, err)
+
+	leave := c.Enter(7)
+	frozen := make(chan func())
+	go func() { frozen <- c.Freeze(7) }()
+
+	select {
+	case <-frozen:
+		t.Fatal("slot frozen while a command is inside")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// other slots are not affected
+	c.Enter(8)()
+
+	leave()
+	unfreeze := <-frozen
+	entered := make(chan struct{})
+	go func() {
+		c.Enter(7)()
+		close(entered)
+	}()
+
+	select {
+	case <-entered:
+		t.Fatal("command entered frozen slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unfreeze()
+	<-entered
+}
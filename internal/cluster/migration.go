@@ -0,0 +1,377 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/atomicfile"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+var (
+	ErrMigrationRunning    = errors.New("migration is already running")
+	ErrNothingToResume     = errors.New("there is no interrupted migration to resume")
+	ErrInvalidMigrationDst = errors.New("invalid migration target")
+)
+
+type MigrationState string
+
+const (
+	MigrationIdle        MigrationState = "idle"
+	MigrationRunning     MigrationState = "running"
+	MigrationDone        MigrationState = "done"
+	MigrationFailed      MigrationState = "failed"
+	MigrationInterrupted MigrationState = "interrupted"
+)
+
+// Progress describes the current or the last migration, it is also the
+// journal the migration is resumed from.
+type Progress struct {
+	State  MigrationState `json:"state"`
+	Range  SlotRange      `json:"range"`
+	Target string         `json:"target"`
+	// Slot is the slot being migrated, slots before it are moved already.
+	Slot      int       `json:"slot"`
+	SlotsDone int       `json:"slots_done"`
+	KeysMoved int       `json:"keys_moved"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store is the local storage keys are moved from.
+type Store interface {
+	Keys(ctx context.Context) ([]string, error)
+	Dump(ctx context.Context, key string) ([]byte, error)
+	MDelete(ctx context.Context, keys []string) ([]bool, error)
+}
+
+// Peer sends migration commands to another node.
+type Peer interface {
+	SetSlot(ctx context.Context, slot int, state, node string) error
+	// Restore stores dumped values of keys on the peer.
+	Restore(ctx context.Context, dumps []KeyDump) error
+	Close() error
+}
+
+type KeyDump struct {
+	Key  string
+	Data []byte
+}
+
+type Dialer func(address string) (Peer, error)
+
+// Migrator moves slots owned by this node to another node without downtime:
+// keys are moved in batches while the slot keeps serving commands, keys
+// already moved are redirected with ASK, and the ownership is flipped after
+// the last batch.
+type Migrator struct {
+	cluster   *Cluster
+	store     Store
+	dial      Dialer
+	journal   string
+	batchSize int
+	log       *slog.Logger
+
+	lock     sync.Mutex
+	progress Progress
+}
+
+// NewMigrator loads the journal, a migration running when the journal was
+// written is reported as interrupted and can be resumed. Empty journal path
+// disables the journal.
+func NewMigrator(c *Cluster, store Store, dial Dialer, journal string, batchSize int, l *slog.Logger) (*Migrator, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("invalid migration batch size %d", batchSize)
+	}
+
+	m := &Migrator{
+		cluster:   c,
+		store:     store,
+		dial:      dial,
+		journal:   journal,
+		batchSize: batchSize,
+		log:       l,
+		progress:  Progress{State: MigrationIdle},
+	}
+
+	if journal == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(journal)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read migration journal: %w", err)
+	}
+	if err = json.Unmarshal(data, &m.progress); err != nil {
+		return nil, fmt.Errorf("decode migration journal: %w", err)
+	}
+	if m.progress.State == MigrationRunning {
+		m.progress.State = MigrationInterrupted
+	}
+
+	return m, nil
+}
+
+func (m *Migrator) Progress() Progress {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.progress
+}
+
+// Start migrates the slot range to the target node in background until the
+// context is done.
+func (m *Migrator) Start(ctx context.Context, r SlotRange, target string) error {
+	self := m.cluster.Self()
+	if target == self.ID {
+		return fmt.Errorf("%w: node %q is this node", ErrInvalidMigrationDst, target)
+	}
+	if _, ok := m.cluster.Node(target); !ok {
+		return fmt.Errorf("%w: node %q: %w", ErrInvalidMigrationDst, target, ErrUnknownNode)
+	}
+	for slot := r.Start; slot <= r.End; slot++ {
+		if owner, _ := m.cluster.Owner(slot); owner.ID != self.ID {
+			return fmt.Errorf("slot %d is not owned by this node", slot)
+		}
+	}
+
+	now := time.Now()
+	return m.run(ctx, func(p *Progress) error {
+		if p.State == MigrationRunning {
+			return ErrMigrationRunning
+		}
+		*p = Progress{State: MigrationRunning, Range: r, Target: target, Slot: r.Start, StartedAt: now, UpdatedAt: now}
+		return nil
+	})
+}
+
+// Resume continues the interrupted or failed migration from the slot it stopped at.
+func (m *Migrator) Resume(ctx context.Context) error {
+	return m.run(ctx, func(p *Progress) error {
+		if p.State != MigrationInterrupted && p.State != MigrationFailed {
+			return ErrNothingToResume
+		}
+		p.State = MigrationRunning
+		p.Error = ""
+		return nil
+	})
+}
+
+func (m *Migrator) run(ctx context.Context, init func(*Progress) error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := init(&m.progress); err != nil {
+		return err
+	}
+	if err := m.save(); err != nil {
+		return err
+	}
+
+	p := m.progress
+	go func() {
+		err := m.migrate(ctx, p)
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		if err != nil {
+			m.log.Error("slot migration failed", "slot", m.progress.Slot, "error", err.Error())
+			m.progress.State = MigrationFailed
+			m.progress.Error = err.Error()
+		} else {
+			m.log.Info("slot migration finished", "range", p.Range.String(), "target", p.Target)
+			m.progress.State = MigrationDone
+		}
+		m.progress.UpdatedAt = time.Now()
+		if err = m.save(); err != nil {
+			m.log.Error("failed to save migration journal", "error", err.Error())
+		}
+	}()
+
+	return nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, p Progress) error {
+	target, _ := m.cluster.Node(p.Target)
+	peer, err := m.dial(target.Address)
+	if err != nil {
+		return fmt.Errorf("dial target: %w", err)
+	}
+	defer func() {
+		if err := peer.Close(); err != nil {
+			m.log.Error("failed to close target connection", "error", err.Error())
+		}
+	}()
+
+	keys, err := m.keysBySlot(ctx, SlotRange{Start: p.Slot, End: p.Range.End})
+	if err != nil {
+		return err
+	}
+
+	for slot := p.Slot; slot <= p.Range.End; slot++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = m.migrateSlot(ctx, peer, slot, p.Target, keys[slot]); err != nil {
+			return fmt.Errorf("slot %d: %w", slot, err)
+		}
+
+		if err = m.update(func(p *Progress) {
+			p.Slot = slot + 1
+			p.SlotsDone++
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) migrateSlot(ctx context.Context, peer Peer, slot int, target string, keys []string) error {
+	self := m.cluster.Self()
+	owner, _ := m.cluster.Owner(slot)
+	if owner.ID == target {
+		// moved before the migration was interrupted
+		return nil
+	}
+	if owner.ID != self.ID {
+		return fmt.Errorf("slot is owned by %q", owner.ID)
+	}
+
+	if err := peer.SetSlot(ctx, slot, domain.SlotImporting, self.ID); err != nil {
+		return fmt.Errorf("set importing on target: %w", err)
+	}
+	if err := m.cluster.SetMigrating(slot, target); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(keys); start += m.batchSize {
+		batch := keys[start:min(start+m.batchSize, len(keys))]
+
+		moved, err := m.moveBatch(ctx, peer, slot, batch)
+		if err != nil {
+			return err
+		}
+		if err = m.update(func(p *Progress) { p.KeysMoved += moved }); err != nil {
+			return err
+		}
+	}
+
+	if err := peer.SetSlot(ctx, slot, domain.SlotNode, target); err != nil {
+		return fmt.Errorf("set owner on target: %w", err)
+	}
+	if err := m.cluster.SetOwner(slot, target); err != nil {
+		return err
+	}
+
+	m.announce(ctx, slot, target)
+	return nil
+}
+
+// moveBatch freezes the slot, so no command changes keys between the dump and
+// the delete.
+func (m *Migrator) moveBatch(ctx context.Context, peer Peer, slot int, keys []string) (int, error) {
+	unfreeze := m.cluster.Freeze(slot)
+	defer unfreeze()
+
+	dumps := make([]KeyDump, 0, len(keys))
+	for _, key := range keys {
+		data, err := m.store.Dump(ctx, key)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("dump %q: %w", key, err)
+		}
+		dumps = append(dumps, KeyDump{Key: key, Data: data})
+	}
+	if len(dumps) == 0 {
+		return 0, nil
+	}
+
+	if err := peer.Restore(ctx, dumps); err != nil {
+		return 0, fmt.Errorf("restore on target: %w", err)
+	}
+
+	moved := make([]string, len(dumps))
+	for i, d := range dumps {
+		moved[i] = d.Key
+	}
+	if _, err := m.store.MDelete(ctx, moved); err != nil {
+		return 0, fmt.Errorf("delete moved keys: %w", err)
+	}
+
+	return len(moved), nil
+}
+
+// announce tells other nodes about the new owner, they would redirect clients
+// to the old owner otherwise, which redirects them once more.
+func (m *Migrator) announce(ctx context.Context, slot int, target string) {
+	self := m.cluster.Self()
+	for _, n := range m.cluster.Nodes() {
+		if n.ID == self.ID || n.ID == target {
+			continue
+		}
+
+		peer, err := m.dial(n.Address)
+		if err != nil {
+			m.log.Warn("failed to announce slot owner", "node", n.ID, "slot", slot, "error", err.Error())
+			continue
+		}
+		if err = peer.SetSlot(ctx, slot, domain.SlotNode, target); err != nil {
+			m.log.Warn("failed to announce slot owner", "node", n.ID, "slot", slot, "error", err.Error())
+		}
+		if err = peer.Close(); err != nil {
+			m.log.Error("failed to close node connection", "node", n.ID, "error", err.Error())
+		}
+	}
+}
+
+func (m *Migrator) keysBySlot(ctx context.Context, r SlotRange) (map[int][]string, error) {
+	keys, err := m.store.Keys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list keys: %w", err)
+	}
+
+	result := make(map[int][]string)
+	for _, key := range keys {
+		if slot := KeySlot(key); r.Contains(slot) {
+			result[slot] = append(result[slot], key)
+		}
+	}
+	return result, nil
+}
+
+func (m *Migrator) update(f func(*Progress)) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	f(&m.progress)
+	m.progress.UpdatedAt = time.Now()
+	return m.save()
+}
+
+// save writes the journal, the caller must hold the lock.
+func (m *Migrator) save() error {
+	if m.journal == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(m.progress)
+	if err != nil {
+		return fmt.Errorf("encode migration journal: %w", err)
+	}
+	return atomicfile.Write(m.journal, data)
+}
@@ -0,0 +1,225 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+type fakeStore struct {
+	lock sync.Mutex
+	data map[string]string
+}
+
+func newFakeStore(keys ...string) *fakeStore {
+	s := &fakeStore{data: make(map[string]string)}
+	for _, k := range keys {
+		s.data[k] = "value of " + k
+	}
+	return s
+}
+
+func (s *fakeStore) Keys(context.Context) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return slices.Sorted(maps.Keys(s.data)), nil
+}
+
+func (s *fakeStore) Dump(_ context.Context, key string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v, ok := s.data[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return []byte(v), nil
+}
+
+func (s *fakeStore) MDelete(_ context.Context, keys []string) ([]bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deleted := make([]bool, len(keys))
+	for i, k := range keys {
+		_, deleted[i] = s.data[k]
+		delete(s.data, k)
+	}
+	return deleted, nil
+}
+
+// fakePeer applies migration commands to the target cluster and store,
+// restores fail while failRestore is set.
+type fakePeer struct {
+	cluster     *Cluster
+	store       *fakeStore
+	failRestore *bool
+	lock        *sync.Mutex
+}
+
+func (p fakePeer) SetSlot(_ context.Context, slot int, state, node string) error {
+	switch state {
+	case domain.SlotImporting:
+		return p.cluster.SetImporting(slot, node)
+	case domain.SlotNode:
+		return p.cluster.SetOwner(slot, node)
+	default:
+		return errors.New("unexpected slot state " + state)
+	}
+}
+
+func (p fakePeer) Restore(_ context.Context, dumps []KeyDump) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if *p.failRestore {
+		return io.ErrUnexpectedEOF
+	}
+
+	p.store.lock.Lock()
+	defer p.store.lock.Unlock()
+	for _, d := range dumps {
+		p.store.data[d.Key] = string(d.Data)
+	}
+	return nil
+}
+
+func (p fakePeer) Close() error {
+	return nil
+}
+
+func TestMigrator(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	nodes := []NodeSlots{
+		{Node: Node{ID: "a", Address: "10.0.0.1:3223"}, Slots: []SlotRange{{Start: 0, End: 8191}}},
+		{Node: Node{ID: "b", Address: "10.0.0.2:3223"}, Slots: []SlotRange{{Start: 8192, End: 16383}}},
+	}
+
+	// "bar" and "{bar}.x" hash to slot 5061, "baz" to 4813, "foo" to 12182
+	setup := func(t *testing.T, journal string) (*Migrator, *fakeStore, *Cluster, *bool, *sync.Mutex) {
+		source, err := New("a", nodes)
+		require.NoError(t, err)
+		target, err := New("b", nodes)
+		require.NoError(t, err)
+
+		srcStore := newFakeStore("bar", "{bar}.x", "baz")
+		dstStore := newFakeStore("foo")
+
+		failRestore := new(bool)
+		lock := &sync.Mutex{}
+		dial := func(address string) (Peer, error) {
+			require.Equal(t, "10.0.0.2:3223", address)
+			return fakePeer{cluster: target, store: dstStore, failRestore: failRestore, lock: lock}, nil
+		}
+
+		m, err := NewMigrator(source, srcStore, dial, journal, 1, log)
+		require.NoError(t, err)
+		return m, dstStore, target, failRestore, lock
+	}
+
+	waitFor := func(t *testing.T, m *Migrator, state MigrationState) Progress {
+		require.Eventually(t, func() bool { return m.Progress().State == state }, time.Second, time.Millisecond)
+		return m.Progress()
+	}
+
+	t.Run("moves keys and flips ownership", func(t *testing.T) {
+		t.Parallel()
+
+		m, dstStore, target, _, _ := setup(t, "")
+		require.Equal(t, MigrationIdle, m.Progress().State)
+
+		require.NoError(t, m.Start(context.Background(), SlotRange{Start: 5000, End: 5100}, "b"))
+		p := waitFor(t, m, MigrationDone)
+
+		require.Equal(t, 101, p.SlotsDone)
+		require.Equal(t, 2, p.KeysMoved)
+		require.Equal(t, 5101, p.Slot)
+		require.Equal(t, []string{"bar", "foo", "{bar}.x"}, slices.Sorted(maps.Keys(dstStore.data)))
+
+		for _, c := range []*Cluster{m.cluster, target} {
+			owner, ok := c.Owner(5061)
+			require.True(t, ok)
+			require.Equal(t, "b", owner.ID)
+		}
+		require.NoError(t, target.Route(5061, false, func() bool { return false }))
+		require.EqualError(t, m.cluster.Route(5061, false, func() bool { return false }), "MOVED 5061 10.0.0.2:3223")
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		t.Parallel()
+
+		m, _, _, _, _ := setup(t, "")
+		ctx := context.Background()
+
+		require.ErrorIs(t, m.Start(ctx, SlotRange{Start: 0, End: 10}, "a"), ErrInvalidMigrationDst)
+		require.ErrorIs(t, m.Start(ctx, SlotRange{Start: 0, End: 10}, "z"), ErrUnknownNode)
+		require.Error(t, m.Start(ctx, SlotRange{Start: 8000, End: 8200}, "b"))
+		require.ErrorIs(t, m.Resume(ctx), ErrNothingToResume)
+	})
+
+	t.Run("resumes failed migration", func(t *testing.T) {
+		t.Parallel()
+
+		journal := filepath.Join(t.TempDir(), "migration.json")
+		m, dstStore, _, failRestore, lock := setup(t, journal)
+
+		lock.Lock()
+		*failRestore = true
+		lock.Unlock()
+
+		require.NoError(t, m.Start(context.Background(), SlotRange{Start: 4800, End: 5100}, "b"))
+		p := waitFor(t, m, MigrationFailed)
+		require.Equal(t, 4813, p.Slot)
+		require.Contains(t, p.Error, "slot 4813")
+		require.Equal(t, 13, p.SlotsDone)
+
+		// the journal keeps the failed state for the restarted node
+		loaded, err := NewMigrator(m.cluster, m.store, m.dial, journal, 1, log)
+		require.NoError(t, err)
+		require.Equal(t, MigrationFailed, loaded.Progress().State)
+
+		lock.Lock()
+		*failRestore = false
+		lock.Unlock()
+
+		require.NoError(t, m.Resume(context.Background()))
+		p = waitFor(t, m, MigrationDone)
+		require.Equal(t, 301, p.SlotsDone)
+		require.Equal(t, 3, p.KeysMoved)
+		require.Empty(t, p.Error)
+		require.Len(t, dstStore.data, 4)
+	})
+
+	t.Run("reports running migration from journal as interrupted", func(t *testing.T) {
+		t.Parallel()
+
+		journal := filepath.Join(t.TempDir(), "migration.json")
+		data := `{"state":"running","range":{"Start":10,"End":20},"target":"b","slot":15,"slots_done":5}`
+		require.NoError(t, os.WriteFile(journal, []byte(data), 0o600))
+
+		m, _, _, _, _ := setup(t, journal)
+		p := m.Progress()
+		require.Equal(t, MigrationInterrupted, p.State)
+		require.Equal(t, 15, p.Slot)
+
+		require.NoError(t, m.Resume(context.Background()))
+		p = waitFor(t, m, MigrationDone)
+		require.Equal(t, 11, p.SlotsDone)
+		require.Equal(t, 21, p.Slot)
+	})
+}
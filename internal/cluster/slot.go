@@ -0,0 +1,99 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SlotCount is the number of hash slots the keyspace is split into.
+const SlotCount = 16384
+
+// KeySlot maps the key to a slot. When the key contains a non-empty hash tag
+// like {user1}, only the tag is hashed, so related keys share a slot.
+func KeySlot(key string) int {
+	return int(crc16(HashTag(key))) % SlotCount
+}
+
+// HashTag returns the part of the key to hash: the content of the first
+// non-empty hash tag or the whole key.
+func HashTag(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key[start+1 : start+1+end]
+		}
+	}
+	return key
+}
+
+// crc16 implements CRC-16/XMODEM.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// SlotRange is an inclusive range of slots.
+type SlotRange struct {
+	Start, End int
+}
+
+func (r SlotRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+func (r SlotRange) Contains(slot int) bool {
+	return slot >= r.Start && slot <= r.End
+}
+
+// validate checks the range holds existing slots in ascending order like
+// ParseSlotRange does.
+func (r SlotRange) validate() error {
+	if r.Start < 0 || r.End >= SlotCount || r.Start > r.End {
+		return fmt.Errorf("invalid slot range %q", r.String())
+	}
+	return nil
+}
+
+// ParseSlotRange parses a single slot "5" or an inclusive range "0-5460".
+func ParseSlotRange(s string) (SlotRange, error) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	if !isRange {
+		endStr = startStr
+	}
+
+	start, err := parseSlot(startStr)
+	if err != nil {
+		return SlotRange{}, err
+	}
+	end, err := parseSlot(endStr)
+	if err != nil {
+		return SlotRange{}, err
+	}
+	if start > end {
+		return SlotRange{}, fmt.Errorf("invalid slot range %q", s)
+	}
+
+	return SlotRange{Start: start, End: end}, nil
+}
+
+// parseSlot parses a slot number.
+func parseSlot(s string) (int, error) {
+	slot, err := strconv.Atoi(s)
+	if err != nil || slot < 0 || slot >= SlotCount {
+		return 0, fmt.Errorf("invalid slot %q", s)
+	}
+	return slot, nil
+}
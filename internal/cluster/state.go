@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/tmvrus/key-value-storage/internal/atomicfile"
+)
+
+// persistedState is the slot assignment and migration states saved as JSON.
+type persistedState struct {
+	Slots     map[string][]SlotRange `json:"slots"`
+	Migrating map[int]string         `json:"migrating"`
+	Importing map[int]string         `json:"importing"`
+}
+
+// Persist makes the cluster save slot assignments and migration states to the
+// file on every change. The state saved earlier is loaded first and takes
+// precedence over the configured assignments.
+func (c *Cluster) Persist(path string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stateFile = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c.save()
+	}
+	if err != nil {
+		return fmt.Errorf("read cluster state: %w", err)
+	}
+
+	var state persistedState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("decode cluster state: %w", err)
+	}
+
+	return c.apply(state)
+}
+
+func (c *Cluster) apply(state persistedState) error {
+	var owners [SlotCount]string
+	for id, ranges := range state.Slots {
+		if _, ok := c.nodes[id]; !ok {
+			return fmt.Errorf("cluster state node %q: %w", id, ErrUnknownNode)
+		}
+		for _, r := range ranges {
+			if err := r.validate(); err != nil {
+				return fmt.Errorf("cluster state node %q: %w", id, err)
+			}
+			for slot := r.Start; slot <= r.End; slot++ {
+				if owner := owners[slot]; owner != "" {
+					return fmt.Errorf("cluster state slot %d is assigned to %q and %q", slot, owner, id)
+				}
+				owners[slot] = id
+			}
+		}
+	}
+
+	for _, ids := range []map[int]string{state.Migrating, state.Importing} {
+		for slot, id := range ids {
+			if _, ok := c.nodes[id]; !ok || slot < 0 || slot >= SlotCount {
+				return fmt.Errorf("cluster state slot %d of node %q: %w", slot, id, ErrUnknownNode)
+			}
+		}
+	}
+
+	c.owners = owners
+	c.migrating = make(map[int]string)
+	c.importing = make(map[int]string)
+	for slot, id := range state.Migrating {
+		c.migrating[slot] = id
+	}
+	for slot, id := range state.Importing {
+		c.importing[slot] = id
+	}
+	return nil
+}
+
+// save writes the state file atomically, the caller must hold the lock.
+func (c *Cluster) save() error {
+	if c.stateFile == "" {
+		return nil
+	}
+
+	state := persistedState{
+		Slots:     make(map[string][]SlotRange),
+		Migrating: c.migrating,
+		Importing: c.importing,
+	}
+	for _, n := range c.slots() {
+		state.Slots[n.ID] = append(state.Slots[n.ID], n.Slots...)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode cluster state: %w", err)
+	}
+
+	return atomicfile.Write(c.stateFile, data)
+}
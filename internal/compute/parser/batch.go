@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func parseMGet(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandMGet, args, 1); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandMGet
+	cmd.Args = args
+	return
+}
+
+func parseMSet(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandMSet, args, 2); err != nil {
+		return
+	}
+	if len(args)%2 != 0 {
+		err = fmt.Errorf("odd arguments number for MSET command")
+		return
+	}
+
+	cmd.Type = domain.CommandMSet
+	cmd.Args = args
+	return
+}
+
+func parseMDelete(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandMDelete, args, 1); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandMDelete
+	cmd.Args = args
+	return
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Batch(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in: "MGET key",
+			out: domain.Command{
+				Type: domain.CommandMGet,
+				Args: []string{"key"},
+			},
+		},
+		{
+			in: "MGET key1 key2 key3",
+			out: domain.Command{
+				Type: domain.CommandMGet,
+				Args: []string{"key1", "key2", "key3"},
+			},
+		},
+		{
+			in:  "MGET key1  key2",
+			err: true,
+		},
+		{
+			in: "MSET key1 value1 key2 value2",
+			out: domain.Command{
+				Type: domain.CommandMSet,
+				Args: []string{"key1", "value1", "key2", "value2"},
+			},
+		},
+		{
+			in:  "MSET key1 value1 key2",
+			err: true,
+		},
+		{
+			in: "MDELETE key1 key2",
+			out: domain.Command{
+				Type: domain.CommandMDelete,
+				Args: []string{"key1", "key2"},
+			},
+		},
+		{
+			in:  "MDELETE ",
+			err: true,
+		},
+	}
+
+	for i, c := range tt {
+		cmd, err := Parse(c.in)
+		if c.err {
+			require.Errorf(t, err, "iter %d", i)
+		} else {
+			require.NoErrorf(t, err, "iter %d", i)
+			require.Equal(t, c.out, cmd)
+		}
+	}
+}
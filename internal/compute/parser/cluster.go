@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// parseCluster produces Args: the subcommand followed by its arguments,
+// KEYSLOT takes a key, SETSLOT takes a slot, a state and a node id for all
+// states but STABLE, MIGRATE takes a slot range and a target node id or
+// one of STATUS and RESUME. Slots are validated by the handler.
+func parseCluster(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandCluster, args, 1); err != nil {
+		return
+	}
+
+	switch args[0] {
+	case domain.ClusterSlots:
+		err = checkArgs(domain.CommandCluster, args, 1)
+	case domain.ClusterKeySlot:
+		err = checkArgs(domain.CommandCluster, args, 2)
+	case domain.ClusterSetSlot:
+		err = checkSetSlot(args)
+	case domain.ClusterMigrate:
+		err = checkMigrate(args)
+	default:
+		err = fmt.Errorf("unsupported subcommand %q for CLUSTER command", args[0])
+	}
+	if err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandCluster
+	cmd.Args = args
+	return
+}
+
+func checkSetSlot(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("invalid arguments number for %s command", domain.CommandCluster)
+	}
+
+	switch args[2] {
+	case domain.SlotStable:
+		return checkArgs(domain.CommandCluster, args, 3)
+	case domain.SlotImporting, domain.SlotMigrating, domain.SlotNode:
+		return checkArgs(domain.CommandCluster, args, 4)
+	default:
+		return fmt.Errorf("unsupported slot state %q for CLUSTER SETSLOT command", args[2])
+	}
+}
+
+func checkMigrate(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("invalid arguments number for %s command", domain.CommandCluster)
+	}
+
+	switch args[1] {
+	case domain.MigrateStatus, domain.MigrateResume:
+		return checkArgs(domain.CommandCluster, args, 2)
+	default:
+		return checkArgs(domain.CommandCluster, args, 3)
+	}
+}
+
+// parseRestore produces Args: keys followed by base64 encoded dumps of their values.
+func parseRestore(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandRestore, args, 2); err != nil {
+		return
+	}
+	if len(args)%2 != 0 {
+		err = fmt.Errorf("odd arguments number for RESTORE command")
+		return
+	}
+
+	cmd.Type = domain.CommandRestore
+	cmd.Args = args
+	return
+}
+
+func parseAsking(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandAsking, args, 0); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandAsking
+	return
+}
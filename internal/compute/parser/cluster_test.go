@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Cluster(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in:  "CLUSTER SLOTS",
+			out: domain.Command{Type: domain.CommandCluster, Args: []string{"SLOTS"}},
+		},
+		{
+			in:  "CLUSTER KEYSLOT user:1",
+			out: domain.Command{Type: domain.CommandCluster, Args: []string{"KEYSLOT", "user:1"}},
+		},
+		{
+			in:  "ASKING",
+			out: domain.Command{Type: domain.CommandAsking},
+		},
+		{
+			in:  "CLUSTER SETSLOT 42 IMPORTING node-a",
+			out: domain.Command{Type: domain.CommandCluster, Args: []string{"SETSLOT", "42", "IMPORTING", "node-a"}},
+		},
+		{
+			in:  "CLUSTER SETSLOT 42 STABLE",
+			out: domain.Command{Type: domain.CommandCluster, Args: []string{"SETSLOT", "42", "STABLE"}},
+		},
+		{
+			in:  "CLUSTER MIGRATE 0-100 node-b",
+			out: domain.Command{Type: domain.CommandCluster, Args: []string{"MIGRATE", "0-100", "node-b"}},
+		},
+		{
+			in:  "CLUSTER MIGRATE STATUS",
+			out: domain.Command{Type: domain.CommandCluster, Args: []string{"MIGRATE", "STATUS"}},
+		},
+		{
+			in:  "CLUSTER MIGRATE RESUME",
+			out: domain.Command{Type: domain.CommandCluster, Args: []string{"MIGRATE", "RESUME"}},
+		},
+		{
+			in:  "RESTORE a ZGF0YQ== b ZGF0YQ==",
+			out: domain.Command{Type: domain.CommandRestore, Args: []string{"a", "ZGF0YQ==", "b", "ZGF0YQ=="}},
+		},
+		{
+			in:  "CLUSTER",
+			err: true,
+		},
+		{
+			in:  "CLUSTER SETSLOT 42",
+			err: true,
+		},
+		{
+			in:  "CLUSTER SETSLOT 42 STABLE node-a",
+			err: true,
+		},
+		{
+			in:  "CLUSTER SETSLOT 42 NODE",
+			err: true,
+		},
+		{
+			in:  "CLUSTER SETSLOT 42 OWNED node-a",
+			err: true,
+		},
+		{
+			in:  "CLUSTER MIGRATE 0-100",
+			err: true,
+		},
+		{
+			in:  "CLUSTER MIGRATE STATUS now",
+			err: true,
+		},
+		{
+			in:  "RESTORE a",
+			err: true,
+		},
+		{
+			in:  "RESTORE a ZGF0YQ== b",
+			err: true,
+		},
+		{
+			in:  "CLUSTER KEYSLOT",
+			err: true,
+		},
+		{
+			in:  "CLUSTER NODES",
+			err: true,
+		},
+		{
+			in:  "ASKING now",
+			err: true,
+		},
+		{
+			in:  "GET",
+			err: true,
+		},
+		{
+			in:  "",
+			err: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, err := Parse(tc.in)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.out, cmd)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// parseAuth produces Args: the user name and the password.
+func parseAuth(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandAuth, args, 2); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandAuth
+	cmd.Args = args
+	return
+}
+
+// parseClient produces Args: the subcommand followed by its arguments, KILL
+// takes the filter, ID or ADDR, and its value, SETNAME takes the name.
+func parseClient(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandClient, args, 1); err != nil {
+		return
+	}
+
+	switch args[0] {
+	case domain.ClientList, domain.ClientGetName:
+		err = checkArgs(domain.CommandClient, args, 1)
+	case domain.ClientSetName:
+		err = checkArgs(domain.CommandClient, args, 2)
+	case domain.ClientKill:
+		if err = checkArgs(domain.CommandClient, args, 3); err != nil {
+			return
+		}
+		switch args[1] {
+		case domain.ClientKillID:
+			if _, convErr := strconv.ParseUint(args[2], 10, 64); convErr != nil {
+				err = fmt.Errorf("invalid client id %q for CLIENT command", args[2])
+			}
+		case domain.ClientKillAddr:
+		default:
+			err = fmt.Errorf("unsupported filter %q for CLIENT KILL command", args[1])
+		}
+	default:
+		err = fmt.Errorf("unsupported subcommand %q for CLIENT command", args[0])
+	}
+	if err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandClient
+	cmd.Args = args
+	return
+}
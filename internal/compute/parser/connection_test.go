@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Connection(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in:  "AUTH alice secret",
+			out: domain.Command{Type: domain.CommandAuth, Args: []string{"alice", "secret"}},
+		},
+		{
+			in:  "AUTH alice",
+			err: true,
+		},
+		{
+			in:  "AUTH alice secret more",
+			err: true,
+		},
+		{
+			in:  "CLIENT LIST",
+			out: domain.Command{Type: domain.CommandClient, Args: []string{"LIST"}},
+		},
+		{
+			in:  "CLIENT KILL ID 7",
+			out: domain.Command{Type: domain.CommandClient, Args: []string{"KILL", "ID", "7"}},
+		},
+		{
+			in:  "CLIENT KILL ADDR 10.0.0.1:5000",
+			out: domain.Command{Type: domain.CommandClient, Args: []string{"KILL", "ADDR", "10.0.0.1:5000"}},
+		},
+		{
+			in:  "CLIENT SETNAME worker-1",
+			out: domain.Command{Type: domain.CommandClient, Args: []string{"SETNAME", "worker-1"}},
+		},
+		{
+			in:  "CLIENT GETNAME",
+			out: domain.Command{Type: domain.CommandClient, Args: []string{"GETNAME"}},
+		},
+		{
+			in:  "CLIENT KILL ID x",
+			err: true,
+		},
+		{
+			in:  "CLIENT KILL USER alice",
+			err: true,
+		},
+		{
+			in:  "CLIENT KILL 10.0.0.1:5000",
+			err: true,
+		},
+		{
+			in:  "CLIENT LIST all",
+			err: true,
+		},
+		{
+			in:  "CLIENT SETNAME",
+			err: true,
+		},
+		{
+			in:  "CLIENT PAUSE",
+			err: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, err := Parse(tc.in)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.out, cmd)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func parseHSet(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandHSet, args, 3); err != nil {
+		return
+	}
+	if len(args)%2 != 1 {
+		err = fmt.Errorf("odd field value arguments number for HSET command")
+		return
+	}
+
+	cmd.Type = domain.CommandHSet
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+func parseHGet(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandHGet, args, 2); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandHGet
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+func parseHDel(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandHDel, args, 2); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandHDel
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+func parseHIncrBy(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandHIncrBy, args, 3); err != nil {
+		return
+	}
+	if _, err = strconv.ParseInt(args[2], 10, 64); err != nil {
+		err = fmt.Errorf("invalid increment for HINCRBY command: %w", domain.ErrNotInteger)
+		return
+	}
+
+	cmd.Type = domain.CommandHIncrBy
+	cmd.Key = args[0]
+	cmd.Value = args[2]
+	cmd.Args = args[1:2]
+	return
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Hash(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in: "HSET user name john age 42",
+			out: domain.Command{
+				Type: domain.CommandHSet,
+				Key:  "user",
+				Args: []string{"name", "john", "age", "42"},
+			},
+		},
+		{
+			in:  "HSET user name john age",
+			err: true,
+		},
+		{
+			in: "HGET user name",
+			out: domain.Command{
+				Type: domain.CommandHGet,
+				Key:  "user",
+				Args: []string{"name"},
+			},
+		},
+		{
+			in:  "HGET user",
+			err: true,
+		},
+		{
+			in: "HDEL user name age",
+			out: domain.Command{
+				Type: domain.CommandHDel,
+				Key:  "user",
+				Args: []string{"name", "age"},
+			},
+		},
+		{
+			in: "HGETALL user",
+			out: domain.Command{
+				Type: domain.CommandHGetAll,
+				Key:  "user",
+			},
+		},
+		{
+			in:  "HKEYS user name",
+			err: true,
+		},
+		{
+			in: "HLEN user",
+			out: domain.Command{
+				Type: domain.CommandHLen,
+				Key:  "user",
+			},
+		},
+		{
+			in: "HINCRBY user age 5",
+			out: domain.Command{
+				Type:  domain.CommandHIncrBy,
+				Key:   "user",
+				Value: "5",
+				Args:  []string{"age"},
+			},
+		},
+		{
+			in:  "HINCRBY user age five",
+			err: true,
+		},
+	}
+
+	for i, c := range tt {
+		cmd, err := Parse(c.in)
+		if c.err {
+			require.Errorf(t, err, "iter %d", i)
+		} else {
+			require.NoErrorf(t, err, "iter %d", i)
+			require.Equal(t, c.out, cmd)
+		}
+	}
+}
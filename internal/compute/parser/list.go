@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func parsePush(t domain.CommandType) parseArgFunc {
+	return func(args []string) (cmd domain.Command, err error) {
+		if err = checkVarArgs(t, args, 2); err != nil {
+			return
+		}
+
+		cmd.Type = t
+		cmd.Key = args[0]
+		cmd.Args = args[1:]
+		return
+	}
+}
+
+// parseKeyRange builds a parser for commands accepting a key with start and stop indexes.
+func parseKeyRange(t domain.CommandType) parseArgFunc {
+	return func(args []string) (cmd domain.Command, err error) {
+		if err = checkArgs(t, args, 3); err != nil {
+			return
+		}
+		for _, a := range args[1:] {
+			if _, err = strconv.Atoi(a); err != nil {
+				err = fmt.Errorf("invalid range for %s command: %w", t, domain.ErrNotInteger)
+				return
+			}
+		}
+
+		cmd.Type = t
+		cmd.Key = args[0]
+		cmd.Args = args[1:]
+		return
+	}
+}
+
+// parseBlockingPop builds a parser for commands accepting keys followed by
+// the timeout in seconds.
+func parseBlockingPop(t domain.CommandType) parseArgFunc {
+	return func(args []string) (cmd domain.Command, err error) {
+		if err = checkVarArgs(t, args, 2); err != nil {
+			return
+		}
+		timeout := args[len(args)-1]
+		if _, err = parseTimeout(timeout); err != nil {
+			err = fmt.Errorf("invalid timeout for %s command: %w", t, err)
+			return
+		}
+
+		cmd.Type = t
+		cmd.Value = timeout
+		cmd.Args = args[:len(args)-1]
+		return
+	}
+}
+
+// maxTimeout is the longest timeout in seconds time.Duration holds.
+const maxTimeout = float64(math.MaxInt64) / float64(time.Second)
+
+func parseTimeout(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, domain.ErrNotFloat
+	}
+	if f < 0 {
+		return 0, fmt.Errorf("timeout is negative")
+	}
+	if f >= maxTimeout {
+		return 0, fmt.Errorf("timeout is too large")
+	}
+	return f, nil
+}
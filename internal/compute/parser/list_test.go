@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_List(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in: "LPUSH queue a b",
+			out: domain.Command{
+				Type: domain.CommandLPush,
+				Key:  "queue",
+				Args: []string{"a", "b"},
+			},
+		},
+		{
+			in:  "RPUSH queue",
+			err: true,
+		},
+		{
+			in: "RPOP queue",
+			out: domain.Command{
+				Type: domain.CommandRPop,
+				Key:  "queue",
+			},
+		},
+		{
+			in: "LRANGE queue 0 -1",
+			out: domain.Command{
+				Type: domain.CommandLRange,
+				Key:  "queue",
+				Args: []string{"0", "-1"},
+			},
+		},
+		{
+			in:  "LTRIM queue 0 last",
+			err: true,
+		},
+		{
+			in: "BLPOP queue1 queue2 0.5",
+			out: domain.Command{
+				Type:  domain.CommandBLPop,
+				Value: "0.5",
+				Args:  []string{"queue1", "queue2"},
+			},
+		},
+		{
+			in:  "BRPOP queue -1",
+			err: true,
+		},
+		{
+			in:  "BLPOP queue NaN",
+			err: true,
+		},
+		{
+			in:  "BLPOP queue +Inf",
+			err: true,
+		},
+		{
+			in:  "BLPOP queue 1e300",
+			err: true,
+		},
+		{
+			in:  "BRPOP queue",
+			err: true,
+		},
+	}
+
+	for i, c := range tt {
+		cmd, err := Parse(c.in)
+		if c.err {
+			require.Errorf(t, err, "iter %d", i)
+		} else {
+			require.NoErrorf(t, err, "iter %d", i)
+			require.Equal(t, c.out, cmd)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/tmvrus/key-value-storage/internal/domain"
@@ -9,6 +11,48 @@ import (
 
 type parseArgFunc func([]string) (domain.Command, error)
 
+func checkArgs(t domain.CommandType, args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("invalid arguments number for %s command", t)
+	}
+	return CheckArgs(t, args...)
+}
+
+// CheckArgs validates arguments of a command built without parsing, like in
+// gateways of other protocols, they must be ones the text protocol can carry:
+// not empty and without spaces or line breaks.
+func CheckArgs(t domain.CommandType, args ...string) error {
+	for _, a := range args {
+		if a == "" {
+			return fmt.Errorf("empty arguments for %s command", t)
+		}
+		if strings.ContainsAny(a, " \r\n") {
+			return fmt.Errorf("arguments of %s command contain spaces or line breaks", t)
+		}
+	}
+	return nil
+}
+
+// parseKeyOnly builds a parser for commands accepting a single key argument.
+func parseKeyOnly(t domain.CommandType) parseArgFunc {
+	return func(args []string) (cmd domain.Command, err error) {
+		if err = checkArgs(t, args, 1); err != nil {
+			return
+		}
+
+		cmd.Type = t
+		cmd.Key = args[0]
+		return
+	}
+}
+
+func checkVarArgs(t domain.CommandType, args []string, minimum int) error {
+	if len(args) < minimum {
+		return fmt.Errorf("invalid arguments number for %s command", t)
+	}
+	return CheckArgs(t, args...)
+}
+
 func parseGet(args []string) (cmd domain.Command, err error) {
 	if len(args) != 1 {
 		err = fmt.Errorf("invalid arguments number for GET command")
@@ -55,24 +99,72 @@ func parseDelete(args []string) (cmd domain.Command, err error) {
 	return
 }
 
-func Parse(s string) (cmd domain.Command, err error) {
-	m := map[domain.CommandType]parseArgFunc{
-		domain.CommandGet:    parseGet,
-		domain.CommandSet:    parseSet,
-		domain.CommandDelete: parseDelete,
+func parseIncr(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandIncr, args, 1); err != nil {
+		return
 	}
 
-	args := strings.Split(s, " ")
-	if len(args) < 2 {
-		err = fmt.Errorf("invalid arguments numbers")
+	cmd.Type = domain.CommandIncr
+	cmd.Key = args[0]
+	return
+}
+
+func parseDecr(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandDecr, args, 1); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandDecr
+	cmd.Key = args[0]
+	return
+}
+
+func parseIncrBy(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandIncrBy, args, 2); err != nil {
+		return
+	}
+	if _, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+		err = fmt.Errorf("invalid increment for INCRBY command: %w", domain.ErrNotInteger)
+		return
+	}
+
+	cmd.Type = domain.CommandIncrBy
+	cmd.Key = args[0]
+	cmd.Value = args[1]
+	return
+}
+
+func parseIncrByFloat(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandIncrByFloat, args, 2); err != nil {
+		return
+	}
+	f, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		err = fmt.Errorf("invalid increment for INCRBYFLOAT command: %w", domain.ErrNotFloat)
 		return
 	}
 
-	f, ok := m[domain.CommandType(args[0])]
+	cmd.Type = domain.CommandIncrByFloat
+	cmd.Key = args[0]
+	cmd.Value = args[1]
+	return
+}
+
+func Parse(s string) (cmd domain.Command, err error) {
+	if s == "" {
+		err = fmt.Errorf("empty command")
+		return
+	}
+	args := strings.Split(s, " ")
+
+	c, ok := Lookup(domain.CommandType(args[0]))
 	if !ok {
 		err = fmt.Errorf("unsupported operation")
 		return
 	}
+	if err = c.checkArity(len(args)); err != nil {
+		return
+	}
 
-	return f(args[1:])
+	return c.parse(args[1:])
 }
@@ -57,6 +57,56 @@ func TestParse(t *testing.T) {
 			in:  "SET key ",
 			err: true,
 		},
+		{
+			in: "INCR counter",
+			out: domain.Command{
+				Type: domain.CommandIncr,
+				Key:  "counter",
+			},
+		},
+		{
+			in:  "INCR counter 1",
+			err: true,
+		},
+		{
+			in: "DECR counter",
+			out: domain.Command{
+				Type: domain.CommandDecr,
+				Key:  "counter",
+			},
+		},
+		{
+			in: "INCRBY counter -10",
+			out: domain.Command{
+				Type:  domain.CommandIncrBy,
+				Key:   "counter",
+				Value: "-10",
+			},
+		},
+		{
+			in:  "INCRBY counter 1.5",
+			err: true,
+		},
+		{
+			in:  "INCRBY counter",
+			err: true,
+		},
+		{
+			in: "INCRBYFLOAT counter 0.25",
+			out: domain.Command{
+				Type:  domain.CommandIncrByFloat,
+				Key:   "counter",
+				Value: "0.25",
+			},
+		},
+		{
+			in:  "INCRBYFLOAT counter NaN",
+			err: true,
+		},
+		{
+			in:  "INCRBYFLOAT counter abc",
+			err: true,
+		},
 	}
 
 	for i, c := range tt {
@@ -68,3 +118,14 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckArgs(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, CheckArgs(domain.CommandSet, "key", "value"))
+	require.NoError(t, CheckArgs(domain.CommandGet))
+	require.Error(t, CheckArgs(domain.CommandSet, "key", ""))
+	require.Error(t, CheckArgs(domain.CommandSet, "key", "two words"))
+	require.Error(t, CheckArgs(domain.CommandSet, "key\nGET other", "value"))
+	require.Error(t, CheckArgs(domain.CommandSet, "key", "value\r"))
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// parseRaft produces Args: the subcommand followed by its arguments,
+// ADD takes member ID, raft address and client address, REMOVE takes member ID.
+func parseRaft(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandRaft, args, 1); err != nil {
+		return
+	}
+
+	switch args[0] {
+	case domain.RaftStatus:
+		err = checkArgs(domain.CommandRaft, args, 1)
+	case domain.RaftAdd:
+		err = checkArgs(domain.CommandRaft, args, 4)
+	case domain.RaftRemove:
+		err = checkArgs(domain.CommandRaft, args, 2)
+	default:
+		err = fmt.Errorf("unsupported subcommand %q for RAFT command", args[0])
+	}
+	if err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandRaft
+	cmd.Args = args
+	return
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Raft(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in:  "RAFT STATUS",
+			out: domain.Command{Type: domain.CommandRaft, Args: []string{"STATUS"}},
+		},
+		{
+			in:  "RAFT ADD n4 10.0.0.4:4001 10.0.0.4:3223",
+			out: domain.Command{Type: domain.CommandRaft, Args: []string{"ADD", "n4", "10.0.0.4:4001", "10.0.0.4:3223"}},
+		},
+		{
+			in:  "RAFT REMOVE n4",
+			out: domain.Command{Type: domain.CommandRaft, Args: []string{"REMOVE", "n4"}},
+		},
+		{
+			in:  "RAFT STATUS n1",
+			err: true,
+		},
+		{
+			in:  "RAFT ADD n4 10.0.0.4:4001",
+			err: true,
+		},
+		{
+			in:  "RAFT REMOVE",
+			err: true,
+		},
+		{
+			in:  "RAFT LEADER",
+			err: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, err := Parse(tc.in)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.out, cmd)
+		})
+	}
+}
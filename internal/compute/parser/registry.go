@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// CommandFlags are properties of a command combined with |.
+type CommandFlags uint8
+
+const (
+	// FlagWrite marks commands changing the stored data, they are
+	// replicated, audited and reported to watchers.
+	FlagWrite CommandFlags = 1 << iota
+)
+
+// CommandInfo describes a command of the text protocol.
+type CommandInfo struct {
+	Type domain.CommandType
+	// Arity is the number of words of the command, the name included, a
+	// negative arity means at least that many words, Parse enforces it.
+	Arity int
+	Flags CommandFlags
+	// Usage shows the command with its arguments, optional ones go in
+	// square brackets and alternatives are separated by |.
+	Usage string
+
+	parse parseArgFunc
+}
+
+// commands is the registry of supported commands, the only list of them:
+// Parse, HELP and COMMAND are driven by it.
+var commands = []CommandInfo{
+	{domain.CommandGet, 2, 0, "GET key", parseGet},
+	{domain.CommandSet, 3, FlagWrite, "SET key value", parseSet},
+	{domain.CommandDelete, 2, FlagWrite, "DELETE key", parseDelete},
+
+	{domain.CommandIncr, 2, FlagWrite, "INCR key", parseIncr},
+	{domain.CommandDecr, 2, FlagWrite, "DECR key", parseDecr},
+	{domain.CommandIncrBy, 3, FlagWrite, "INCRBY key increment", parseIncrBy},
+	{domain.CommandIncrByFloat, 3, FlagWrite, "INCRBYFLOAT key increment", parseIncrByFloat},
+
+	{domain.CommandAppend, 3, FlagWrite, "APPEND key value", parseAppend},
+	{domain.CommandGetRange, 4, 0, "GETRANGE key start end", parseGetRange},
+	{domain.CommandSetRange, 4, FlagWrite, "SETRANGE key offset value", parseSetRange},
+	{domain.CommandStrLen, 2, 0, "STRLEN key", parseStrLen},
+	{domain.CommandGetSet, 3, FlagWrite, "GETSET key value", parseGetSet},
+	{domain.CommandSetNX, 3, FlagWrite, "SETNX key value", parseSetNX},
+
+	{domain.CommandMGet, -2, 0, "MGET key [key ...]", parseMGet},
+	{domain.CommandMSet, -3, FlagWrite, "MSET key value [key value ...]", parseMSet},
+	{domain.CommandMDelete, -2, FlagWrite, "MDELETE key [key ...]", parseMDelete},
+
+	{domain.CommandHSet, -4, FlagWrite, "HSET key field value [field value ...]", parseHSet},
+	{domain.CommandHGet, 3, 0, "HGET key field", parseHGet},
+	{domain.CommandHDel, -3, FlagWrite, "HDEL key field [field ...]", parseHDel},
+	{domain.CommandHGetAll, 2, 0, "HGETALL key", parseKeyOnly(domain.CommandHGetAll)},
+	{domain.CommandHKeys, 2, 0, "HKEYS key", parseKeyOnly(domain.CommandHKeys)},
+	{domain.CommandHVals, 2, 0, "HVALS key", parseKeyOnly(domain.CommandHVals)},
+	{domain.CommandHLen, 2, 0, "HLEN key", parseKeyOnly(domain.CommandHLen)},
+	{domain.CommandHIncrBy, 4, FlagWrite, "HINCRBY key field increment", parseHIncrBy},
+
+	{domain.CommandLPush, -3, FlagWrite, "LPUSH key element [element ...]", parsePush(domain.CommandLPush)},
+	{domain.CommandRPush, -3, FlagWrite, "RPUSH key element [element ...]", parsePush(domain.CommandRPush)},
+	{domain.CommandLPop, 2, FlagWrite, "LPOP key", parseKeyOnly(domain.CommandLPop)},
+	{domain.CommandRPop, 2, FlagWrite, "RPOP key", parseKeyOnly(domain.CommandRPop)},
+	{domain.CommandLRange, 4, 0, "LRANGE key start stop", parseKeyRange(domain.CommandLRange)},
+	{domain.CommandLLen, 2, 0, "LLEN key", parseKeyOnly(domain.CommandLLen)},
+	{domain.CommandLTrim, 4, FlagWrite, "LTRIM key start stop", parseKeyRange(domain.CommandLTrim)},
+	{domain.CommandBLPop, -3, FlagWrite, "BLPOP key [key ...] timeout", parseBlockingPop(domain.CommandBLPop)},
+	{domain.CommandBRPop, -3, FlagWrite, "BRPOP key [key ...] timeout", parseBlockingPop(domain.CommandBRPop)},
+
+	{domain.CommandSAdd, -3, FlagWrite, "SADD key member [member ...]", parseKeyMembers(domain.CommandSAdd)},
+	{domain.CommandSRem, -3, FlagWrite, "SREM key member [member ...]", parseKeyMembers(domain.CommandSRem)},
+	{domain.CommandSMembers, 2, 0, "SMEMBERS key", parseKeyOnly(domain.CommandSMembers)},
+	{domain.CommandSIsMember, 3, 0, "SISMEMBER key member", parseKeyMember(domain.CommandSIsMember)},
+	{domain.CommandSInter, -2, 0, "SINTER key [key ...]", parseKeys(domain.CommandSInter)},
+	{domain.CommandSUnion, -2, 0, "SUNION key [key ...]", parseKeys(domain.CommandSUnion)},
+	{domain.CommandSDiff, -2, 0, "SDIFF key [key ...]", parseKeys(domain.CommandSDiff)},
+	{domain.CommandSCard, 2, 0, "SCARD key", parseKeyOnly(domain.CommandSCard)},
+
+	{domain.CommandZAdd, -4, FlagWrite, "ZADD key score member [score member ...]", parseZAdd},
+	{domain.CommandZRem, -3, FlagWrite, "ZREM key member [member ...]", parseKeyMembers(domain.CommandZRem)},
+	{domain.CommandZScore, 3, 0, "ZSCORE key member", parseKeyMember(domain.CommandZScore)},
+	{domain.CommandZRank, 3, 0, "ZRANK key member", parseKeyMember(domain.CommandZRank)},
+	{domain.CommandZRange, -4, 0, "ZRANGE key start stop [WITHSCORES]", parseZRange},
+	{domain.CommandZRangeByScore, -4, 0, "ZRANGEBYSCORE key min max [WITHSCORES]", parseZRangeByScore},
+	{domain.CommandZIncrBy, 4, FlagWrite, "ZINCRBY key increment member", parseZIncrBy},
+
+	{domain.CommandXAdd, -5, FlagWrite, "XADD key ID|* field value [field value ...]", parseXAdd},
+	{domain.CommandXRange, -4, 0, "XRANGE key start end [COUNT count]", parseXRange},
+	{domain.CommandXRead, -4, 0, "XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] ID [ID ...]", parseXRead},
+	{domain.CommandXGroup, -5, FlagWrite, "XGROUP CREATE key group ID|$ [MKSTREAM]", parseXGroup},
+	{domain.CommandXReadGroup, -7, FlagWrite, "XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] ID [ID ...]", parseXReadGroup},
+	{domain.CommandXAck, -4, FlagWrite, "XACK key group ID [ID ...]", parseXAck},
+	{domain.CommandXPending, -3, 0, "XPENDING key group [consumer]", parseXPending},
+
+	{domain.CommandRaft, -2, 0, "RAFT STATUS|ADD id raft-address client-address|REMOVE id", parseRaft},
+
+	{domain.CommandCluster, -2, 0, "CLUSTER SLOTS|KEYSLOT key|SETSLOT slot state [node]|MIGRATE slots node|MIGRATE STATUS|MIGRATE RESUME", parseCluster},
+	{domain.CommandAsking, 1, 0, "ASKING", parseAsking},
+	{domain.CommandRestore, -3, FlagWrite, "RESTORE key dump [key dump ...]", parseRestore},
+
+	{domain.CommandAuth, 3, 0, "AUTH user password", parseAuth},
+	{domain.CommandClient, -2, 0, "CLIENT LIST|GETNAME|SETNAME name|KILL ID id|KILL ADDR address", parseClient},
+
+	{domain.CommandSlowLog, -2, 0, "SLOWLOG GET [count]|LEN|RESET", parseSlowLog},
+	{domain.CommandMonitor, 1, 0, "MONITOR", parseMonitor},
+
+	{domain.CommandPing, -1, 0, "PING [message]", parsePing},
+	{domain.CommandEcho, 2, 0, "ECHO message", parseEcho},
+	{domain.CommandQuit, 1, 0, "QUIT", parseQuit},
+	{domain.CommandHelp, -1, 0, "HELP [command]", parseHelp},
+	{domain.CommandCommand, 1, 0, "COMMAND", parseCommand},
+}
+
+// commandsByType is filled by init, initializing it along with its
+// declaration would be a cycle as parseHelp looks commands up.
+var commandsByType map[domain.CommandType]CommandInfo
+
+func init() {
+	commandsByType = indexCommands(commands)
+}
+
+func indexCommands(cmds []CommandInfo) map[domain.CommandType]CommandInfo {
+	m := make(map[domain.CommandType]CommandInfo, len(cmds))
+	for _, c := range cmds {
+		m[c.Type] = c
+	}
+	return m
+}
+
+// Commands returns every supported command in the order of the registry.
+func Commands() []CommandInfo {
+	return slices.Clone(commands)
+}
+
+// Lookup returns the description of the supported command.
+func Lookup(t domain.CommandType) (CommandInfo, bool) {
+	c, ok := commandsByType[t]
+	return c, ok
+}
+
+// Mutating reports whether the command changes the stored data.
+func Mutating(t domain.CommandType) bool {
+	c, ok := Lookup(t)
+	return ok && c.Flags&FlagWrite != 0
+}
+
+// checkArity checks the number of words of the command, the name included.
+func (c CommandInfo) checkArity(words int) error {
+	if c.Arity >= 0 && words != c.Arity || c.Arity < 0 && words < -c.Arity {
+		return fmt.Errorf("invalid arguments number for %s command", c.Type)
+	}
+	return nil
+}
+
+// HelpLines returns lines of the HELP reply: usages of all commands or of the
+// given one.
+func HelpLines(t domain.CommandType) ([]string, error) {
+	if t != "" {
+		c, ok := Lookup(t)
+		if !ok {
+			return nil, fmt.Errorf("unsupported command %q for HELP command", t)
+		}
+		return []string{c.Usage}, nil
+	}
+
+	lines := make([]string, len(commands))
+	for i, c := range commands {
+		lines[i] = c.Usage
+	}
+	return lines, nil
+}
+
+// CommandLines returns lines of the COMMAND reply: names of all commands
+// with their arity.
+func CommandLines() []string {
+	lines := make([]string, len(commands))
+	for i, c := range commands {
+		lines[i] = string(c.Type) + " " + strconv.Itoa(c.Arity)
+	}
+	return lines
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[domain.CommandType]bool)
+	for _, c := range Commands() {
+		require.False(t, seen[c.Type], "%s is registered twice", c.Type)
+		seen[c.Type] = true
+
+		require.NotZero(t, c.Arity, c.Type)
+		require.True(t, strings.HasPrefix(c.Usage+" ", string(c.Type)+" "), c.Usage)
+		require.NotNil(t, c.parse, c.Type)
+
+		info, ok := Lookup(c.Type)
+		require.True(t, ok)
+		require.Equal(t, c.Usage, info.Usage)
+	}
+
+	_, ok := Lookup("FLUSHALL")
+	require.False(t, ok)
+}
+
+func TestParse_Arity(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range Commands() {
+		words := c.Arity
+		if words < 0 {
+			words = -words
+		}
+		if words > 1 {
+			line := string(c.Type) + strings.Repeat(" x", words-2)
+			_, err := Parse(line)
+			require.ErrorContains(t, err, "invalid arguments number", line)
+		}
+		if c.Arity > 0 {
+			line := string(c.Type) + strings.Repeat(" x", c.Arity)
+			_, err := Parse(line)
+			require.ErrorContains(t, err, "invalid arguments number", line)
+		}
+	}
+}
+
+func TestMutating(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []domain.CommandType{domain.CommandSet, domain.CommandDelete, domain.CommandMSet, domain.CommandLPop,
+		domain.CommandXAdd, domain.CommandXReadGroup, domain.CommandRestore} {
+		require.True(t, Mutating(v), v)
+	}
+	for _, v := range []domain.CommandType{domain.CommandGet, domain.CommandMGet, domain.CommandLRange,
+		domain.CommandXRead, domain.CommandRaft, "FLUSHALL"} {
+		require.False(t, Mutating(v), v)
+	}
+}
+
+func TestHelpLines(t *testing.T) {
+	t.Parallel()
+
+	lines, err := HelpLines(domain.CommandGet)
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET key"}, lines)
+
+	lines, err = HelpLines("")
+	require.NoError(t, err)
+	require.Len(t, lines, len(Commands()))
+
+	_, err = HelpLines("FLUSHALL")
+	require.Error(t, err)
+
+	_, err = Parse("HELP FLUSHALL")
+	require.Error(t, err)
+	require.Contains(t, CommandLines(), "MGET -2")
+}
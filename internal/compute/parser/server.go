@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// parseSlowLog produces Args: the subcommand, GET may be followed by the
+// number of entries.
+func parseSlowLog(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandSlowLog, args, 1); err != nil {
+		return
+	}
+
+	switch args[0] {
+	case domain.SlowLogGet:
+		if len(args) > 2 {
+			err = checkArgs(domain.CommandSlowLog, args, 2)
+			return
+		}
+		if len(args) == 2 {
+			if n, convErr := strconv.Atoi(args[1]); convErr != nil || n < 0 {
+				err = fmt.Errorf("invalid number of entries %q for SLOWLOG command", args[1])
+				return
+			}
+		}
+	case domain.SlowLogLen, domain.SlowLogReset:
+		if err = checkArgs(domain.CommandSlowLog, args, 1); err != nil {
+			return
+		}
+	default:
+		err = fmt.Errorf("unsupported subcommand %q for SLOWLOG command", args[0])
+		return
+	}
+
+	cmd.Type = domain.CommandSlowLog
+	cmd.Args = args
+	return
+}
+
+func parseMonitor(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandMonitor, args, 0); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandMonitor
+	return
+}
+
+// parsePing produces Value: the optional message to reply with.
+func parsePing(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandPing, args, 0); err != nil {
+		return
+	}
+	if len(args) > 1 {
+		err = fmt.Errorf("invalid arguments number for PING command")
+		return
+	}
+
+	cmd.Type = domain.CommandPing
+	if len(args) == 1 {
+		cmd.Value = args[0]
+	}
+	return
+}
+
+func parseEcho(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandEcho, args, 1); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandEcho
+	cmd.Value = args[0]
+	return
+}
+
+func parseQuit(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandQuit, args, 0); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandQuit
+	return
+}
+
+// parseHelp produces Args: the optional command to describe.
+func parseHelp(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandHelp, args, 0); err != nil {
+		return
+	}
+	if len(args) > 1 {
+		err = fmt.Errorf("invalid arguments number for HELP command")
+		return
+	}
+	if len(args) == 1 {
+		if _, ok := Lookup(domain.CommandType(args[0])); !ok {
+			err = fmt.Errorf("unsupported command %q for HELP command", args[0])
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandHelp
+	cmd.Args = args
+	return
+}
+
+func parseCommand(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandCommand, args, 0); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandCommand
+	return
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Server(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in:  "SLOWLOG GET",
+			out: domain.Command{Type: domain.CommandSlowLog, Args: []string{"GET"}},
+		},
+		{
+			in:  "SLOWLOG GET 5",
+			out: domain.Command{Type: domain.CommandSlowLog, Args: []string{"GET", "5"}},
+		},
+		{
+			in:  "SLOWLOG LEN",
+			out: domain.Command{Type: domain.CommandSlowLog, Args: []string{"LEN"}},
+		},
+		{
+			in:  "SLOWLOG RESET",
+			out: domain.Command{Type: domain.CommandSlowLog, Args: []string{"RESET"}},
+		},
+		{
+			in:  "SLOWLOG GET -1",
+			err: true,
+		},
+		{
+			in:  "SLOWLOG GET 5 6",
+			err: true,
+		},
+		{
+			in:  "SLOWLOG LEN 5",
+			err: true,
+		},
+		{
+			in:  "SLOWLOG",
+			err: true,
+		},
+		{
+			in:  "SLOWLOG FLUSH",
+			err: true,
+		},
+		{
+			in:  "MONITOR",
+			out: domain.Command{Type: domain.CommandMonitor},
+		},
+		{
+			in:  "MONITOR all",
+			err: true,
+		},
+		{
+			in:  "PING",
+			out: domain.Command{Type: domain.CommandPing},
+		},
+		{
+			in:  "PING hello",
+			out: domain.Command{Type: domain.CommandPing, Value: "hello"},
+		},
+		{
+			in:  "PING hello world",
+			err: true,
+		},
+		{
+			in:  "PING ",
+			err: true,
+		},
+		{
+			in:  "ECHO hello",
+			out: domain.Command{Type: domain.CommandEcho, Value: "hello"},
+		},
+		{
+			in:  "ECHO",
+			err: true,
+		},
+		{
+			in:  "QUIT",
+			out: domain.Command{Type: domain.CommandQuit},
+		},
+		{
+			in:  "QUIT now",
+			err: true,
+		},
+		{
+			in:  "HELP",
+			out: domain.Command{Type: domain.CommandHelp, Args: []string{}},
+		},
+		{
+			in:  "HELP GET",
+			out: domain.Command{Type: domain.CommandHelp, Args: []string{"GET"}},
+		},
+		{
+			in:  "HELP FLUSHALL",
+			err: true,
+		},
+		{
+			in:  "HELP GET SET",
+			err: true,
+		},
+		{
+			in:  "COMMAND",
+			out: domain.Command{Type: domain.CommandCommand},
+		},
+		{
+			in:  "COMMAND INFO",
+			err: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, err := Parse(tc.in)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.out, cmd)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// parseKeyMembers builds a parser for commands accepting a key followed by members.
+func parseKeyMembers(t domain.CommandType) parseArgFunc {
+	return func(args []string) (cmd domain.Command, err error) {
+		if err = checkVarArgs(t, args, 2); err != nil {
+			return
+		}
+
+		cmd.Type = t
+		cmd.Key = args[0]
+		cmd.Args = args[1:]
+		return
+	}
+}
+
+// parseKeyMember builds a parser for commands accepting a key and a single member.
+func parseKeyMember(t domain.CommandType) parseArgFunc {
+	return func(args []string) (cmd domain.Command, err error) {
+		if err = checkArgs(t, args, 2); err != nil {
+			return
+		}
+
+		cmd.Type = t
+		cmd.Key = args[0]
+		cmd.Args = args[1:]
+		return
+	}
+}
+
+// parseKeys builds a parser for commands accepting one or more keys.
+func parseKeys(t domain.CommandType) parseArgFunc {
+	return func(args []string) (cmd domain.Command, err error) {
+		if err = checkVarArgs(t, args, 1); err != nil {
+			return
+		}
+
+		cmd.Type = t
+		cmd.Args = args
+		return
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Set(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in: "SADD tags go redis",
+			out: domain.Command{
+				Type: domain.CommandSAdd,
+				Key:  "tags",
+				Args: []string{"go", "redis"},
+			},
+		},
+		{
+			in:  "SREM tags",
+			err: true,
+		},
+		{
+			in: "SISMEMBER tags go",
+			out: domain.Command{
+				Type: domain.CommandSIsMember,
+				Key:  "tags",
+				Args: []string{"go"},
+			},
+		},
+		{
+			in:  "SISMEMBER tags go redis",
+			err: true,
+		},
+		{
+			in: "SINTER tags1 tags2",
+			out: domain.Command{
+				Type: domain.CommandSInter,
+				Args: []string{"tags1", "tags2"},
+			},
+		},
+		{
+			in: "SCARD tags",
+			out: domain.Command{
+				Type: domain.CommandSCard,
+				Key:  "tags",
+			},
+		},
+	}
+
+	for i, c := range tt {
+		cmd, err := Parse(c.in)
+		if c.err {
+			require.Errorf(t, err, "iter %d", i)
+		} else {
+			require.NoErrorf(t, err, "iter %d", i)
+			require.Equal(t, c.out, cmd)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const (
+	streamAutoID   = "*"
+	streamMinID    = "-"
+	streamMaxID    = "+"
+	streamLatestID = "$"
+	streamNewID    = ">"
+
+	noCount = "0"
+	noBlock = "-1"
+)
+
+// parseXAdd produces Args: ID or "*", then field value pairs.
+func parseXAdd(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandXAdd, args, 4); err != nil {
+		return
+	}
+	if len(args)%2 != 0 {
+		err = fmt.Errorf("odd field value arguments number for XADD command")
+		return
+	}
+	if args[1] != streamAutoID {
+		var id domain.StreamID
+		if id, err = domain.ParseStreamID(args[1], 0); err != nil {
+			return
+		}
+		if id.IsZero() {
+			err = fmt.Errorf("the ID specified in XADD must be greater than 0-0")
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandXAdd
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+// parseXRange produces Args: start ID or "-", end ID or "+", count.
+func parseXRange(args []string) (cmd domain.Command, err error) {
+	count := noCount
+	if len(args) == 5 {
+		if count, err = parseCountOption(args[3:]); err != nil {
+			return
+		}
+		args = args[:3]
+	}
+	if err = checkArgs(domain.CommandXRange, args, 3); err != nil {
+		return
+	}
+	if args[1] != streamMinID {
+		if _, err = domain.ParseStreamID(args[1], 0); err != nil {
+			return
+		}
+	}
+	if args[2] != streamMaxID {
+		if _, err = domain.ParseStreamID(args[2], math.MaxUint64); err != nil {
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandXRange
+	cmd.Key = args[0]
+	cmd.Args = []string{args[1], args[2], count}
+	return
+}
+
+// parseXRead produces Args: count, block milliseconds, keys followed by IDs.
+func parseXRead(args []string) (cmd domain.Command, err error) {
+	count, block, streams, err := parseReadOptions(domain.CommandXRead, args)
+	if err != nil {
+		return
+	}
+	if err = checkStreams(domain.CommandXRead, streams, streamLatestID); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandXRead
+	cmd.Args = append([]string{count, block}, streams...)
+	return
+}
+
+// parseXGroup supports the CREATE subcommand only and produces Args:
+// group, ID or "$", optional MKSTREAM flag.
+func parseXGroup(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandXGroup, args, 4); err != nil {
+		return
+	}
+	if args[0] != "CREATE" {
+		err = fmt.Errorf("unsupported subcommand %q for XGROUP command", args[0])
+		return
+	}
+	if len(args) > 5 || (len(args) == 5 && args[4] != "MKSTREAM") {
+		err = fmt.Errorf("invalid arguments for XGROUP CREATE command")
+		return
+	}
+	if args[3] != streamLatestID {
+		if _, err = domain.ParseStreamID(args[3], 0); err != nil {
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandXGroup
+	cmd.Key = args[1]
+	cmd.Args = args[2:]
+	return
+}
+
+// parseXReadGroup produces Args: group, consumer, count, block milliseconds,
+// keys followed by IDs.
+func parseXReadGroup(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandXReadGroup, args, 6); err != nil {
+		return
+	}
+	if args[0] != "GROUP" {
+		err = fmt.Errorf("missing GROUP option for XREADGROUP command")
+		return
+	}
+
+	count, block, streams, err := parseReadOptions(domain.CommandXReadGroup, args[3:])
+	if err != nil {
+		return
+	}
+	if err = checkStreams(domain.CommandXReadGroup, streams, streamNewID); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandXReadGroup
+	cmd.Args = append([]string{args[1], args[2], count, block}, streams...)
+	return
+}
+
+// parseXAck produces Args: group followed by IDs.
+func parseXAck(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandXAck, args, 3); err != nil {
+		return
+	}
+	for _, id := range args[2:] {
+		if _, err = domain.ParseStreamID(id, 0); err != nil {
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandXAck
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+// parseXPending produces Args: group and optional consumer.
+func parseXPending(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandXPending, args, 2); err != nil {
+		return
+	}
+	if len(args) > 3 {
+		err = fmt.Errorf("invalid arguments number for XPENDING command")
+		return
+	}
+
+	cmd.Type = domain.CommandXPending
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+// parseReadOptions parses optional COUNT and BLOCK options followed by STREAMS.
+func parseReadOptions(t domain.CommandType, args []string) (count, block string, streams []string, err error) {
+	count, block = noCount, noBlock
+
+	for len(args) > 0 {
+		switch args[0] {
+		case "COUNT":
+			if count, err = parseCountOption(args); err != nil {
+				return
+			}
+			args = args[2:]
+		case "BLOCK":
+			if len(args) < 2 {
+				err = fmt.Errorf("missing BLOCK value for %s command", t)
+				return
+			}
+			var ms int
+			if ms, err = strconv.Atoi(args[1]); err != nil || ms < 0 {
+				err = fmt.Errorf("invalid BLOCK value for %s command", t)
+				return
+			}
+			block = args[1]
+			args = args[2:]
+		case "STREAMS":
+			streams = args[1:]
+			return
+		default:
+			err = fmt.Errorf("unsupported option %q for %s command", args[0], t)
+			return
+		}
+	}
+
+	err = fmt.Errorf("missing STREAMS option for %s command", t)
+	return
+}
+
+func parseCountOption(args []string) (string, error) {
+	if len(args) < 2 || args[0] != "COUNT" {
+		return "", fmt.Errorf("invalid COUNT option")
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("invalid COUNT value")
+	}
+	return args[1], nil
+}
+
+// checkStreams checks keys are followed by the same number of IDs,
+// each ID may be replaced with the special one.
+func checkStreams(t domain.CommandType, streams []string, special string) error {
+	if len(streams) == 0 || len(streams)%2 != 0 {
+		return fmt.Errorf("unbalanced STREAMS list for %s command", t)
+	}
+	for _, s := range streams {
+		if s == "" {
+			return fmt.Errorf("empty arguments for %s command", t)
+		}
+	}
+	for _, id := range streams[len(streams)/2:] {
+		if id == special {
+			continue
+		}
+		if _, err := domain.ParseStreamID(id, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Stream(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in: "XADD events * user 1 action login",
+			out: domain.Command{
+				Type: domain.CommandXAdd,
+				Key:  "events",
+				Args: []string{"*", "user", "1", "action", "login"},
+			},
+		},
+		{
+			in: "XADD events 1526919030474-55 user 1",
+			out: domain.Command{
+				Type: domain.CommandXAdd,
+				Key:  "events",
+				Args: []string{"1526919030474-55", "user", "1"},
+			},
+		},
+		{
+			in:  "XADD events 0-0 user 1",
+			err: true,
+		},
+		{
+			in:  "XADD events * user",
+			err: true,
+		},
+		{
+			in: "XRANGE events - +",
+			out: domain.Command{
+				Type: domain.CommandXRange,
+				Key:  "events",
+				Args: []string{"-", "+", "0"},
+			},
+		},
+		{
+			in: "XRANGE events 100 200-1 COUNT 10",
+			out: domain.Command{
+				Type: domain.CommandXRange,
+				Key:  "events",
+				Args: []string{"100", "200-1", "10"},
+			},
+		},
+		{
+			in:  "XRANGE events - + COUNT",
+			err: true,
+		},
+		{
+			in: "XREAD COUNT 2 BLOCK 100 STREAMS events logs 0 $",
+			out: domain.Command{
+				Type: domain.CommandXRead,
+				Args: []string{"2", "100", "events", "logs", "0", "$"},
+			},
+		},
+		{
+			in: "XREAD STREAMS events 0-1",
+			out: domain.Command{
+				Type: domain.CommandXRead,
+				Args: []string{"0", "-1", "events", "0-1"},
+			},
+		},
+		{
+			in:  "XREAD STREAMS events logs 0",
+			err: true,
+		},
+		{
+			in:  "XREAD BLOCK -1 STREAMS events 0",
+			err: true,
+		},
+		{
+			in: "XGROUP CREATE events workers $ MKSTREAM",
+			out: domain.Command{
+				Type: domain.CommandXGroup,
+				Key:  "events",
+				Args: []string{"workers", "$", "MKSTREAM"},
+			},
+		},
+		{
+			in:  "XGROUP DESTROY events workers",
+			err: true,
+		},
+		{
+			in: "XREADGROUP GROUP workers alice COUNT 1 STREAMS events >",
+			out: domain.Command{
+				Type: domain.CommandXReadGroup,
+				Args: []string{"workers", "alice", "1", "-1", "events", ">"},
+			},
+		},
+		{
+			in:  "XREADGROUP workers alice STREAMS events >",
+			err: true,
+		},
+		{
+			in: "XACK events workers 1-1 1-2",
+			out: domain.Command{
+				Type: domain.CommandXAck,
+				Key:  "events",
+				Args: []string{"workers", "1-1", "1-2"},
+			},
+		},
+		{
+			in: "XPENDING events workers alice",
+			out: domain.Command{
+				Type: domain.CommandXPending,
+				Key:  "events",
+				Args: []string{"workers", "alice"},
+			},
+		},
+	}
+
+	for i, c := range tt {
+		cmd, err := Parse(c.in)
+		if c.err {
+			require.Errorf(t, err, "iter %d", i)
+		} else {
+			require.NoErrorf(t, err, "iter %d", i)
+			require.Equal(t, c.out, cmd)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func parseAppend(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandAppend, args, 2); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandAppend
+	cmd.Key = args[0]
+	cmd.Value = args[1]
+	return
+}
+
+func parseGetRange(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandGetRange, args, 3); err != nil {
+		return
+	}
+	for _, a := range args[1:] {
+		if _, err = strconv.Atoi(a); err != nil {
+			err = fmt.Errorf("invalid range for GETRANGE command: %w", domain.ErrNotInteger)
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandGetRange
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+func parseSetRange(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandSetRange, args, 3); err != nil {
+		return
+	}
+	offset, err := strconv.Atoi(args[1])
+	if err != nil {
+		err = fmt.Errorf("invalid offset for SETRANGE command: %w", domain.ErrNotInteger)
+		return
+	}
+	if offset < 0 || offset > domain.MaxStringSize {
+		err = fmt.Errorf("invalid offset for SETRANGE command: %w", domain.ErrOutOfRange)
+		return
+	}
+
+	cmd.Type = domain.CommandSetRange
+	cmd.Key = args[0]
+	cmd.Value = args[2]
+	cmd.Args = args[1:2]
+	return
+}
+
+func parseStrLen(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandStrLen, args, 1); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandStrLen
+	cmd.Key = args[0]
+	return
+}
+
+func parseGetSet(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandGetSet, args, 2); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandGetSet
+	cmd.Key = args[0]
+	cmd.Value = args[1]
+	return
+}
+
+func parseSetNX(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandSetNX, args, 2); err != nil {
+		return
+	}
+
+	cmd.Type = domain.CommandSetNX
+	cmd.Key = args[0]
+	cmd.Value = args[1]
+	return
+}
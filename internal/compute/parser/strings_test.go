@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_Strings(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in: "APPEND key value",
+			out: domain.Command{
+				Type:  domain.CommandAppend,
+				Key:   "key",
+				Value: "value",
+			},
+		},
+		{
+			in:  "APPEND key",
+			err: true,
+		},
+		{
+			in: "GETRANGE key 0 -1",
+			out: domain.Command{
+				Type: domain.CommandGetRange,
+				Key:  "key",
+				Args: []string{"0", "-1"},
+			},
+		},
+		{
+			in:  "GETRANGE key 0 end",
+			err: true,
+		},
+		{
+			in: "SETRANGE key 6 value",
+			out: domain.Command{
+				Type:  domain.CommandSetRange,
+				Key:   "key",
+				Value: "value",
+				Args:  []string{"6"},
+			},
+		},
+		{
+			in:  "SETRANGE key -1 value",
+			err: true,
+		},
+		{
+			in:  "SETRANGE key 9223372036854775806 ab",
+			err: true,
+		},
+		{
+			in: "STRLEN key",
+			out: domain.Command{
+				Type: domain.CommandStrLen,
+				Key:  "key",
+			},
+		},
+		{
+			in: "GETSET key value",
+			out: domain.Command{
+				Type:  domain.CommandGetSet,
+				Key:   "key",
+				Value: "value",
+			},
+		},
+		{
+			in: "SETNX lock owner",
+			out: domain.Command{
+				Type:  domain.CommandSetNX,
+				Key:   "lock",
+				Value: "owner",
+			},
+		},
+		{
+			in:  "SETNX lock",
+			err: true,
+		},
+	}
+
+	for i, c := range tt {
+		cmd, err := Parse(c.in)
+		if c.err {
+			require.Errorf(t, err, "iter %d", i)
+		} else {
+			require.NoErrorf(t, err, "iter %d", i)
+			require.Equal(t, c.out, cmd)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const withScoresFlag = "WITHSCORES"
+
+func parseZAdd(args []string) (cmd domain.Command, err error) {
+	if err = checkVarArgs(domain.CommandZAdd, args, 3); err != nil {
+		return
+	}
+	if len(args)%2 != 1 {
+		err = fmt.Errorf("odd score member arguments number for ZADD command")
+		return
+	}
+	for i := 1; i < len(args); i += 2 {
+		if !validScore(args[i]) {
+			err = fmt.Errorf("invalid score for ZADD command: %w", domain.ErrNotFloat)
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandZAdd
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	return
+}
+
+func parseZRange(args []string) (cmd domain.Command, err error) {
+	withScores, args, err := cutWithScores(domain.CommandZRange, args)
+	if err != nil {
+		return
+	}
+
+	cmd, err = parseKeyRange(domain.CommandZRange)(args)
+	if err == nil && withScores {
+		cmd.Args = append(cmd.Args, withScoresFlag)
+	}
+	return
+}
+
+func parseZRangeByScore(args []string) (cmd domain.Command, err error) {
+	withScores, args, err := cutWithScores(domain.CommandZRangeByScore, args)
+	if err != nil {
+		return
+	}
+	if err = checkArgs(domain.CommandZRangeByScore, args, 3); err != nil {
+		return
+	}
+	for _, a := range args[1:] {
+		if _, _, err = domain.ParseScoreBound(a); err != nil {
+			err = fmt.Errorf("invalid score bound for ZRANGEBYSCORE command: %w", err)
+			return
+		}
+	}
+
+	cmd.Type = domain.CommandZRangeByScore
+	cmd.Key = args[0]
+	cmd.Args = args[1:]
+	if withScores {
+		cmd.Args = append(cmd.Args, withScoresFlag)
+	}
+	return
+}
+
+func parseZIncrBy(args []string) (cmd domain.Command, err error) {
+	if err = checkArgs(domain.CommandZIncrBy, args, 3); err != nil {
+		return
+	}
+	if !validScore(args[1]) {
+		err = fmt.Errorf("invalid increment for ZINCRBY command: %w", domain.ErrNotFloat)
+		return
+	}
+
+	cmd.Type = domain.CommandZIncrBy
+	cmd.Key = args[0]
+	cmd.Value = args[1]
+	cmd.Args = args[2:]
+	return
+}
+
+// cutWithScores removes the optional trailing WITHSCORES flag.
+func cutWithScores(t domain.CommandType, args []string) (bool, []string, error) {
+	if len(args) != 4 {
+		return false, args, nil
+	}
+	if args[3] != withScoresFlag {
+		return false, nil, fmt.Errorf("unsupported option %q for %s command", args[3], t)
+	}
+	return true, args[:3], nil
+}
+
+func validScore(s string) bool {
+	f, err := strconv.ParseFloat(s, 64)
+	return err == nil && !math.IsNaN(f)
+}
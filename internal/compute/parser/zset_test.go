@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestParse_SortedSet(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in  string
+		out domain.Command
+		err bool
+	}{
+		{
+			in: "ZADD board 10 alice 20.5 bob",
+			out: domain.Command{
+				Type: domain.CommandZAdd,
+				Key:  "board",
+				Args: []string{"10", "alice", "20.5", "bob"},
+			},
+		},
+		{
+			in:  "ZADD board ten alice",
+			err: true,
+		},
+		{
+			in:  "ZADD board 10",
+			err: true,
+		},
+		{
+			in: "ZRANGE board 0 -1",
+			out: domain.Command{
+				Type: domain.CommandZRange,
+				Key:  "board",
+				Args: []string{"0", "-1"},
+			},
+		},
+		{
+			in: "ZRANGE board 0 -1 WITHSCORES",
+			out: domain.Command{
+				Type: domain.CommandZRange,
+				Key:  "board",
+				Args: []string{"0", "-1", "WITHSCORES"},
+			},
+		},
+		{
+			in:  "ZRANGE board 0 -1 WITHVALUES",
+			err: true,
+		},
+		{
+			in: "ZRANGEBYSCORE board (10 +inf WITHSCORES",
+			out: domain.Command{
+				Type: domain.CommandZRangeByScore,
+				Key:  "board",
+				Args: []string{"(10", "+inf", "WITHSCORES"},
+			},
+		},
+		{
+			in:  "ZRANGEBYSCORE board low high",
+			err: true,
+		},
+		{
+			in: "ZINCRBY board 5 alice",
+			out: domain.Command{
+				Type:  domain.CommandZIncrBy,
+				Key:   "board",
+				Value: "5",
+				Args:  []string{"alice"},
+			},
+		},
+		{
+			in: "ZRANK board alice",
+			out: domain.Command{
+				Type: domain.CommandZRank,
+				Key:  "board",
+				Args: []string{"alice"},
+			},
+		},
+	}
+
+	for i, c := range tt {
+		cmd, err := Parse(c.in)
+		if c.err {
+			require.Errorf(t, err, "iter %d", i)
+		} else {
+			require.NoErrorf(t, err, "iter %d", i)
+			require.Equal(t, c.out, cmd)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+)
+
+// DefaultFile is the config file read when -config is not given, it may be missing.
+const DefaultFile = "./config.yml"
+
+// CommandLine defines -config, -print-config and the setting flags, binaries
+// load their config through it to treat the command line the same way.
+type CommandLine struct {
+	File string
+	// Print asks to print the effective config and exit.
+	Print bool
+	flags *Flags
+}
+
+func NewCommandLine(set *flag.FlagSet) *CommandLine {
+	c := &CommandLine{}
+	set.StringVar(&c.File, "config", DefaultFile, "")
+	set.BoolVar(&c.Print, "print-config", false, "print the effective config and exit")
+	c.flags = NewFlags(set)
+	return c
+}
+
+// Load loads the config from the file, the environment and the flags like
+// Load does, the default file is skipped when it is missing. It may be called
+// again to reload the config.
+func (c *CommandLine) Load() (*Config, error) {
+	fileName := c.File
+	if fileName == DefaultFile {
+		if _, err := os.Stat(fileName); errors.Is(err, fs.ErrNotExist) {
+			fileName = ""
+		}
+	}
+	return Load(fileName, os.Environ(), c.flags)
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -13,6 +16,21 @@ import (
 const (
 	EngineTypeInMemory = "in-memory"
 	LogLevelDebug      = "debug"
+	LogLevelInfo       = "info"
+	LogLevelWarn       = "warn"
+	LogLevelError      = "error"
+	LogFormatJSON      = "json"
+	LogFormatText      = "text"
+	AuditValuesHash    = "hash"
+	AuditValuesInclude = "include"
+)
+
+const redacted = "(redacted)"
+
+// Span exporters of tracing.
+const (
+	TraceExporterOTLP   = "otlp"
+	TraceExporterStdout = "stdout"
 )
 
 type MessageSizeBytes int
@@ -27,10 +45,28 @@ func (m *MessageSizeBytes) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+// MarshalYAML writes the size with the largest unit dividing it.
+func (m MessageSizeBytes) MarshalYAML() (any, error) {
+	return formatBytes(int(m)), nil
+}
+
 func (m *MessageSizeBytes) Int() int {
 	return int(*m)
 }
 
+func formatBytes(size int) string {
+	const bytesInKB = 1024
+
+	switch {
+	case size > 0 && size%(bytesInKB*bytesInKB) == 0:
+		return strconv.Itoa(size/(bytesInKB*bytesInKB)) + "MB"
+	case size > 0 && size%bytesInKB == 0:
+		return strconv.Itoa(size/bytesInKB) + "KB"
+	default:
+		return strconv.Itoa(size) + "B"
+	}
+}
+
 func parseBytes(s string) (int, error) {
 	const bytesInKB = 1024
 
@@ -61,22 +97,201 @@ func parseBytes(s string) (int, error) {
 	return 0, fmt.Errorf("invalid size value: %q", s)
 }
 
+// FileMode is a file permission written in octal like 0660.
+type FileMode os.FileMode
+
+func (m *FileMode) UnmarshalYAML(node *yaml.Node) error {
+	mode, err := strconv.ParseUint(node.Value, 8, 32)
+	if err != nil || mode > 0o777 {
+		return fmt.Errorf("unmarshal error: invalid file mode %q", node.Value)
+	}
+
+	*m = FileMode(mode)
+	return nil
+}
+
+func (m FileMode) MarshalYAML() (any, error) {
+	return fmt.Sprintf("%#o", uint32(m)), nil
+}
+
 type Config struct {
 	Engine struct {
 		Type string `yaml:"type"`
 	} `yaml:"engine"`
 
 	Network struct {
-		Address        string           `yaml:"address"`
+		// Address is host:port, tcp://host:port or unix:///path/to.sock.
+		Address string `yaml:"address"`
+		// Addresses are listened on along with Address.
+		Addresses []string `yaml:"addresses,omitempty"`
+		// SocketMode is the permission of unix socket files.
+		SocketMode     FileMode         `yaml:"socket_mode"`
 		MaxConnections uint             `yaml:"max_connections"`
 		MaxMessageSize MessageSizeBytes `yaml:"max_message_size"`
 		IdleTimeout    time.Duration    `yaml:"idle_timeout"`
 	} `yaml:"network"`
 
+	// HTTP is an optional JSON gateway to the storage, network limits apply to it.
+	HTTP struct {
+		Enabled bool   `yaml:"enabled"`
+		Address string `yaml:"address"`
+	} `yaml:"http"`
+
+	// GRPC is an optional gRPC API of the storage.
+	GRPC struct {
+		Enabled bool   `yaml:"enabled"`
+		Address string `yaml:"address"`
+	} `yaml:"grpc"`
+
+	// Admin serves /healthz, /readyz, /debug/pprof and /config over HTTP. It
+	// has no authentication, so keep it on a private address.
+	Admin struct {
+		Enabled bool   `yaml:"enabled"`
+		Address string `yaml:"address"`
+	} `yaml:"admin"`
+
+	// Auth lists users sessions identify themselves as with AUTH. Once users
+	// are configured, admin commands like CLUSTER SETSLOT, RAFT ADD or CLIENT
+	// KILL require AUTH, other commands do not.
+	Auth struct {
+		Users []User `yaml:"users,omitempty"`
+	} `yaml:"auth"`
+
+	// RateLimit applies token buckets to text protocol sessions, a zero rate is
+	// unlimited. Per user limits apply to sessions after AUTH.
+	RateLimit struct {
+		PerIP   Rate `yaml:"per_ip"`
+		PerUser Rate `yaml:"per_user"`
+		// Throttle delays commands over the limit instead of rejecting them.
+		Throttle bool `yaml:"throttle"`
+	} `yaml:"rate_limit"`
+
+	// SlowLog keeps the last MaxLen commands executed longer than Threshold,
+	// zero threshold disables it.
+	SlowLog struct {
+		Threshold time.Duration `yaml:"threshold"`
+		MaxLen    int           `yaml:"max_len"`
+	} `yaml:"slow_log"`
+
+	// Audit records mutating and admin commands along with the client, the
+	// user and keys to an append-only file, apart from the log.
+	Audit struct {
+		Enabled bool   `yaml:"enabled"`
+		Output  string `yaml:"output"`
+		// Values is hash to record HMAC-SHA256 of values keyed by HashKey or
+		// include to record them as is.
+		Values string `yaml:"values"`
+		// HashKey is the secret key of value hashes, without it values
+		// of a small set could be found by hashing every candidate.
+		HashKey string `yaml:"hash_key"`
+	} `yaml:"audit"`
+
+	// Tracing exports OpenTelemetry spans of sessions, parsing and commands.
+	// Clients propagate their trace context with the traceparent header.
+	Tracing struct {
+		Enabled bool `yaml:"enabled"`
+		// Exporter is otlp to send spans to Endpoint over gRPC or stdout.
+		Exporter string `yaml:"exporter"`
+		Endpoint string `yaml:"endpoint"`
+		// Insecure disables TLS to the endpoint, like a local collector.
+		Insecure    bool   `yaml:"insecure"`
+		ServiceName string `yaml:"service_name"`
+		// SampleRatio is the share of traces started by the server to record,
+		// traces propagated by clients keep their sampling decision.
+		SampleRatio float64 `yaml:"sample_ratio"`
+	} `yaml:"tracing"`
+
 	Logging struct {
-		Level  string `yaml:"level"`
+		Level string `yaml:"level"`
+		// Output is the log file, records go to stdout when it is empty.
 		Output string `yaml:"output"`
+		// Format is json or text.
+		Format string `yaml:"format"`
+		// Stderr copies records to stderr as well.
+		Stderr bool `yaml:"stderr"`
+		// Rotation renames the output file to a timestamped backup once it
+		// grows over MaxSize or gets older than MaxAge, keeping MaxBackups of
+		// them. Zero values disable the limits.
+		Rotation struct {
+			MaxSize    MessageSizeBytes `yaml:"max_size,omitempty"`
+			MaxAge     time.Duration    `yaml:"max_age"`
+			MaxBackups int              `yaml:"max_backups"`
+			// Compress gzips backups.
+			Compress bool `yaml:"compress"`
+		} `yaml:"rotation"`
 	} `yaml:"logging"`
+
+	Raft struct {
+		Enabled bool   `yaml:"enabled"`
+		NodeID  string `yaml:"node_id"`
+		// Address is where the node accepts raft RPCs from other members,
+		// member addresses have the same form as network.address.
+		Address           string        `yaml:"address"`
+		Members           []RaftMember  `yaml:"members,omitempty"`
+		ElectionTimeout   time.Duration `yaml:"election_timeout"`
+		HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+		SnapshotThreshold uint64        `yaml:"snapshot_threshold"`
+		// DataDir keeps the term, the vote, the log and the snapshot of the
+		// node, a member must not forget them across restarts.
+		DataDir string `yaml:"data_dir"`
+	} `yaml:"raft"`
+
+	Cluster struct {
+		Enabled bool          `yaml:"enabled"`
+		NodeID  string        `yaml:"node_id"`
+		Nodes   []ClusterNode `yaml:"nodes,omitempty"`
+		// StateDir keeps slot assignments and the migration journal across
+		// restarts, nothing is kept when empty.
+		StateDir           string `yaml:"state_dir"`
+		MigrationBatchSize int    `yaml:"migration_batch_size"`
+	} `yaml:"cluster"`
+
+	// Proxy is used by cmd/proxy only, network limits apply to proxy sessions.
+	Proxy struct {
+		Address  string   `yaml:"address"`
+		Backends []string `yaml:"backends,omitempty"`
+		// VirtualNodes is the number of hash ring points per backend.
+		VirtualNodes int `yaml:"virtual_nodes"`
+		// MaxIdleConns limits idle pooled connections per backend, IdleConnTimeout
+		// must be lower than the backend idle timeout.
+		MaxIdleConns    int           `yaml:"max_idle_conns"`
+		IdleConnTimeout time.Duration `yaml:"idle_conn_timeout"`
+		DialTimeout     time.Duration `yaml:"dial_timeout"`
+		// BackendTimeout limits a backend reply to a non-blocking command.
+		BackendTimeout      time.Duration `yaml:"backend_timeout"`
+		HealthCheckInterval time.Duration `yaml:"health_check_interval"`
+		// FailureThreshold is the number of failed checks ejecting a backend.
+		FailureThreshold int `yaml:"failure_threshold"`
+	} `yaml:"proxy"`
+}
+
+type User struct {
+	Name     string `yaml:"name"`
+	Password string `yaml:"password"`
+}
+
+// Rate is a per second limit, the bucket holds a second worth of tokens.
+type Rate struct {
+	Commands float64          `yaml:"commands"`
+	Bytes    MessageSizeBytes `yaml:"bytes,omitempty"`
+}
+
+// Limited reports whether any of the limits is set.
+func (r Rate) Limited() bool {
+	return r.Commands > 0 || r.Bytes > 0
+}
+
+type ClusterNode struct {
+	ID      string `yaml:"id"`
+	Address string `yaml:"address"`
+	// Slots lists single slots "100" and inclusive ranges "0-5460".
+	Slots []string `yaml:"slots"`
+}
+
+type RaftMember struct {
+	ID            string `yaml:"id"`
+	Address       string `yaml:"address"`
+	ClientAddress string `yaml:"client_address"`
 }
 
 func NewConfigWithDefaults() *Config {
@@ -86,21 +301,110 @@ func NewConfigWithDefaults() *Config {
 	cfg.Network.MaxConnections = 20
 	cfg.Network.IdleTimeout = time.Minute
 	cfg.Network.MaxMessageSize = 1024
+	cfg.Network.SocketMode = 0o660
+	cfg.HTTP.Address = "127.0.0.1:8080"
+	cfg.GRPC.Address = "127.0.0.1:3225"
+	cfg.Admin.Address = "127.0.0.1:6060"
+	cfg.SlowLog.Threshold = 10 * time.Millisecond
+	cfg.SlowLog.MaxLen = 128
 	cfg.Logging.Output = "./output.log"
+	cfg.Audit.Output = "./audit.log"
+	cfg.Audit.Values = AuditValuesHash
+	cfg.Tracing.Exporter = TraceExporterOTLP
+	cfg.Tracing.Endpoint = "127.0.0.1:4317"
+	cfg.Tracing.Insecure = true
+	cfg.Tracing.ServiceName = "key-value-storage"
+	cfg.Tracing.SampleRatio = 1
 	cfg.Logging.Level = LogLevelDebug
+	cfg.Logging.Format = LogFormatJSON
+	cfg.Logging.Rotation.MaxSize = 100 * 1024 * 1024
+	cfg.Logging.Rotation.MaxBackups = 10
+	cfg.Raft.ElectionTimeout = 500 * time.Millisecond
+	cfg.Raft.HeartbeatInterval = 100 * time.Millisecond
+	cfg.Raft.SnapshotThreshold = 10000
+	cfg.Raft.DataDir = "./raft"
+	cfg.Cluster.MigrationBatchSize = 100
+	cfg.Proxy.Address = "127.0.0.1:3224"
+	cfg.Proxy.VirtualNodes = 128
+	cfg.Proxy.MaxIdleConns = 8
+	cfg.Proxy.IdleConnTimeout = 30 * time.Second
+	cfg.Proxy.DialTimeout = time.Second
+	cfg.Proxy.BackendTimeout = 5 * time.Second
+	cfg.Proxy.HealthCheckInterval = 2 * time.Second
+	cfg.Proxy.FailureThreshold = 3
 	return cfg
 }
 
+// Redacted returns a copy of the config with passwords and keys hidden.
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.Auth.Users = nil
+	for _, u := range c.Auth.Users {
+		r.Auth.Users = append(r.Auth.Users, User{Name: u.Name, Password: redacted})
+	}
+	if r.Audit.HashKey != "" {
+		r.Audit.HashKey = redacted
+	}
+	return &r
+}
+
+// ListenAddresses returns all addresses the server listens on.
+func (c *Config) ListenAddresses() []string {
+	return append([]string{c.Network.Address}, c.Network.Addresses...)
+}
+
+// Marshal returns the config as YAML FillWithFile accepts.
+func (c *Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FillWithFile overrides the config with settings of the YAML file, unknown
+// settings are rejected.
 func FillWithFile(cfg *Config, fileName string) error {
 	data, err := os.ReadFile(fileName)
 	if err != nil {
 		return fmt.Errorf("read file: %w", err)
 	}
 
-	err = yaml.Unmarshal(data, cfg)
-	if err != nil {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
 		return fmt.Errorf("unmarshal data: %w", err)
 	}
 
 	return nil
 }
+
+// Load returns defaults overridden by the file, KVS_* variables of the
+// environment and the flags, in that order, and validates the result. The
+// file is skipped when the name is empty, flags may be nil.
+func Load(fileName string, environ []string, flags *Flags) (*Config, error) {
+	cfg := NewConfigWithDefaults()
+	if fileName != "" {
+		if err := FillWithFile(cfg, fileName); err != nil {
+			return nil, fmt.Errorf("fill with file: %w", err)
+		}
+	}
+	if err := FillWithEnv(cfg, environ); err != nil {
+		return nil, fmt.Errorf("fill with env: %w", err)
+	}
+	if flags != nil {
+		if err := flags.Fill(cfg); err != nil {
+			return nil, fmt.Errorf("fill with flags: %w", err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	return cfg, nil
+}
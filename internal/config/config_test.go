@@ -1,6 +1,9 @@
 package config
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -82,6 +85,201 @@ func Test_Config(t *testing.T) {
 		require.Equal(t, "info", cfg.Logging.Level)
 		require.Equal(t, 4*1024, cfg.Network.MaxMessageSize.Int())
 		require.Equal(t, time.Minute*5, cfg.Network.IdleTimeout)
+		require.Equal(t, FileMode(0o640), cfg.Network.SocketMode)
+		require.Equal(t, []string{"127.0.0.1:3223", "unix:///run/kvs.sock"}, cfg.ListenAddresses())
+		require.Equal(t, []User{{Name: "alice", Password: "secret"}}, cfg.Auth.Users)
+		require.Equal(t, Rate{Commands: 100, Bytes: 64 * 1024}, cfg.RateLimit.PerIP)
+		require.False(t, cfg.RateLimit.PerUser.Limited())
+		require.True(t, cfg.RateLimit.Throttle)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, NewConfigWithDefaults().Validate())
+
+		cfg := NewConfigWithDefaults()
+		cfg.Network.MaxConnections = 0
+		cfg.Logging.Level = "trace"
+		cfg.Auth.Users = []User{{Name: "alice"}, {Name: "alice"}}
+		cfg.Audit.Enabled = true
+		err := cfg.Validate()
+		require.ErrorContains(t, err, "network.max_connections")
+		require.ErrorContains(t, err, "logging.level")
+		require.ErrorContains(t, err, "auth.users")
+		require.ErrorContains(t, err, "audit.hash_key")
+
+		cfg = NewConfigWithDefaults()
+		cfg.Engine.Type = "on-disk"
+		cfg.Network.Addresses = []string{"unix://", "localhost"}
+		cfg.GRPC.Enabled = true
+		cfg.GRPC.Address = "3225"
+		err = cfg.Validate()
+		require.ErrorContains(t, err, "engine.type")
+		require.ErrorContains(t, err, `invalid network address "unix://"`)
+		require.ErrorContains(t, err, `invalid network address "localhost"`)
+		require.ErrorContains(t, err, "grpc.address")
+
+		cfg = NewConfigWithDefaults()
+		cfg.Raft.Enabled = true
+		cfg.Raft.NodeID = "n1"
+		cfg.Raft.Address = "unix:///run/kvs-raft.sock"
+		cfg.Raft.Members = []RaftMember{{ID: "n2", Address: "tcp://127.0.0.1:4002"}, {ID: "n3", Address: "127.0.0.1"}}
+		err = cfg.Validate()
+		require.ErrorContains(t, err, `invalid raft member address "127.0.0.1"`)
+		require.NotContains(t, err.Error(), "raft.address")
+		require.NotContains(t, err.Error(), "4002")
+	})
+
+	t.Run("reject unknown settings in file", func(t *testing.T) {
+		t.Parallel()
+
+		fileName := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(fileName, []byte("network:\n  max_conections: 10\n"), 0o600))
+		require.ErrorContains(t, FillWithFile(NewConfigWithDefaults(), fileName), "max_conections")
+
+		require.NoError(t, os.WriteFile(fileName, nil, 0o600))
+		require.NoError(t, FillWithFile(NewConfigWithDefaults(), fileName))
+	})
+
+	t.Run("fill with env", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := NewConfigWithDefaults()
+		require.NoError(t, FillWithEnv(cfg, []string{
+			"HOME=/root",
+			"KVS_NETWORK_MAX_CONNECTIONS=5",
+			"KVS_NETWORK_MAX_MESSAGE_SIZE=2KB",
+			"KVS_NETWORK_ADDRESSES=[unix:///run/kvs.sock]",
+			"KVS_RATE_LIMIT_PER_IP_COMMANDS=10.5",
+			"KVS_AUTH_USERS=[{name: alice, password: secret}]",
+			"KVS_LOGGING_OUTPUT=",
+		}))
+		require.Equal(t, uint(5), cfg.Network.MaxConnections)
+		require.Equal(t, 2048, cfg.Network.MaxMessageSize.Int())
+		require.Equal(t, []string{"unix:///run/kvs.sock"}, cfg.Network.Addresses)
+		require.Equal(t, 10.5, cfg.RateLimit.PerIP.Commands)
+		require.Equal(t, []User{{Name: "alice", Password: "secret"}}, cfg.Auth.Users)
+		require.Empty(t, cfg.Logging.Output)
+
+		require.ErrorContains(t, FillWithEnv(cfg, []string{"KVS_NETWORK_MAX_CONECTIONS=5"}), "unknown variable")
+		require.ErrorContains(t, FillWithEnv(cfg, []string{"KVS_NETWORK_IDLE_TIMEOUT=soon"}), "network.idle_timeout")
+	})
+
+	t.Run("load with precedence", func(t *testing.T) {
+		t.Parallel()
+
+		fileName := filepath.Join(t.TempDir(), "config.yml")
+		data := []byte("network:\n  max_connections: 10\n  idle_timeout: 5m\nlogging:\n  level: info\n")
+		require.NoError(t, os.WriteFile(fileName, data, 0o600))
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags := NewFlags(fs)
+		require.NoError(t, fs.Parse([]string{"-network.max_connections=30", "--logging.level", "warn"}))
+		require.Error(t, fs.Parse([]string{"-network.max_message_size=1"}))
+
+		env := []string{"KVS_NETWORK_MAX_CONNECTIONS=20", "KVS_NETWORK_IDLE_TIMEOUT=10s"}
+		cfg, err := Load(fileName, env, flags)
+		require.NoError(t, err)
+		require.Equal(t, uint(30), cfg.Network.MaxConnections)
+		require.Equal(t, 10*time.Second, cfg.Network.IdleTimeout)
+		require.Equal(t, LogLevelWarn, cfg.Logging.Level)
+		require.Equal(t, "127.0.0.1:3223", cfg.Network.Address)
+
+		_, err = Load(fileName, []string{"KVS_NETWORK_MAX_CONNECTIONS=0"}, nil)
+		require.ErrorContains(t, err, "network.max_connections")
+	})
+
+	t.Run("load from command line", func(t *testing.T) {
+		t.Parallel()
+
+		fileName := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(fileName, []byte("network:\n  max_connections: 10\n"), 0o600))
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		cmdline := NewCommandLine(fs)
+		require.NoError(t, fs.Parse([]string{"-config", fileName, "-print-config", "-logging.level=warn"}))
+		require.True(t, cmdline.Print)
+		cfg, err := cmdline.Load()
+		require.NoError(t, err)
+		require.Equal(t, uint(10), cfg.Network.MaxConnections)
+		require.Equal(t, LogLevelWarn, cfg.Logging.Level)
+
+		// the default file may be missing, another one may not
+		fs = flag.NewFlagSet("test", flag.ContinueOnError)
+		cmdline = NewCommandLine(fs)
+		require.NoError(t, fs.Parse(nil))
+		require.Equal(t, DefaultFile, cmdline.File)
+		_, err = cmdline.Load()
+		require.NoError(t, err)
+
+		cmdline.File = filepath.Join(t.TempDir(), "missing.yml")
+		_, err = cmdline.Load()
+		require.ErrorContains(t, err, "read file")
+	})
+
+	t.Run("marshal to loadable yaml", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := NewConfigWithDefaults()
+		cfg.Network.MaxMessageSize = 2 * 1024 * 1024
+		cfg.RateLimit.PerIP = Rate{Commands: 5, Bytes: 1500}
+		data, err := cfg.Marshal()
+		require.NoError(t, err)
+
+		fileName := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(fileName, data, 0o600))
+		loaded := NewConfigWithDefaults()
+		require.NoError(t, FillWithFile(loaded, fileName))
+		require.Equal(t, cfg, loaded)
+	})
+
+	t.Run("redact passwords", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := NewConfigWithDefaults()
+		cfg.Auth.Users = []User{{Name: "alice", Password: "secret"}}
+		cfg.Audit.HashKey = "key"
+		r := cfg.Redacted()
+		require.Equal(t, []User{{Name: "alice", Password: "(redacted)"}}, r.Auth.Users)
+		require.Equal(t, "(redacted)", r.Audit.HashKey)
+		require.Equal(t, "secret", cfg.Auth.Users[0].Password)
+		require.Equal(t, "key", cfg.Audit.HashKey)
+		require.Nil(t, NewConfigWithDefaults().Redacted().Auth.Users)
+		require.Empty(t, NewConfigWithDefaults().Redacted().Audit.HashKey)
+	})
+
+	t.Run("name env variables uniquely", func(t *testing.T) {
+		t.Parallel()
+
+		names := make(map[string]struct{})
+		for _, s := range settings(NewConfigWithDefaults()) {
+			require.NotContains(t, names, s.envName())
+			names[s.envName()] = struct{}{}
+		}
+	})
+
+	t.Run("list settings requiring restart", func(t *testing.T) {
+		t.Parallel()
+
+		current := NewConfigWithDefaults()
+		next := NewConfigWithDefaults()
+		next.Network.IdleTimeout = time.Hour
+		next.Logging.Level = LogLevelError
+		next.RateLimit.Throttle = true
+		require.Empty(t, RestartRequired(current, next))
+
+		next.Network.Address = "127.0.0.1:4000"
+		next.GRPC.Enabled = true
+		require.Equal(t, []string{"network.address", "grpc"}, RestartRequired(current, next))
+	})
+
+	t.Run("reject invalid socket mode", func(t *testing.T) {
+		t.Parallel()
+
+		var m FileMode
+		require.Error(t, yaml.Unmarshal([]byte(`"0999"`), &m))
+		require.Error(t, yaml.Unmarshal([]byte(`"01777"`), &m))
 	})
 }
 
@@ -93,6 +291,17 @@ network:
   max_connections: 100
   max_message_size: "4KB"
   idle_timeout: 5m
+  addresses: ["unix:///run/kvs.sock"]
+  socket_mode: "0640"
+auth:
+  users:
+    - name: "alice"
+      password: "secret"
+rate_limit:
+  per_ip:
+    commands: 100
+    bytes: "64KB"
+  throttle: true
 logging:
   level: "info"
   output: "/log/output.log"
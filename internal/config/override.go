@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "KVS_"
+
+// setting is a config field named by its YAML path like network.max_connections.
+type setting struct {
+	path  string
+	value reflect.Value
+}
+
+// envName returns the environment variable of the setting like
+// KVS_NETWORK_MAX_CONNECTIONS.
+func (s setting) envName() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.path, ".", "_"))
+}
+
+// set parses the value as YAML, so lists are written like [a, b] and lists of
+// users or nodes like [{name: alice, password: secret}]. A string is taken as
+// is, an empty value resets the setting.
+func (s setting) set(raw string) error {
+	if s.value.Kind() == reflect.String {
+		s.value.SetString(raw)
+		return nil
+	}
+
+	v := reflect.New(s.value.Type())
+	dec := yaml.NewDecoder(strings.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(v.Interface()); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid %s %q: %w", s.path, raw, err)
+	}
+	s.value.Set(v.Elem())
+	return nil
+}
+
+// settings lists all settings of the config, nested sections are walked
+// down to their fields.
+func settings(cfg *Config) []setting {
+	var out []setting
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if v.Field(i).Kind() == reflect.Struct {
+				walk(prefix+name+".", v.Field(i))
+				continue
+			}
+			out = append(out, setting{path: prefix + name, value: v.Field(i)})
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return out
+}
+
+// FillWithEnv overrides the config with KVS_* variables of the environment
+// given as key=value pairs, unknown KVS_* variables are rejected.
+func FillWithEnv(cfg *Config, environ []string) error {
+	byName := make(map[string]setting)
+	for _, s := range settings(cfg) {
+		byName[s.envName()] = s
+	}
+
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		s, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown variable %s", name)
+		}
+		if err := s.set(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flags are command line flags for every setting named by its path like
+// -network.max_connections, values are written as in FillWithEnv.
+type Flags struct {
+	paths  []string
+	values map[string]string
+}
+
+// NewFlags defines flags of all settings in the flag set.
+func NewFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{values: make(map[string]string)}
+	for _, s := range settings(NewConfigWithDefaults()) {
+		usage := fmt.Sprintf("overrides %s, also set by %s", s.path, s.envName())
+		fs.Func(s.path, usage, func(value string) error {
+			// parse into a scratch config to report a bad value right away
+			if err := settingOf(NewConfigWithDefaults(), s.path).set(value); err != nil {
+				return err
+			}
+			if _, ok := f.values[s.path]; !ok {
+				f.paths = append(f.paths, s.path)
+			}
+			f.values[s.path] = value
+			return nil
+		})
+	}
+	return f
+}
+
+// Fill overrides the config with settings given on the command line.
+func (f *Flags) Fill(cfg *Config) error {
+	for _, path := range f.paths {
+		if err := settingOf(cfg, path).set(f.values[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func settingOf(cfg *Config, path string) setting {
+	for _, s := range settings(cfg) {
+		if s.path == path {
+			return s
+		}
+	}
+	panic("unknown setting " + path)
+}
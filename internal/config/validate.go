@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+
+	"github.com/tmvrus/key-value-storage/internal/netaddr"
+)
+
+// Validate reports settings the server cannot run with.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	checkAddress := func(name, address string, valid func(string) error) {
+		if err := valid(address); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", name, address, err))
+		}
+	}
+
+	check(slices.Contains([]string{EngineTypeInMemory}, c.Engine.Type), "unknown engine.type %q", c.Engine.Type)
+
+	for _, address := range c.ListenAddresses() {
+		checkAddress("network address", address, validTextAddress)
+	}
+	check(c.Network.MaxConnections > 0, "network.max_connections must be positive")
+	check(c.Network.MaxMessageSize > 0, "network.max_message_size must be positive")
+	check(c.Network.IdleTimeout > 0, "network.idle_timeout must be positive")
+	check(slices.Contains([]string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}, c.Logging.Level),
+		"unknown logging.level %q", c.Logging.Level)
+	check(slices.Contains([]string{LogFormatJSON, LogFormatText}, c.Logging.Format),
+		"unknown logging.format %q", c.Logging.Format)
+	check(c.Logging.Rotation.MaxSize >= 0 && c.Logging.Rotation.MaxAge >= 0 && c.Logging.Rotation.MaxBackups >= 0,
+		"logging.rotation must not be negative")
+	if c.HTTP.Enabled {
+		checkAddress("http.address", c.HTTP.Address, validHostPort)
+	}
+	if c.GRPC.Enabled {
+		checkAddress("grpc.address", c.GRPC.Address, validHostPort)
+	}
+	if c.Admin.Enabled {
+		checkAddress("admin.address", c.Admin.Address, validHostPort)
+	}
+	if c.Audit.Enabled {
+		check(c.Audit.Output != "", "audit.output is empty")
+		check(c.Audit.Values != AuditValuesHash || c.Audit.HashKey != "", "audit.hash_key is empty")
+	}
+	check(slices.Contains([]string{AuditValuesHash, AuditValuesInclude}, c.Audit.Values),
+		"unknown audit.values %q", c.Audit.Values)
+	if c.Tracing.Enabled {
+		check(slices.Contains([]string{TraceExporterOTLP, TraceExporterStdout}, c.Tracing.Exporter),
+			"unknown tracing.exporter %q", c.Tracing.Exporter)
+		if c.Tracing.Exporter == TraceExporterOTLP {
+			checkAddress("tracing.endpoint", c.Tracing.Endpoint, validHostPort)
+		}
+		check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be within [0, 1]")
+	}
+	check(c.SlowLog.Threshold >= 0 && c.SlowLog.MaxLen >= 0, "slow_log must not be negative")
+	check(c.RateLimit.PerIP.Commands >= 0 && c.RateLimit.PerIP.Bytes >= 0, "rate_limit.per_ip must not be negative")
+	check(c.RateLimit.PerUser.Commands >= 0 && c.RateLimit.PerUser.Bytes >= 0, "rate_limit.per_user must not be negative")
+
+	names := make(map[string]struct{}, len(c.Auth.Users))
+	for _, u := range c.Auth.Users {
+		_, dup := names[u.Name]
+		check(u.Name != "" && !dup, "auth.users has an empty or duplicate name %q", u.Name)
+		names[u.Name] = struct{}{}
+	}
+
+	if c.Raft.Enabled {
+		check(c.Raft.NodeID != "", "raft.node_id is empty")
+		checkAddress("raft.address", c.Raft.Address, validTextAddress)
+		check(c.Raft.DataDir != "", "raft.data_dir is empty")
+		for _, m := range c.Raft.Members {
+			checkAddress("raft member address", m.Address, validTextAddress)
+		}
+		check(c.Raft.HeartbeatInterval > 0 && c.Raft.ElectionTimeout > c.Raft.HeartbeatInterval,
+			"raft.election_timeout must be greater than positive raft.heartbeat_interval")
+	}
+
+	if c.Cluster.Enabled {
+		check(c.Cluster.NodeID != "", "cluster.node_id is empty")
+		for _, n := range c.Cluster.Nodes {
+			checkAddress("cluster node address", n.Address, validTextAddress)
+		}
+		check(c.Cluster.MigrationBatchSize > 0, "cluster.migration_batch_size must be positive")
+	}
+
+	checkAddress("proxy.address", c.Proxy.Address, validHostPort)
+	for _, b := range c.Proxy.Backends {
+		checkAddress("proxy backend", b, validTextAddress)
+	}
+	check(c.Proxy.VirtualNodes > 0, "proxy.virtual_nodes must be positive")
+	check(c.Proxy.FailureThreshold > 0, "proxy.failure_threshold must be positive")
+
+	return errors.Join(errs...)
+}
+
+// validTextAddress checks an address of the text protocol, see netaddr.
+func validTextAddress(address string) error {
+	network, addr, err := netaddr.Parse(address)
+	if err != nil || network != "tcp" {
+		return err
+	}
+	return validHostPort(addr)
+}
+
+func validHostPort(address string) error {
+	_, _, err := net.SplitHostPort(address)
+	return err
+}
+
+// RestartRequired lists settings changed in the next config the server
+// cannot apply while running.
+func RestartRequired(current, next *Config) []string {
+	settings := []struct {
+		name          string
+		current, next any
+	}{
+		{"engine", current.Engine, next.Engine},
+		{"network.address", current.Network.Address, next.Network.Address},
+		{"network.addresses", current.Network.Addresses, next.Network.Addresses},
+		{"network.socket_mode", current.Network.SocketMode, next.Network.SocketMode},
+		{"http", current.HTTP, next.HTTP},
+		{"grpc", current.GRPC, next.GRPC},
+		{"admin", current.Admin, next.Admin},
+		{"auth", current.Auth, next.Auth},
+		{"slow_log", current.SlowLog, next.SlowLog},
+		{"audit", current.Audit, next.Audit},
+		{"tracing", current.Tracing, next.Tracing},
+		{"logging.output", current.Logging.Output, next.Logging.Output},
+		{"logging.format", current.Logging.Format, next.Logging.Format},
+		{"logging.stderr", current.Logging.Stderr, next.Logging.Stderr},
+		{"logging.rotation", current.Logging.Rotation, next.Logging.Rotation},
+		{"raft", current.Raft, next.Raft},
+		{"cluster", current.Cluster, next.Cluster},
+	}
+
+	var changed []string
+	for _, s := range settings {
+		if !reflect.DeepEqual(s.current, s.next) {
+			changed = append(changed, s.name)
+		}
+	}
+	return changed
+}
@@ -1,19 +1,300 @@
 package domain
 
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
 type CommandType string
 
 const (
 	CommandGet    CommandType = "GET"
 	CommandSet    CommandType = "SET"
 	CommandDelete CommandType = "DELETE"
+
+	CommandIncr        CommandType = "INCR"
+	CommandDecr        CommandType = "DECR"
+	CommandIncrBy      CommandType = "INCRBY"
+	CommandIncrByFloat CommandType = "INCRBYFLOAT"
+
+	CommandAppend   CommandType = "APPEND"
+	CommandGetRange CommandType = "GETRANGE"
+	CommandSetRange CommandType = "SETRANGE"
+	CommandStrLen   CommandType = "STRLEN"
+	CommandGetSet   CommandType = "GETSET"
+	CommandSetNX    CommandType = "SETNX"
+
+	CommandMGet    CommandType = "MGET"
+	CommandMSet    CommandType = "MSET"
+	CommandMDelete CommandType = "MDELETE"
+
+	CommandHSet    CommandType = "HSET"
+	CommandHGet    CommandType = "HGET"
+	CommandHDel    CommandType = "HDEL"
+	CommandHGetAll CommandType = "HGETALL"
+	CommandHKeys   CommandType = "HKEYS"
+	CommandHVals   CommandType = "HVALS"
+	CommandHLen    CommandType = "HLEN"
+	CommandHIncrBy CommandType = "HINCRBY"
+
+	CommandLPush  CommandType = "LPUSH"
+	CommandRPush  CommandType = "RPUSH"
+	CommandLPop   CommandType = "LPOP"
+	CommandRPop   CommandType = "RPOP"
+	CommandLRange CommandType = "LRANGE"
+	CommandLLen   CommandType = "LLEN"
+	CommandLTrim  CommandType = "LTRIM"
+	CommandBLPop  CommandType = "BLPOP"
+	CommandBRPop  CommandType = "BRPOP"
+
+	CommandSAdd      CommandType = "SADD"
+	CommandSRem      CommandType = "SREM"
+	CommandSMembers  CommandType = "SMEMBERS"
+	CommandSIsMember CommandType = "SISMEMBER"
+	CommandSInter    CommandType = "SINTER"
+	CommandSUnion    CommandType = "SUNION"
+	CommandSDiff     CommandType = "SDIFF"
+	CommandSCard     CommandType = "SCARD"
+
+	CommandZAdd          CommandType = "ZADD"
+	CommandZRem          CommandType = "ZREM"
+	CommandZScore        CommandType = "ZSCORE"
+	CommandZRank         CommandType = "ZRANK"
+	CommandZRange        CommandType = "ZRANGE"
+	CommandZRangeByScore CommandType = "ZRANGEBYSCORE"
+	CommandZIncrBy       CommandType = "ZINCRBY"
+
+	CommandXAdd       CommandType = "XADD"
+	CommandXRange     CommandType = "XRANGE"
+	CommandXRead      CommandType = "XREAD"
+	CommandXGroup     CommandType = "XGROUP"
+	CommandXReadGroup CommandType = "XREADGROUP"
+	CommandXAck       CommandType = "XACK"
+	CommandXPending   CommandType = "XPENDING"
+
+	CommandRaft CommandType = "RAFT"
+
+	CommandCluster CommandType = "CLUSTER"
+	CommandAsking  CommandType = "ASKING"
+	CommandRestore CommandType = "RESTORE"
+
+	CommandAuth   CommandType = "AUTH"
+	CommandClient CommandType = "CLIENT"
+
+	CommandSlowLog CommandType = "SLOWLOG"
+	CommandMonitor CommandType = "MONITOR"
+
+	CommandPing    CommandType = "PING"
+	CommandEcho    CommandType = "ECHO"
+	CommandQuit    CommandType = "QUIT"
+	CommandHelp    CommandType = "HELP"
+	CommandCommand CommandType = "COMMAND"
 )
 
-func (t CommandType) Valid() bool {
-	return t == CommandGet || t == CommandSet || t == CommandDelete
-}
+// RAFT subcommands.
+const (
+	RaftStatus = "STATUS"
+	RaftAdd    = "ADD"
+	RaftRemove = "REMOVE"
+)
+
+// CLUSTER subcommands.
+const (
+	ClusterSlots   = "SLOTS"
+	ClusterKeySlot = "KEYSLOT"
+	ClusterSetSlot = "SETSLOT"
+	ClusterMigrate = "MIGRATE"
+)
+
+// CLUSTER SETSLOT states.
+const (
+	SlotImporting = "IMPORTING"
+	SlotMigrating = "MIGRATING"
+	SlotNode      = "NODE"
+	SlotStable    = "STABLE"
+)
+
+// CLUSTER MIGRATE subcommands besides the slot range.
+const (
+	MigrateStatus = "STATUS"
+	MigrateResume = "RESUME"
+)
+
+// CLIENT subcommands.
+const (
+	ClientList    = "LIST"
+	ClientKill    = "KILL"
+	ClientSetName = "SETNAME"
+	ClientGetName = "GETNAME"
+)
+
+// CLIENT KILL filters.
+const (
+	ClientKillID   = "ID"
+	ClientKillAddr = "ADDR"
+)
+
+// SLOWLOG subcommands.
+const (
+	SlowLogGet   = "GET"
+	SlowLogLen   = "LEN"
+	SlowLogReset = "RESET"
+)
+
+// MaxStringSize limits the length of a string value APPEND and SETRANGE may produce.
+const MaxStringSize = 512 * 1024 * 1024
 
 type Command struct {
 	Type  CommandType
 	Key   string
 	Value string
+	// Args holds command specific arguments besides the key and the value.
+	Args []string
+}
+
+// Blocking reports whether the command may wait for data to arrive.
+func (c Command) Blocking() bool {
+	switch c.Type {
+	case CommandBLPop, CommandBRPop:
+		return true
+	case CommandXRead:
+		return c.Args[1] != "-1"
+	case CommandXReadGroup:
+		return c.Args[3] != "-1"
+	default:
+		return false
+	}
+}
+
+// Admin reports whether the command changes the state of the server rather
+// than the stored data, AUTH included.
+func (c Command) Admin() bool {
+	switch c.Type {
+	case CommandAuth:
+		return true
+	case CommandRaft:
+		return c.Args[0] != RaftStatus
+	case CommandCluster:
+		return c.Args[0] == ClusterSetSlot || c.Args[0] == ClusterMigrate && c.Args[1] != MigrateStatus
+	case CommandClient:
+		return c.Args[0] == ClientKill
+	case CommandSlowLog:
+		return c.Args[0] == SlowLogReset
+	default:
+		return false
+	}
+}
+
+// MultiLineReply reports whether the command replies with the number of lines
+// followed by the lines themselves.
+func (c Command) MultiLineReply() bool {
+	switch c.Type {
+	case CommandMGet, CommandMDelete,
+		CommandHGetAll, CommandHKeys, CommandHVals,
+		CommandLRange, CommandBLPop, CommandBRPop,
+		CommandSMembers, CommandSInter, CommandSUnion, CommandSDiff,
+		CommandZRange, CommandZRangeByScore,
+		CommandXRange, CommandXRead, CommandXReadGroup, CommandXPending,
+		CommandHelp, CommandCommand:
+		return true
+	case CommandRaft:
+		return c.Args[0] == RaftStatus
+	case CommandCluster:
+		return c.Args[0] == ClusterSlots || c.Args[0] == ClusterMigrate && c.Args[1] == MigrateStatus
+	case CommandClient:
+		return c.Args[0] == ClientList
+	case CommandSlowLog:
+		return c.Args[0] == SlowLogGet
+	default:
+		return false
+	}
+}
+
+// Keys returns all keys the command accesses.
+func (c Command) Keys() []string {
+	switch c.Type {
+	case CommandMGet, CommandMDelete, CommandSInter, CommandSUnion, CommandSDiff, CommandBLPop, CommandBRPop:
+		return c.Args
+	case CommandMSet, CommandRestore:
+		keys := make([]string, 0, len(c.Args)/2)
+		for i := 0; i < len(c.Args); i += 2 {
+			keys = append(keys, c.Args[i])
+		}
+		return keys
+	case CommandXRead:
+		return streamKeys(c.Args[2:])
+	case CommandXReadGroup:
+		return streamKeys(c.Args[4:])
+	case CommandRaft, CommandCluster, CommandAsking, CommandAuth, CommandClient, CommandSlowLog, CommandMonitor,
+		CommandPing, CommandEcho, CommandQuit, CommandHelp, CommandCommand:
+		return nil
+	default:
+		return []string{c.Key}
+	}
+}
+
+// Fields lists the command type followed by the key, the value and the rest
+// of arguments. It is a readable form of the command for logs, arguments may
+// go in a different order than they were sent.
+func (c Command) Fields() []string {
+	fields := make([]string, 0, len(c.Args)+3)
+	fields = append(fields, string(c.Type))
+	if c.Key != "" {
+		fields = append(fields, c.Key)
+	}
+	if c.Value != "" {
+		fields = append(fields, c.Value)
+	}
+	return append(fields, c.Args...)
+}
+
+// streamKeys returns keys of stream reads arguments, keys followed by IDs.
+func streamKeys(args []string) []string {
+	return args[:len(args)/2]
+}
+
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// ScoreRange is a range of sorted set scores, each bound may be exclusive.
+type ScoreRange struct {
+	Min, Max                   float64
+	MinExclusive, MaxExclusive bool
+}
+
+func (r ScoreRange) AboveMin(score float64) bool {
+	if r.MinExclusive {
+		return score > r.Min
+	}
+	return score >= r.Min
+}
+
+func (r ScoreRange) BelowMax(score float64) bool {
+	if r.MaxExclusive {
+		return score < r.Max
+	}
+	return score <= r.Max
+}
+
+// ParseScoreBound parses a score range bound: a float, "-inf", "+inf"
+// or a float prefixed with "(" for an exclusive bound.
+func ParseScoreBound(s string) (score float64, exclusive bool, err error) {
+	if strings.HasPrefix(s, "(") {
+		exclusive = true
+		s = s[1:]
+	}
+
+	score, err = strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, false, ErrNotFloat
+	}
+	return score, exclusive, nil
 }
@@ -1,21 +1,116 @@
 package domain
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
-func TestCommandType_Valid(t *testing.T) {
+func TestCommand_Blocking(t *testing.T) {
 	t.Parallel()
 
-	valid := []CommandType{CommandDelete, CommandSet, CommandGet}
-	for _, v := range valid {
-		require.True(t, v.Valid())
+	require.True(t, Command{Type: CommandBLPop, Args: []string{"jobs"}, Value: "0"}.Blocking())
+	require.True(t, Command{Type: CommandXRead, Args: []string{"0", "100", "s", "$"}}.Blocking())
+	require.False(t, Command{Type: CommandXRead, Args: []string{"0", "-1", "s", "$"}}.Blocking())
+	require.True(t, Command{Type: CommandXReadGroup, Args: []string{"g", "c", "0", "0", "s", ">"}}.Blocking())
+	require.False(t, Command{Type: CommandXReadGroup, Args: []string{"g", "c", "0", "-1", "s", ">"}}.Blocking())
+	require.False(t, Command{Type: CommandSet, Key: "k", Value: "v"}.Blocking())
+}
+
+func TestCommand_Admin(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, Command{Type: CommandAuth, Args: []string{"alice", "secret"}}.Admin())
+	require.True(t, Command{Type: CommandRaft, Args: []string{RaftAdd, "n2", "127.0.0.1:4000"}}.Admin())
+	require.False(t, Command{Type: CommandRaft, Args: []string{RaftStatus}}.Admin())
+	require.True(t, Command{Type: CommandCluster, Args: []string{ClusterSetSlot, "1", SlotStable}}.Admin())
+	require.True(t, Command{Type: CommandCluster, Args: []string{ClusterMigrate, "0-10", "b"}}.Admin())
+	require.False(t, Command{Type: CommandCluster, Args: []string{ClusterMigrate, MigrateStatus}}.Admin())
+	require.False(t, Command{Type: CommandCluster, Args: []string{ClusterSlots}}.Admin())
+	require.True(t, Command{Type: CommandClient, Args: []string{ClientKill, ClientKillID, "1"}}.Admin())
+	require.False(t, Command{Type: CommandClient, Args: []string{ClientList}}.Admin())
+	require.True(t, Command{Type: CommandSlowLog, Args: []string{SlowLogReset}}.Admin())
+	require.False(t, Command{Type: CommandSet, Key: "k", Value: "v"}.Admin())
+}
+
+func TestCommand_MultiLineReply(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, Command{Type: CommandMGet, Args: []string{"a"}}.MultiLineReply())
+	require.True(t, Command{Type: CommandXRange, Key: "s"}.MultiLineReply())
+	require.True(t, Command{Type: CommandRaft, Args: []string{RaftStatus}}.MultiLineReply())
+	require.True(t, Command{Type: CommandCluster, Args: []string{ClusterMigrate, MigrateStatus}}.MultiLineReply())
+	require.False(t, Command{Type: CommandCluster, Args: []string{ClusterMigrate, "0-10", "b"}}.MultiLineReply())
+	require.False(t, Command{Type: CommandCluster, Args: []string{ClusterKeySlot, "k"}}.MultiLineReply())
+	require.False(t, Command{Type: CommandGet, Key: "k"}.MultiLineReply())
+	require.False(t, Command{Type: CommandLLen, Key: "k"}.MultiLineReply())
+	require.True(t, Command{Type: CommandSlowLog, Args: []string{SlowLogGet}}.MultiLineReply())
+	require.False(t, Command{Type: CommandSlowLog, Args: []string{SlowLogLen}}.MultiLineReply())
+	require.True(t, Command{Type: CommandClient, Args: []string{ClientList}}.MultiLineReply())
+	require.False(t, Command{Type: CommandClient, Args: []string{ClientGetName}}.MultiLineReply())
+	require.True(t, Command{Type: CommandHelp}.MultiLineReply())
+	require.True(t, Command{Type: CommandCommand}.MultiLineReply())
+	require.False(t, Command{Type: CommandPing}.MultiLineReply())
+}
+
+func TestCommand_Keys(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		cmd  Command
+		keys []string
+	}{
+		{cmd: Command{Type: CommandGet, Key: "k"}, keys: []string{"k"}},
+		{cmd: Command{Type: CommandMSet, Args: []string{"a", "1", "b", "2"}}, keys: []string{"a", "b"}},
+		{cmd: Command{Type: CommandRestore, Args: []string{"a", "ZGF0YQ==", "b", "ZGF0YQ=="}}, keys: []string{"a", "b"}},
+		{cmd: Command{Type: CommandBLPop, Args: []string{"a", "b"}, Value: "0"}, keys: []string{"a", "b"}},
+		{cmd: Command{Type: CommandXRead, Args: []string{"0", "-1", "a", "b", "0", "$"}}, keys: []string{"a", "b"}},
+		{cmd: Command{Type: CommandXReadGroup, Args: []string{"g", "c", "0", "-1", "a", ">"}}, keys: []string{"a"}},
+		{cmd: Command{Type: CommandCluster, Args: []string{ClusterSlots}}, keys: nil},
+		{cmd: Command{Type: CommandSlowLog, Args: []string{SlowLogLen}}, keys: nil},
+		{cmd: Command{Type: CommandEcho, Value: "hello"}, keys: nil},
+	}
+
+	for _, tc := range tt {
+		require.Equal(t, tc.keys, tc.cmd.Keys(), tc.cmd.Type)
+	}
+}
+
+func TestCommand_Fields(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"SET", "k", "v"}, Command{Type: CommandSet, Key: "k", Value: "v"}.Fields())
+	require.Equal(t, []string{"MGET", "a", "b"}, Command{Type: CommandMGet, Args: []string{"a", "b"}}.Fields())
+	require.Equal(t, []string{"ASKING"}, Command{Type: CommandAsking}.Fields())
+}
+
+func TestParseScoreBound(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in        string
+		score     float64
+		exclusive bool
+		err       bool
+	}{
+		{in: "1.5", score: 1.5},
+		{in: "(10", score: 10, exclusive: true},
+		{in: "-inf", score: math.Inf(-1)},
+		{in: "(+inf", score: math.Inf(1), exclusive: true},
+		{in: "nan", err: true},
+		{in: "((1", err: true},
+		{in: "abc", err: true},
 	}
 
-	notValid := []CommandType{"", "zzz"}
-	for _, v := range notValid {
-		require.False(t, v.Valid())
+	for _, c := range tt {
+		score, exclusive, err := ParseScoreBound(c.in)
+		if c.err {
+			require.Errorf(t, err, c.in)
+			continue
+		}
+		require.NoErrorf(t, err, c.in)
+		require.Equal(t, c.score, score)
+		require.Equal(t, c.exclusive, exclusive)
 	}
 }
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type nowKey struct{}
+
+// ContextWithNow makes storage take t as the current time, so replicas applying
+// the same command generate the same stream IDs.
+func ContextWithNow(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, nowKey{}, t)
+}
+
+// Now returns the time set by ContextWithNow or the current time.
+func Now(ctx context.Context) time.Time {
+	if ctx != nil {
+		if t, ok := ctx.Value(nowKey{}).(time.Time); ok {
+			return t
+		}
+	}
+	return time.Now()
+}
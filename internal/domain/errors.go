@@ -2,4 +2,20 @@ package domain
 
 import "errors"
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrNotInteger = errors.New("value is not an integer")
+	ErrNotFloat   = errors.New("value is not a valid float")
+	ErrOverflow   = errors.New("increment or decrement would overflow")
+	ErrOutOfRange = errors.New("offset is out of range")
+	ErrWrongType  = errors.New("WRONGTYPE operation against a key holding the wrong kind of value")
+
+	ErrInvalidStreamID  = errors.New("invalid stream ID")
+	ErrStreamIDTooSmall = errors.New("ID is equal or smaller than the stream top item")
+	ErrStreamExhausted  = errors.New("the stream has exhausted the last possible ID, unable to add more items")
+	ErrNoGroup          = errors.New("NOGROUP no such key or consumer group")
+	ErrGroupExists      = errors.New("BUSYGROUP consumer group name already exists")
+
+	// ErrNotLeader is followed by the client address of the leader when it is known.
+	ErrNotLeader = errors.New("NOTLEADER")
+)
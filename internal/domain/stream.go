@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamID identifies a stream entry: milliseconds of the entry creation
+// and a sequence number among entries created within the same millisecond.
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+// MaxStreamID is greater than or equal to any other ID.
+var MaxStreamID = StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+
+func (id StreamID) String() string {
+	return fmt.Sprintf("%d-%d", id.Ms, id.Seq)
+}
+
+func (id StreamID) Less(other StreamID) bool {
+	return id.Ms < other.Ms || (id.Ms == other.Ms && id.Seq < other.Seq)
+}
+
+func (id StreamID) IsZero() bool {
+	return id.Ms == 0 && id.Seq == 0
+}
+
+// ParseStreamID parses "ms-seq", the sequence may be omitted and defaults to defaultSeq.
+func ParseStreamID(s string, defaultSeq uint64) (StreamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, ErrInvalidStreamID
+	}
+
+	seq := defaultSeq
+	if hasSeq {
+		seq, err = strconv.ParseUint(seqPart, 10, 64)
+		if err != nil {
+			return StreamID{}, ErrInvalidStreamID
+		}
+	}
+
+	return StreamID{Ms: ms, Seq: seq}, nil
+}
+
+type StreamEntry struct {
+	ID     StreamID
+	Fields []KeyValue
+}
+
+// StreamEntries are entries read from the stream stored by the key.
+type StreamEntries struct {
+	Key     string
+	Entries []StreamEntry
+}
+
+// StreamOffset points to the position to read the stream from: entries with IDs
+// greater than ID are returned. Latest stands for "$" in XREAD, entries added after
+// the call, and for ">" in XREADGROUP, entries never delivered to the group.
+type StreamOffset struct {
+	Key    string
+	ID     StreamID
+	Latest bool
+}
+
+// PendingEntry is an entry delivered to the consumer of the group but not acknowledged yet.
+type PendingEntry struct {
+	ID         StreamID
+	Consumer   string
+	Idle       time.Duration
+	Deliveries int
+}
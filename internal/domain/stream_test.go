@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStreamID(t *testing.T) {
+	t.Parallel()
+
+	id, err := ParseStreamID("1526919030474-55", 0)
+	require.NoError(t, err)
+	require.Equal(t, StreamID{Ms: 1526919030474, Seq: 55}, id)
+	require.Equal(t, "1526919030474-55", id.String())
+
+	id, err = ParseStreamID("1526919030474", 7)
+	require.NoError(t, err)
+	require.Equal(t, StreamID{Ms: 1526919030474, Seq: 7}, id)
+
+	for _, s := range []string{"", "-", "abc", "1-abc", "-1-1", "1-2-3"} {
+		_, err = ParseStreamID(s, 0)
+		require.ErrorIsf(t, err, ErrInvalidStreamID, s)
+	}
+
+	require.True(t, StreamID{Ms: 1, Seq: 5}.Less(StreamID{Ms: 2}))
+	require.True(t, StreamID{Ms: 1, Seq: 5}.Less(StreamID{Ms: 1, Seq: 6}))
+	require.False(t, StreamID{Ms: 1, Seq: 5}.Less(StreamID{Ms: 1, Seq: 5}))
+	require.True(t, StreamID{}.IsZero())
+}
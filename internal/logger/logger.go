@@ -1,39 +1,115 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"time"
+	"sync"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
 )
 
-func New(fileName, level string) *slog.Logger {
-	w := os.Stdout
-	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModeAppend|os.ModeExclusive)
+// Logger writes records to the output file, rotating it, or to stdout when
+// no file is set. The level may be changed while the logger is used.
+type Logger struct {
+	*slog.Logger
+	Level *slog.LevelVar
+
+	file      *rotatingFile
+	closeOnce sync.Once
+}
+
+// New returns the logger configured by the logging section, stdout is used
+// when the output file fails to open. The logger must be closed.
+func New(cfg *config.Config) *Logger {
+	l := &Logger{Level: &slog.LevelVar{}}
+
+	var w io.Writer = os.Stdout
+	if name := cfg.Logging.Output; name != "" {
+		f, err := openRotatingFile(name, rotation{
+			maxSize:    int64(cfg.Logging.Rotation.MaxSize),
+			maxAge:     cfg.Logging.Rotation.MaxAge,
+			maxBackups: cfg.Logging.Rotation.MaxBackups,
+			compress:   cfg.Logging.Rotation.Compress,
+		})
+		if err != nil {
+			slog.Error("failed to open file for logging, use stdout", "error", err.Error(), "file_name", name)
+		} else {
+			l.file = f
+			w = f
+		}
+	}
+	if cfg.Logging.Stderr {
+		w = io.MultiWriter(w, os.Stderr)
+	}
+
+	level, err := ParseLevel(cfg.Logging.Level)
 	if err != nil {
-		slog.Error("failed to open file for logging, use stdout", err.Error(), "file_name", fileName)
+		slog.Error("failed to find log level, use debug", "level", cfg.Logging.Level)
+		level = slog.LevelDebug
+	}
+	l.Level.Set(level)
+
+	opts := &slog.HandlerOptions{Level: l.Level}
+	if cfg.Logging.Format == config.LogFormatText {
+		l.Logger = slog.New(slog.NewTextHandler(w, opts))
 	} else {
-		go func() {
-			t := time.NewTicker(time.Second)
-			for range t.C {
-				if err := f.Sync(); err != nil {
-					slog.Error("failed to sync log file", "file_name", fileName, "error", err.Error())
-				}
-			}
-		}()
+		l.Logger = slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return l
+}
+
+// Reopen opens the output file again after it has been moved away, like
+// logrotate does.
+func (l *Logger) Reopen() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.reopen()
+}
 
-		w = f
+// ReopenOnSignal reopens the output file on every signal until the context
+// is done.
+func (l *Logger) ReopenOnSignal(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+		}
+
+		if err := l.Reopen(); err != nil {
+			l.Error("failed to reopen log file", "error", err.Error())
+			continue
+		}
+		l.Info("log file reopened")
 	}
+}
 
+// Close stops syncing the output file and closes it, it is safe to call
+// Close more than once.
+func (l *Logger) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		if l.file != nil {
+			err = l.file.close()
+		}
+	})
+	return err
+}
+
+func ParseLevel(level string) (slog.Level, error) {
 	m := map[string]slog.Level{
 		"info":  slog.LevelInfo,
 		"debug": slog.LevelDebug,
+		"warn":  slog.LevelWarn,
 		"error": slog.LevelError,
 	}
 	l, ok := m[level]
 	if !ok {
-		slog.Error("failed to find log level, use debug", "level", level)
-		l = slog.LevelDebug
+		return 0, fmt.Errorf("unknown log level %q", level)
 	}
-
-	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l}))
+	return l, nil
 }
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(t *testing.T) *config.Config {
+		cfg := config.NewConfigWithDefaults()
+		cfg.Logging.Output = filepath.Join(t.TempDir(), "output.log")
+		return cfg
+	}
+
+	t.Run("write text records", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := newConfig(t)
+		cfg.Logging.Format = config.LogFormatText
+		cfg.Logging.Level = config.LogLevelInfo
+		l := New(cfg)
+		l.Debug("hidden")
+		l.Info("shown", "key", "value")
+		require.NoError(t, l.Close())
+		require.NoError(t, l.Close())
+
+		data, err := os.ReadFile(cfg.Logging.Output)
+		require.NoError(t, err)
+		require.Regexp(t, `^time=\S+ level=INFO msg=shown key=value\n$`, string(data))
+	})
+
+	t.Run("rotate by size keeping backups", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := newConfig(t)
+		cfg.Logging.Rotation.MaxSize = 200
+		cfg.Logging.Rotation.MaxBackups = 2
+		cfg.Logging.Rotation.Compress = true
+		l := New(cfg)
+		defer l.Close()
+
+		for i := range 10 {
+			l.Info("record", "i", i)
+		}
+
+		var backups []string
+		require.Eventually(t, func() bool {
+			backups, _ = filepath.Glob(cfg.Logging.Output + ".*")
+			return len(backups) == 2 && strings.HasSuffix(backups[0], ".gz") && strings.HasSuffix(backups[1], ".gz")
+		}, 5*time.Second, 10*time.Millisecond)
+
+		f, err := os.Open(backups[1])
+		require.NoError(t, err)
+		defer f.Close()
+		zr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		data, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"msg":"record"`)
+
+		info, err := os.Stat(cfg.Logging.Output)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(200))
+	})
+
+	t.Run("rotate by age", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := newConfig(t)
+		cfg.Logging.Rotation.MaxAge = 50 * time.Millisecond
+		l := New(cfg)
+		defer l.Close()
+
+		l.Info("first")
+		time.Sleep(60 * time.Millisecond)
+		l.Info("second")
+
+		backups, err := filepath.Glob(cfg.Logging.Output + ".*")
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		data, err := os.ReadFile(cfg.Logging.Output)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "second")
+		require.NotContains(t, string(data), "first")
+	})
+
+	t.Run("keep writing when rotation fails", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "logs")
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		cfg := config.NewConfigWithDefaults()
+		cfg.Logging.Output = filepath.Join(dir, "output.log")
+		cfg.Logging.Rotation.MaxSize = 100
+		l := New(cfg)
+		defer l.Close()
+
+		l.Info("before")
+		// the directory is gone, so renaming the file to a backup fails
+		moved := filepath.Join(filepath.Dir(dir), "moved")
+		require.NoError(t, os.Rename(dir, moved))
+		for i := range 5 {
+			l.Info("after", "i", i)
+		}
+
+		data, err := os.ReadFile(filepath.Join(moved, "output.log"))
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"msg":"before"`)
+		require.Contains(t, string(data), `"i":4`)
+		backups, err := filepath.Glob(filepath.Join(moved, "output.log.*"))
+		require.NoError(t, err)
+		require.Empty(t, backups)
+	})
+
+	t.Run("reopen moved file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := newConfig(t)
+		l := New(cfg)
+		defer l.Close()
+
+		l.Info("before")
+		moved := cfg.Logging.Output + ".1"
+		require.NoError(t, os.Rename(cfg.Logging.Output, moved))
+		require.NoError(t, l.Reopen())
+		l.Info("after")
+
+		data, err := os.ReadFile(cfg.Logging.Output)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "after")
+		require.NotContains(t, string(data), "before")
+		data, err = os.ReadFile(moved)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "before")
+	})
+}
@@ -0,0 +1,301 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	syncInterval = time.Second
+	// rotateRetryInterval delays the next rotation after a failed one.
+	rotateRetryInterval = 10 * time.Second
+
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressedSuffix = ".gz"
+)
+
+// rotation limits the output file, zero values disable the limits.
+type rotation struct {
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+}
+
+// rotatingFile is a log file renamed to a timestamped backup once it grows
+// over the size or gets older than the age. Backups are compressed and the
+// oldest of them removed in the background, along with syncing the file.
+type rotatingFile struct {
+	name string
+	rotation
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	// retryAt postpones rotation after it failed.
+	retryAt time.Time
+
+	rotated chan struct{}
+	// failed reports rotation errors to be logged by the background goroutine,
+	// logging them while writing could deadlock on the file itself.
+	failed chan error
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func openRotatingFile(name string, r rotation) (*rotatingFile, error) {
+	f := &rotatingFile{
+		name:     name,
+		rotation: r,
+		rotated:  make(chan struct{}, 1),
+		failed:   make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	f.wg.Add(1)
+	go f.run()
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.due(len(p)) {
+		if err := f.rotate(); err != nil {
+			// records keep going to the current file until a retry succeeds
+			f.retryAt = time.Now().Add(rotateRetryInterval)
+			select {
+			case f.failed <- err:
+			default:
+			}
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// due reports whether the file must be rotated before writing n bytes, a
+// record bigger than the size limit still goes to a file of its own.
+func (f *rotatingFile) due(n int) bool {
+	if f.size == 0 || time.Now().Before(f.retryAt) {
+		return false
+	}
+	return (f.maxSize > 0 && f.size+int64(n) > f.maxSize) ||
+		(f.maxAge > 0 && time.Since(f.openedAt) >= f.maxAge)
+}
+
+// rotate renames the file to a backup and opens a new one. The renamed file
+// stays open until the new one is, so records are not lost when it fails.
+func (f *rotatingFile) rotate() error {
+	old := f.file
+	if err := os.Rename(f.name, f.backupName(time.Now())); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	select {
+	case f.rotated <- struct{}{}:
+	default:
+	}
+	return old.Close()
+}
+
+// backupName returns a name no backup has, moving the timestamp forward
+// when files are rotated within the same millisecond.
+func (f *rotatingFile) backupName(t time.Time) string {
+	for ; ; t = t.Add(time.Millisecond) {
+		name := f.name + "." + t.Format(backupTimeFormat)
+		if !exists(name) && !exists(name+compressedSuffix) {
+			return name
+		}
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// reopen closes and opens the file again, it is used after the file has been
+// renamed by an external tool like logrotate.
+func (f *rotatingFile) reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	// the old file keeps taking records when the new one cannot be opened
+	old := f.file
+	if err := f.open(); err != nil {
+		return err
+	}
+	return old.Close()
+}
+
+func (f *rotatingFile) sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+func (f *rotatingFile) close() error {
+	close(f.done)
+	f.wg.Wait()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *rotatingFile) run() {
+	defer f.wg.Done()
+
+	t := time.NewTicker(syncInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-t.C:
+			if err := f.sync(); err != nil {
+				slog.Error("failed to sync log file", "file_name", f.name, "error", err.Error())
+			}
+		case err := <-f.failed:
+			slog.Error("failed to rotate log file", "file_name", f.name, "error", err.Error())
+		case <-f.rotated:
+			if err := f.processBackups(); err != nil {
+				slog.Error("failed to process rotated log files", "file_name", f.name, "error", err.Error())
+			}
+		}
+	}
+}
+
+// processBackups compresses new backups and removes ones over the limit.
+func (f *rotatingFile) processBackups() error {
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	if f.maxBackups > 0 && len(backups) > f.maxBackups {
+		for _, b := range backups[:len(backups)-f.maxBackups] {
+			if err := os.Remove(b); err != nil {
+				return err
+			}
+		}
+		backups = backups[len(backups)-f.maxBackups:]
+	}
+
+	if f.compress {
+		for _, b := range backups {
+			if strings.HasSuffix(b, compressedSuffix) {
+				continue
+			}
+			if err := compressFile(b); err != nil {
+				return fmt.Errorf("compress %s: %w", b, err)
+			}
+		}
+	}
+	return nil
+}
+
+// backups returns backup files of the log, the oldest first.
+func (f *rotatingFile) backups() ([]string, error) {
+	matches, err := filepath.Glob(f.name + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]string, 0, len(matches))
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, f.name+"."), compressedSuffix)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	slices.Sort(backups)
+	return backups, nil
+}
+
+// compressFile replaces the file with its gzip copy.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressedSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(dst.Name())
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
@@ -0,0 +1,99 @@
+// Package netaddr handles addresses of the text protocol: host:port or
+// tcp://host:port for TCP and unix:///path/to.sock for unix domain sockets.
+package netaddr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	schemeTCP  = "tcp://"
+	schemeUnix = "unix://"
+)
+
+var errSocketInUse = errors.New("socket is in use")
+
+// Parse returns the network and the address to pass to the net package.
+func Parse(address string) (network, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, schemeUnix):
+		path := strings.TrimPrefix(address, schemeUnix)
+		if path == "" {
+			return "", "", fmt.Errorf("empty socket path in %q", address)
+		}
+		return "unix", path, nil
+	case strings.HasPrefix(address, schemeTCP):
+		return "tcp", strings.TrimPrefix(address, schemeTCP), nil
+	case strings.Contains(address, "://"):
+		return "", "", fmt.Errorf("unsupported address scheme in %q", address)
+	default:
+		return "tcp", address, nil
+	}
+}
+
+// Dial connects to the address, zero timeout means no timeout.
+func Dial(address string, timeout time.Duration) (net.Conn, error) {
+	network, addr, err := Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	return net.DialTimeout(network, addr, timeout)
+}
+
+// Listen listens on the address. A unix socket gets the file mode, a socket
+// file left by a crashed process is removed first, while a socket somebody
+// still accepts on is reported as in use. The socket file is removed when
+// the listener is closed.
+func Listen(address string, mode os.FileMode) (net.Listener, error) {
+	network, addr, err := Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	if network == "tcp" {
+		return net.Listen(network, addr)
+	}
+
+	if err = removeStaleSocket(addr); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(addr, mode); err != nil {
+		return nil, errors.Join(fmt.Errorf("chmod socket: %w", err), l.Close())
+	}
+
+	return l, nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stat socket: %w", err)
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%q exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%q: %w", path, errSocketInUse)
+	}
+
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("remove stale socket: %w", err)
+	}
+	return nil
+}
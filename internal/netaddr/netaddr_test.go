@@ -0,0 +1,77 @@
+package netaddr
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		in      string
+		network string
+		addr    string
+		err     bool
+	}{
+		{in: "127.0.0.1:3223", network: "tcp", addr: "127.0.0.1:3223"},
+		{in: "tcp://localhost:3223", network: "tcp", addr: "localhost:3223"},
+		{in: "unix:///var/run/kvs.sock", network: "unix", addr: "/var/run/kvs.sock"},
+		{in: "unix://kvs.sock", network: "unix", addr: "kvs.sock"},
+		{in: "unix://", err: true},
+		{in: "udp://127.0.0.1:3223", err: true},
+	}
+
+	for _, tc := range tt {
+		network, addr, err := Parse(tc.in)
+		if tc.err {
+			require.Error(t, err, tc.in)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		require.Equal(t, tc.network, network, tc.in)
+		require.Equal(t, tc.addr, addr, tc.in)
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "kvs.sock")
+	address := "unix://" + path
+
+	l, err := Listen(address, 0o660)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	_, err = Listen(address, 0o660)
+	require.ErrorIs(t, err, errSocketInUse)
+
+	conn, err := Dial(address, 0)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// a socket file left by a crashed process
+	ul := l.(*net.UnixListener)
+	ul.SetUnlinkOnClose(false)
+	require.NoError(t, l.Close())
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	l, err = Listen(address, 0o600)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	_, err = Listen(address, 0o600)
+	require.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package proxy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/netaddr"
+)
+
+const errorPrefix = "ERROR: "
+
+var errUnexpectedReply = errors.New("unexpected backend reply")
+
+type backendConfig struct {
+	maxIdleConns     int
+	idleConnTimeout  time.Duration
+	dialTimeout      time.Duration
+	timeout          time.Duration
+	failureThreshold int
+	bufferSize       int
+}
+
+// backend is a server behind the proxy with a pool of idle connections.
+// It is ejected from routing after failureThreshold failures in a row and
+// brought back by the first successful request or health check.
+type backend struct {
+	address string
+	cfg     backendConfig
+	log     *slog.Logger
+
+	idle     chan *backendConn
+	failures atomic.Int32
+	healthy  atomic.Bool
+}
+
+type backendConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	usedAt time.Time
+}
+
+func newBackend(address string, cfg backendConfig, l *slog.Logger) *backend {
+	b := &backend{
+		address: address,
+		cfg:     cfg,
+		log:     l,
+		idle:    make(chan *backendConn, cfg.maxIdleConns),
+	}
+	b.healthy.Store(true)
+	return b
+}
+
+// roundTrip sends the command line and returns the reply lines, a multi-line
+// reply is read up to its last line. Blocking commands wait for the reply
+// without a deadline. A connection failed on IO is dropped.
+func (b *backend) roundTrip(line string, multi, blocking bool) ([]string, error) {
+	c, err := b.get()
+	if err != nil {
+		b.fail()
+		return nil, err
+	}
+
+	var deadline time.Time
+	if !blocking {
+		deadline = time.Now().Add(b.cfg.timeout)
+	}
+	if err = c.conn.SetDeadline(deadline); err != nil {
+		b.close(c)
+		b.fail()
+		return nil, fmt.Errorf("set deadline: %w", err)
+	}
+
+	lines, err := c.exchange(line, multi)
+	if err != nil {
+		b.close(c)
+		b.fail()
+		return nil, fmt.Errorf("backend %q: %w", b.address, err)
+	}
+
+	b.put(c)
+	b.succeed()
+	return lines, nil
+}
+
+// check sends the command over a new connection, so a backend which stopped
+// accepting connections is detected even if pooled ones still work.
+func (b *backend) check(line string) error {
+	c, err := b.dial()
+	if err == nil {
+		defer b.close(c)
+		if err = c.conn.SetDeadline(time.Now().Add(b.cfg.timeout)); err == nil {
+			_, err = c.exchange(line, false)
+		}
+	}
+	if err != nil {
+		b.fail()
+		return err
+	}
+
+	b.succeed()
+	return nil
+}
+
+func (b *backend) get() (*backendConn, error) {
+	for {
+		select {
+		case c := <-b.idle:
+			if time.Since(c.usedAt) < b.cfg.idleConnTimeout {
+				return c, nil
+			}
+			b.close(c)
+		default:
+			return b.dial()
+		}
+	}
+}
+
+func (b *backend) dial() (*backendConn, error) {
+	conn, err := netaddr.Dial(b.address, b.cfg.dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("dial backend %q: %w", b.address, err)
+	}
+	return &backendConn{conn: conn, reader: bufio.NewReaderSize(conn, b.cfg.bufferSize)}, nil
+}
+
+func (b *backend) put(c *backendConn) {
+	c.usedAt = time.Now()
+	select {
+	case b.idle <- c:
+	default:
+		b.close(c)
+	}
+}
+
+// closeIdle closes pooled connections, connections in use are closed when returned.
+func (b *backend) closeIdle() {
+	for {
+		select {
+		case c := <-b.idle:
+			b.close(c)
+		default:
+			return
+		}
+	}
+}
+
+func (b *backend) close(c *backendConn) {
+	if err := c.conn.Close(); err != nil {
+		b.log.Error("failed to close backend connection", "backend", b.address, "error", err.Error())
+	}
+}
+
+func (b *backend) alive() bool {
+	return b.healthy.Load()
+}
+
+func (b *backend) fail() {
+	if int(b.failures.Add(1)) >= b.cfg.failureThreshold && b.healthy.Swap(false) {
+		b.log.Warn("backend ejected", "backend", b.address)
+	}
+}
+
+func (b *backend) succeed() {
+	b.failures.Store(0)
+	if !b.healthy.Swap(true) {
+		b.log.Info("backend is back", "backend", b.address)
+	}
+}
+
+func (c *backendConn) exchange(line string, multi bool) ([]string, error) {
+	if _, err := c.conn.Write([]byte(line + "\n")); err != nil {
+		return nil, fmt.Errorf("write command: %w", err)
+	}
+
+	first, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if !multi {
+		return []string{first}, nil
+	}
+
+	// errors and nil replies have no following lines
+	n, err := strconv.Atoi(first)
+	if err != nil {
+		return []string{first}, nil
+	}
+
+	lines := make([]string, 1, n+1)
+	lines[0] = first
+	for range n {
+		l, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func (c *backendConn) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("read reply: %w", err)
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// replyError returns the error the backend replied with.
+func replyError(lines []string) error {
+	if len(lines) == 1 && strings.HasPrefix(lines[0], errorPrefix) {
+		return errors.New(strings.TrimPrefix(lines[0], errorPrefix))
+	}
+	return nil
+}
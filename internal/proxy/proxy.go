@@ -0,0 +1,136 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+// Proxy accepts the text protocol and routes commands to backend servers
+// chosen by consistent hashing on the command keys.
+type Proxy struct {
+	log      *slog.Logger
+	cfg      *config.Config
+	backends []*backend
+	ring     ring
+
+	sessionLimiter chan struct{}
+}
+
+func New(cfg *config.Config, l *slog.Logger) (Proxy, error) {
+	if len(cfg.Proxy.Backends) == 0 {
+		return Proxy{}, errors.New("no backends configured")
+	}
+
+	bc := backendConfig{
+		maxIdleConns:     cfg.Proxy.MaxIdleConns,
+		idleConnTimeout:  cfg.Proxy.IdleConnTimeout,
+		dialTimeout:      cfg.Proxy.DialTimeout,
+		timeout:          cfg.Proxy.BackendTimeout,
+		failureThreshold: cfg.Proxy.FailureThreshold,
+		bufferSize:       cfg.Network.MaxMessageSize.Int(),
+	}
+	backends := make([]*backend, len(cfg.Proxy.Backends))
+	for i, address := range cfg.Proxy.Backends {
+		backends[i] = newBackend(address, bc, l)
+	}
+
+	return Proxy{
+		log:            l,
+		cfg:            cfg,
+		backends:       backends,
+		ring:           newRing(cfg.Proxy.Backends, cfg.Proxy.VirtualNodes),
+		sessionLimiter: make(chan struct{}, cfg.Network.MaxConnections),
+	}, nil
+}
+
+func (p Proxy) Run(ctx context.Context) error {
+	l, err := net.Listen("tcp", p.cfg.Proxy.Address)
+	if err != nil {
+		return fmt.Errorf("net listen: %w", err)
+	}
+
+	p.log.Debug("ready to accept connections", "address", p.cfg.Proxy.Address)
+
+	go p.checkHealth(ctx)
+	go func() {
+		<-ctx.Done()
+		if err := l.Close(); err != nil {
+			p.log.Error("failed to close listener", "error", err.Error())
+		}
+		for _, b := range p.backends {
+			b.closeIdle()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.log.Debug("got context done, stop proxy")
+			return ctx.Err()
+		default:
+		}
+
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				p.log.Error("failed to accept connection", "error", err.Error())
+			}
+			continue
+		}
+
+		select {
+		case p.sessionLimiter <- struct{}{}:
+			p.log.Debug("start session", "src", conn.RemoteAddr().String())
+		default:
+			p.log.Debug("drop session due the limit", "src", conn.RemoteAddr().String())
+			if err := conn.Close(); err != nil {
+				p.log.Error("failed to close connection", "error", err.Error())
+			}
+			continue
+		}
+
+		go func() {
+			defer func() {
+				if err := conn.Close(); err != nil {
+					p.log.Error("failed to close connection", "error", err.Error())
+				}
+			}()
+
+			start := time.Now()
+			p.serve(ctx, conn)
+			<-p.sessionLimiter
+
+			p.log.Debug("session finished", "src", conn.RemoteAddr().String(), "duration", time.Since(start).String())
+		}()
+	}
+}
+
+// healthCheckCommand is answered by a backend without touching its storage.
+const healthCheckCommand = "PING"
+
+// checkHealth probes backends periodically, the probe result ejects or brings
+// back the backend like any request does.
+func (p Proxy) checkHealth(ctx context.Context) {
+	t := time.NewTicker(p.cfg.Proxy.HealthCheckInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		for _, b := range p.backends {
+			if err := b.check(healthCheckCommand); err != nil {
+				p.log.Debug("backend health check failed", "backend", b.address, "error", err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/server"
+	"github.com/tmvrus/key-value-storage/internal/storage"
+	"github.com/tmvrus/key-value-storage/pkg/client"
+)
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	// start runs backends and the proxy in front of them, cancelling a backend
+	// context stops it.
+	start := func(t *testing.T, backends int) (Proxy, *client.Client, []context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		cfg := config.NewConfigWithDefaults()
+		cfg.Proxy.Address = findFreePort(t)
+		cfg.Proxy.HealthCheckInterval = 20 * time.Millisecond
+		cfg.Proxy.FailureThreshold = 1
+
+		stops := make([]context.CancelFunc, backends)
+		for i := range stops {
+			bcfg := config.NewConfigWithDefaults()
+			bcfg.Network.Address = findFreePort(t)
+			cfg.Proxy.Backends = append(cfg.Proxy.Backends, bcfg.Network.Address)
+
+			var bctx context.Context
+			bctx, stops[i] = context.WithCancel(ctx)
+			go func() { _ = server.New(bcfg, storage.New(bcfg), log).Run(bctx) }()
+			waitListening(t, bcfg.Network.Address)
+		}
+
+		p, err := New(cfg, log)
+		require.NoError(t, err)
+		go func() { _ = p.Run(ctx) }()
+		waitListening(t, cfg.Proxy.Address)
+
+		conn, err := net.Dial("tcp", cfg.Proxy.Address)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		return p, client.NewClient(conn, log), stops
+	}
+
+	t.Run("routes single key commands", func(t *testing.T) {
+		t.Parallel()
+
+		p, c, _ := start(t, 2)
+
+		require.NoError(t, c.Set("a", "1"))
+		v, err := c.Get("a")
+		require.NoError(t, err)
+		require.Equal(t, "1", v)
+
+		n, err := c.Do("LPUSH", "jobs", "x", "y")
+		require.NoError(t, err)
+		require.Equal(t, "2", n)
+
+		_, err = c.Do("CLUSTER", "SLOTS")
+		require.EqualError(t, err, errUnsupported.Error())
+		_, err = c.Do("GET")
+		require.Error(t, err)
+
+		pong, err := c.Do("PING")
+		require.NoError(t, err)
+		require.Equal(t, "PONG", pong)
+		echo, err := c.Do("ECHO", "hello")
+		require.NoError(t, err)
+		require.Equal(t, "hello", echo)
+
+		reply, quit := p.handle("HELP SET")
+		require.Equal(t, "1\nSET key value", reply)
+		require.False(t, quit)
+		reply, _ = p.handle("COMMAND")
+		require.Equal(t, strconv.Itoa(len(parser.Commands())), strings.Split(reply, "\n")[0])
+		require.Contains(t, reply, "\nGET 2\n")
+
+		_, err = c.Do("QUIT")
+		require.NoError(t, err)
+		_, err = c.Do("PING")
+		require.Error(t, err)
+	})
+
+	t.Run("fans out multi-key commands", func(t *testing.T) {
+		t.Parallel()
+
+		_, c, _ := start(t, 3)
+
+		pairs := map[string]string{"k1": "1", "k2": "2", "k3": "3", "k4": "4", "k5": "5", "k6": "6"}
+		require.NoError(t, c.MSet(pairs))
+
+		values, err := c.MGet("k6", "k1", "missing", "k3", "k2")
+		require.NoError(t, err)
+		require.Len(t, values, 5)
+		require.Equal(t, "6", *values[0])
+		require.Equal(t, "1", *values[1])
+		require.Nil(t, values[2])
+		require.Equal(t, "3", *values[3])
+		require.Equal(t, "2", *values[4])
+
+		deleted, err := c.MDelete("k1", "missing", "k5")
+		require.NoError(t, err)
+		require.Equal(t, []bool{true, false, true}, deleted)
+
+		_, err = c.Do("SINTER", "{t}.a", "{t}.b")
+		require.NoError(t, err)
+	})
+
+	t.Run("ejects failed backend", func(t *testing.T) {
+		t.Parallel()
+
+		p, c, stops := start(t, 2)
+
+		stops[1]()
+		require.Eventually(t, func() bool { return !p.backends[1].alive() }, time.Second, 10*time.Millisecond)
+		require.True(t, p.backends[0].alive())
+
+		for _, k := range []string{"a", "b", "c", "d", "e", "f"} {
+			require.NoError(t, c.Set(k, k))
+		}
+		values, err := c.MGet("a", "b", "c", "d", "e", "f")
+		require.NoError(t, err)
+		for _, v := range values {
+			require.NotNil(t, v)
+		}
+	})
+}
+
+func findFreePort(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	return l.Addr().String()
+}
+
+func waitListening(t *testing.T, address string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			return false
+		}
+		return conn.Close() == nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,60 @@
+package proxy
+
+import (
+	"cmp"
+	"crypto/md5"
+	"encoding/binary"
+	"slices"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+)
+
+// ring maps keys to backends with consistent hashing, so a backend leaving
+// the ring moves only its own keys to the neighbouring backends.
+type ring struct {
+	points []point
+}
+
+type point struct {
+	hash    uint32
+	backend int
+}
+
+// newRing places virtualNodes points per backend, backends are referred to
+// by their index.
+func newRing(backends []string, virtualNodes int) ring {
+	points := make([]point, 0, len(backends)*virtualNodes)
+	for i, b := range backends {
+		for v := 0; v < virtualNodes; v++ {
+			points = append(points, point{hash: hash(b + "#" + strconv.Itoa(v)), backend: i})
+		}
+	}
+	slices.SortFunc(points, func(a, b point) int { return cmp.Compare(a.hash, b.hash) })
+
+	return ring{points: points}
+}
+
+// lookup returns the first alive backend clockwise from the key point. Only
+// the hash tag of the key is hashed, so keys sharing a tag share a backend.
+func (r ring) lookup(key string, alive func(int) bool) (int, bool) {
+	if len(r.points) == 0 {
+		return 0, false
+	}
+
+	h := hash(cluster.HashTag(key))
+	start, _ := slices.BinarySearchFunc(r.points, h, func(p point, h uint32) int { return cmp.Compare(p.hash, h) })
+	for i := range r.points {
+		p := r.points[(start+i)%len(r.points)]
+		if alive(p.backend) {
+			return p.backend, true
+		}
+	}
+
+	return 0, false
+}
+
+func hash(s string) uint32 {
+	sum := md5.Sum([]byte(s))
+	return binary.BigEndian.Uint32(sum[:4])
+}
@@ -0,0 +1,50 @@
+package proxy
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	backends := []string{"10.0.0.1:3223", "10.0.0.2:3223", "10.0.0.3:3223"}
+	r := newRing(backends, 128)
+	all := func(int) bool { return true }
+
+	const keys = 3000
+	counts := make([]int, len(backends))
+	owners := make([]int, keys)
+	for k := range keys {
+		i, ok := r.lookup("key:"+strconv.Itoa(k), all)
+		require.True(t, ok)
+		counts[i]++
+		owners[k] = i
+	}
+	for _, c := range counts {
+		require.InDelta(t, keys/len(backends), c, keys/10, counts)
+	}
+
+	// keys of the ejected backend move, others stay
+	without1 := func(i int) bool { return i != 1 }
+	for k := range keys {
+		i, ok := r.lookup("key:"+strconv.Itoa(k), without1)
+		require.True(t, ok)
+		if owners[k] != 1 {
+			require.Equal(t, owners[k], i)
+		} else {
+			require.NotEqual(t, 1, i)
+		}
+	}
+
+	a, _ := r.lookup("{user:1}.name", all)
+	b, _ := r.lookup("{user:1}.email", all)
+	require.Equal(t, a, b)
+
+	_, ok := r.lookup("key", func(int) bool { return false })
+	require.False(t, ok)
+	_, ok = newRing(nil, 128).lookup("key", all)
+	require.False(t, ok)
+}
@@ -0,0 +1,206 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/internal/tracing"
+)
+
+var (
+	errUnsupported        = errors.New("command is not supported by proxy")
+	errCrossBackend       = errors.New("keys belong to different backends, use hash tags to keep them together")
+	errNoBackendAvailable = errors.New("no backend available")
+)
+
+// serve handles the client session until the client leaves, stays idle for
+// too long or ctx is done.
+func (p Proxy) serve(ctx context.Context, conn net.Conn) {
+	bufferSize := p.cfg.Network.MaxMessageSize.Int()
+	input := bufio.NewScanner(conn)
+	input.Buffer(make([]byte, bufferSize), bufferSize)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := conn.SetReadDeadline(time.Now().Add(p.cfg.Network.IdleTimeout)); err != nil {
+			p.log.Error("failed to set read deadline", "error", err.Error())
+			return
+		}
+		if !input.Scan() {
+			if err := input.Err(); err != nil {
+				p.log.Debug("stop session", "error", err.Error())
+			}
+			return
+		}
+
+		reply, quit := p.handle(input.Text())
+		if _, err := conn.Write([]byte(reply + "\n")); err != nil {
+			p.log.Error("failed to write reply", "error", err.Error())
+			return
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+// handle returns the reply to the command line and whether the session ends
+// after it. A trace header is forwarded along with the line unless the
+// command is split across backends.
+func (p Proxy) handle(line string) (reply string, quit bool) {
+	_, text := tracing.SplitHeader(line)
+	cmd, err := parser.Parse(text)
+	if err != nil {
+		return errorPrefix + err.Error(), false
+	}
+
+	lines, err := p.do(cmd, line)
+	if err != nil {
+		return errorPrefix + err.Error(), false
+	}
+	return strings.Join(lines, "\n"), cmd.Type == domain.CommandQuit
+}
+
+func (p Proxy) do(c domain.Command, line string) ([]string, error) {
+	switch c.Type {
+	case domain.CommandRaft, domain.CommandCluster, domain.CommandAsking, domain.CommandRestore:
+		return nil, errUnsupported
+	case domain.CommandMGet, domain.CommandMSet, domain.CommandMDelete:
+		return p.fanOut(c, line)
+	case domain.CommandPing:
+		if c.Value == "" {
+			return []string{"PONG"}, nil
+		}
+		return []string{c.Value}, nil
+	case domain.CommandEcho:
+		return []string{c.Value}, nil
+	case domain.CommandQuit:
+		return []string{"OK"}, nil
+	case domain.CommandHelp:
+		var t domain.CommandType
+		if len(c.Args) == 1 {
+			t = domain.CommandType(c.Args[0])
+		}
+		lines, err := parser.HelpLines(t)
+		if err != nil {
+			return nil, err
+		}
+		return append([]string{strconv.Itoa(len(lines))}, lines...), nil
+	case domain.CommandCommand:
+		lines := parser.CommandLines()
+		return append([]string{strconv.Itoa(len(lines))}, lines...), nil
+	}
+
+	var b *backend
+	for _, k := range c.Keys() {
+		kb, err := p.backendFor(k)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil && kb != b {
+			return nil, errCrossBackend
+		}
+		b = kb
+	}
+	if b == nil {
+		return nil, errUnsupported
+	}
+
+	return b.roundTrip(line, c.MultiLineReply(), c.Blocking())
+}
+
+func (p Proxy) backendFor(key string) (*backend, error) {
+	i, ok := p.ring.lookup(key, func(i int) bool { return p.backends[i].alive() })
+	if !ok {
+		return nil, errNoBackendAvailable
+	}
+	return p.backends[i], nil
+}
+
+// part is a piece of a multi-key command sent to a single backend, idx holds
+// indexes of its keys among the command arguments.
+type part struct {
+	idx   []int
+	lines []string
+	err   error
+}
+
+// fanOut splits the multi-key command by backends, sends the parts in
+// parallel and merges the replies in the order of keys. MSET is atomic on
+// every backend but not across them.
+func (p Proxy) fanOut(c domain.Command, line string) ([]string, error) {
+	step := 1
+	if c.Type == domain.CommandMSet {
+		step = 2
+	}
+
+	parts := make(map[*backend]*part)
+	for i := 0; i < len(c.Args); i += step {
+		b, err := p.backendFor(c.Args[i])
+		if err != nil {
+			return nil, err
+		}
+		if parts[b] == nil {
+			parts[b] = &part{}
+		}
+		parts[b].idx = append(parts[b].idx, i)
+	}
+
+	if len(parts) == 1 {
+		for b := range parts {
+			return b.roundTrip(line, c.MultiLineReply(), false)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for b, pt := range parts {
+		args := []string{string(c.Type)}
+		for _, i := range pt.idx {
+			args = append(args, c.Args[i:i+step]...)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pt.lines, pt.err = b.roundTrip(strings.Join(args, " "), c.MultiLineReply(), false)
+			if pt.err == nil {
+				pt.err = replyError(pt.lines)
+			}
+		}()
+	}
+	wg.Wait()
+
+	results := make([]string, len(c.Args))
+	for _, pt := range parts {
+		if pt.err != nil {
+			return nil, pt.err
+		}
+		if !c.MultiLineReply() {
+			continue
+		}
+		if len(pt.lines) != len(pt.idx)+1 {
+			return nil, errUnexpectedReply
+		}
+		for j, i := range pt.idx {
+			results[i] = pt.lines[j+1]
+		}
+	}
+
+	if !c.MultiLineReply() {
+		return []string{"OK"}, nil
+	}
+	return append([]string{strconv.Itoa(len(c.Args))}, results...), nil
+}
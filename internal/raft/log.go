@@ -0,0 +1,134 @@
+package raft
+
+type EntryType uint8
+
+const (
+	// EntryCommand carries data applied to the state machine.
+	EntryCommand EntryType = iota
+	// EntryNoop is appended by a new leader to commit entries of previous terms.
+	EntryNoop
+	// EntryConfig carries the new cluster membership.
+	EntryConfig
+)
+
+type Entry struct {
+	Index   uint64
+	Term    uint64
+	Type    EntryType
+	Data    []byte
+	Members []Member
+}
+
+// entryLog keeps entries after the last snapshot. The first entry is a sentinel
+// holding the index and the term of the last entry included in the snapshot.
+type entryLog struct {
+	entries []Entry
+	// unsavedFrom is the index of the first entry appended since the log was
+	// saved, zero when there is none. rewrite is set once entries are
+	// truncated or compacted, the log is saved anew then.
+	unsavedFrom uint64
+	rewrite     bool
+}
+
+func newEntryLog() *entryLog {
+	return &entryLog{entries: []Entry{{}}}
+}
+
+func (l *entryLog) firstIndex() uint64 {
+	return l.entries[0].Index
+}
+
+func (l *entryLog) lastIndex() uint64 {
+	return l.entries[len(l.entries)-1].Index
+}
+
+func (l *entryLog) lastTerm() uint64 {
+	return l.entries[len(l.entries)-1].Term
+}
+
+// term returns the term of the entry, false when the entry is compacted or absent.
+func (l *entryLog) term(index uint64) (uint64, bool) {
+	if index < l.firstIndex() || index > l.lastIndex() {
+		return 0, false
+	}
+	return l.entries[index-l.firstIndex()].Term, true
+}
+
+func (l *entryLog) entry(index uint64) Entry {
+	return l.entries[index-l.firstIndex()]
+}
+
+// between returns entries in the inclusive range, at most limit of them if limit is positive.
+func (l *entryLog) between(from, to uint64, limit int) []Entry {
+	if from <= l.firstIndex() || from > to {
+		return nil
+	}
+
+	entries := l.entries[from-l.firstIndex() : to-l.firstIndex()+1]
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return append([]Entry(nil), entries...)
+}
+
+func (l *entryLog) append(entries ...Entry) {
+	if l.unsavedFrom == 0 && len(entries) > 0 {
+		l.unsavedFrom = entries[0].Index
+	}
+	l.entries = append(l.entries, entries...)
+}
+
+// truncateFrom removes the entry with the index and all entries after it.
+func (l *entryLog) truncateFrom(index uint64) {
+	l.entries = l.entries[:index-l.firstIndex()]
+	l.rewrite = true
+}
+
+// compact drops entries up to the index included in the snapshot.
+func (l *entryLog) compact(index, term uint64) {
+	var rest []Entry
+	if t, ok := l.term(index); ok && t == term {
+		rest = l.entries[index-l.firstIndex()+1:]
+	}
+
+	l.entries = append([]Entry{{Index: index, Term: term}}, rest...)
+	l.rewrite = true
+}
+
+// unsaved returns entries appended since the log was saved.
+func (l *entryLog) unsaved() []Entry {
+	if l.unsavedFrom == 0 {
+		return nil
+	}
+	return l.between(l.unsavedFrom, l.lastIndex(), 0)
+}
+
+// saved marks all entries as saved.
+func (l *entryLog) saved() {
+	l.unsavedFrom = 0
+	l.rewrite = false
+}
+
+// membersAt returns the latest membership configured at or before the index,
+// false when it is not in the log anymore.
+func (l *entryLog) membersAt(index uint64) ([]Member, bool) {
+	if index > l.lastIndex() {
+		index = l.lastIndex()
+	}
+	for i := index; i > l.firstIndex(); i-- {
+		if e := l.entry(i); e.Type == EntryConfig {
+			return e.Members, true
+		}
+	}
+	return nil, false
+}
+
+// lastConfigIndex returns the index of the latest membership entry, zero when there is none.
+func (l *entryLog) lastConfigIndex() uint64 {
+	for i := l.lastIndex(); i > l.firstIndex(); i-- {
+		if l.entry(i).Type == EntryConfig {
+			return i
+		}
+	}
+	return 0
+}
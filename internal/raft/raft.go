@@ -0,0 +1,1004 @@
+// Package raft replicates a log of commands across a cluster with the Raft
+// consensus algorithm, applying committed commands to a state machine in the
+// same order on every member.
+package raft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const maxEntriesPerRequest = 256
+
+var (
+	ErrNotLeader              = errors.New("not leader")
+	ErrLeadershipLost         = errors.New("leadership lost")
+	ErrStopped                = errors.New("raft node is stopped")
+	ErrConfigChangeInProgress = errors.New("configuration change is in progress")
+	ErrUnknownMember          = errors.New("unknown member")
+	ErrMemberExists           = errors.New("member already exists")
+)
+
+// NotLeaderError is returned by a member that can not accept writes, Leader
+// is empty when the leader is unknown.
+type NotLeaderError struct {
+	Leader Member
+}
+
+func (e NotLeaderError) Error() string {
+	if e.Leader.ID == "" {
+		return "not leader, leader is unknown"
+	}
+	return fmt.Sprintf("not leader, leader is %s", e.Leader.ID)
+}
+
+func (e NotLeaderError) Is(target error) bool {
+	return target == ErrNotLeader
+}
+
+type State int
+
+const (
+	Follower State = iota
+	Candidate
+	Leader
+)
+
+func (s State) String() string {
+	switch s {
+	case Follower:
+		return "follower"
+	case Candidate:
+		return "candidate"
+	case Leader:
+		return "leader"
+	default:
+		return "unknown"
+	}
+}
+
+type Member struct {
+	ID      string
+	Address string
+	// ClientAddress is the address clients are redirected to when the member is the leader.
+	ClientAddress string
+}
+
+// FSM is the replicated state machine. Apply must be deterministic, Snapshot
+// and Restore must capture and replace the whole state.
+type FSM interface {
+	Apply(data []byte) any
+	Snapshot() ([]byte, error)
+	Restore(data []byte) error
+}
+
+type Config struct {
+	ID string
+	// Members is the initial cluster configuration, it is empty for a member
+	// joining an existing cluster with AddMember.
+	Members           []Member
+	ElectionTimeout   time.Duration
+	HeartbeatInterval time.Duration
+	// SnapshotThreshold is the number of applied entries that triggers log compaction.
+	SnapshotThreshold uint64
+}
+
+type Status struct {
+	ID          string
+	State       State
+	Term        uint64
+	Leader      string
+	CommitIndex uint64
+	LastApplied uint64
+	Members     []Member
+}
+
+type waiter struct {
+	term   uint64
+	result chan applyResult
+}
+
+type applyResult struct {
+	value any
+	err   error
+}
+
+type Node struct {
+	cfg       Config
+	fsm       FSM
+	transport Transport
+	log       *slog.Logger
+
+	// fsmLock serializes the state machine access between applying and restoring snapshots.
+	fsmLock sync.Mutex
+
+	lock             sync.Mutex
+	applied          *sync.Cond
+	state            State
+	term             uint64
+	votedFor         string
+	leaderID         string
+	leaderContact    time.Time
+	electionDeadline time.Time
+	lastHeartbeat    time.Time
+	entries          *entryLog
+	members          []Member
+	commitIndex      uint64
+	lastApplied      uint64
+	nextIndex        map[string]uint64
+	matchIndex       map[string]uint64
+	inflight         map[string]bool
+	waiters          map[uint64]waiter
+	snapshot         []byte
+	snapshotMembers  []Member
+	stopped          bool
+
+	// storage is nil unless the node persists its state, stateDirty and
+	// snapshotDirty mark changes not saved yet, the log tracks its own.
+	storage       *diskStorage
+	stateDirty    bool
+	snapshotDirty bool
+	// err is the failure to save the state that stopped the node.
+	err error
+}
+
+func New(cfg Config, fsm FSM, transport Transport, l *slog.Logger) *Node {
+	n := &Node{
+		cfg:             cfg,
+		fsm:             fsm,
+		transport:       transport,
+		log:             l.With("raft_id", cfg.ID),
+		entries:         newEntryLog(),
+		members:         cfg.Members,
+		snapshotMembers: cfg.Members,
+		nextIndex:       make(map[string]uint64),
+		matchIndex:      make(map[string]uint64),
+		inflight:        make(map[string]bool),
+		waiters:         make(map[uint64]waiter),
+	}
+	n.applied = sync.NewCond(&n.lock)
+
+	return n
+}
+
+// Run drives elections, heartbeats and applying of committed entries until the
+// context is done.
+func (n *Node) Run(ctx context.Context) error {
+	n.lock.Lock()
+	n.resetElectionDeadline()
+	n.lock.Unlock()
+
+	go n.applyLoop()
+
+	ticker := time.NewTicker(n.cfg.HeartbeatInterval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.stop()
+			return ctx.Err()
+		case <-ticker.C:
+			if err := n.tick(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Persist makes the node keep its term, vote, log and snapshot in the
+// directory, so a restarted member keeps promises it made to others. The
+// state saved earlier is loaded first and the snapshot is restored to the
+// state machine. It must be called before Run.
+func (n *Node) Persist(dir string) error {
+	s, saved, err := openDiskStorage(dir)
+	if err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if snap := saved.snapshot; snap != nil {
+		if err = n.fsm.Restore(snap.Data); err != nil {
+			_ = s.close()
+			return fmt.Errorf("restore raft snapshot: %w", err)
+		}
+		n.entries.compact(snap.Index, snap.Term)
+		n.snapshot = snap.Data
+		n.snapshotMembers = snap.Members
+		n.lastApplied = snap.Index
+		n.commitIndex = snap.Index
+	}
+
+	for _, e := range saved.entries {
+		// entries compacted right before a crash are still in the log
+		if e.Index <= n.entries.firstIndex() {
+			continue
+		}
+		if e.Index != n.entries.lastIndex()+1 {
+			_ = s.close()
+			return fmt.Errorf("raft log misses entries before %d", e.Index)
+		}
+		n.entries.append(e)
+	}
+	n.entries.saved()
+	n.refreshMembers()
+
+	n.term = saved.state.Term
+	n.votedFor = saved.state.VotedFor
+	n.storage = s
+
+	n.log.Info("loaded raft state", "term", n.term, "first_index", n.entries.firstIndex(), "last_index", n.entries.lastIndex())
+	return nil
+}
+
+func (n *Node) ID() string {
+	return n.cfg.ID
+}
+
+func (n *Node) Status() Status {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return Status{
+		ID:          n.cfg.ID,
+		State:       n.state,
+		Term:        n.term,
+		Leader:      n.leaderID,
+		CommitIndex: n.commitIndex,
+		LastApplied: n.lastApplied,
+		Members:     append([]Member(nil), n.members...),
+	}
+}
+
+// Apply replicates the data and returns the result of applying it to the state
+// machine once a quorum commits it. Only the leader accepts data.
+func (n *Node) Apply(ctx context.Context, data []byte) (any, error) {
+	n.lock.Lock()
+	if err := n.checkLeader(); err != nil {
+		n.lock.Unlock()
+		return nil, err
+	}
+
+	result := n.propose(Entry{Type: EntryCommand, Data: data})
+	n.lock.Unlock()
+
+	return n.wait(ctx, result)
+}
+
+// AddMember adds the member to the cluster configuration, the member starts
+// receiving entries immediately and votes once the change is appended.
+func (n *Node) AddMember(ctx context.Context, m Member) error {
+	return n.changeMembers(ctx, func(members []Member) ([]Member, error) {
+		for _, existing := range members {
+			if existing.ID == m.ID {
+				return nil, ErrMemberExists
+			}
+		}
+		return append(append([]Member(nil), members...), m), nil
+	})
+}
+
+// RemoveMember removes the member from the cluster configuration, a removed
+// leader steps down once the change is committed.
+func (n *Node) RemoveMember(ctx context.Context, id string) error {
+	return n.changeMembers(ctx, func(members []Member) ([]Member, error) {
+		result := make([]Member, 0, len(members))
+		for _, existing := range members {
+			if existing.ID != id {
+				result = append(result, existing)
+			}
+		}
+		if len(result) == len(members) {
+			return nil, ErrUnknownMember
+		}
+		return result, nil
+	})
+}
+
+func (n *Node) changeMembers(ctx context.Context, change func([]Member) ([]Member, error)) error {
+	n.lock.Lock()
+	if err := n.checkLeader(); err != nil {
+		n.lock.Unlock()
+		return err
+	}
+
+	// Only one change at a time is safe, and a new leader must commit an entry of
+	// its own term before it knows the latest configuration is committed.
+	if t, _ := n.entries.term(n.commitIndex); n.entries.lastConfigIndex() > n.commitIndex || t != n.term {
+		n.lock.Unlock()
+		return ErrConfigChangeInProgress
+	}
+
+	members, err := change(n.members)
+	if err != nil {
+		n.lock.Unlock()
+		return err
+	}
+
+	result := n.propose(Entry{Type: EntryConfig, Members: members})
+	n.lock.Unlock()
+
+	_, err = n.wait(ctx, result)
+	return err
+}
+
+func (n *Node) checkLeader() error {
+	if n.stopped {
+		return ErrStopped
+	}
+	if n.state != Leader {
+		leader, _ := n.member(n.leaderID)
+		return NotLeaderError{Leader: leader}
+	}
+	return nil
+}
+
+// propose appends the entry to the leader log and starts replicating it.
+func (n *Node) propose(e Entry) chan applyResult {
+	e.Index = n.entries.lastIndex() + 1
+	e.Term = n.term
+	n.entries.append(e)
+
+	if e.Type == EntryConfig {
+		n.setMembers(e.Members)
+	}
+
+	result := make(chan applyResult, 1)
+	n.waiters[e.Index] = waiter{term: e.Term, result: result}
+
+	// the leader counts itself in the quorum, so the entry is saved first
+	if !n.persist() {
+		return result
+	}
+
+	n.broadcast()
+	n.advanceCommitIndex()
+
+	return result
+}
+
+func (n *Node) wait(ctx context.Context, result chan applyResult) (any, error) {
+	select {
+	case r := <-result:
+		return r.value, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (n *Node) tick() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.err != nil {
+		return n.err
+	}
+
+	switch {
+	case n.stopped:
+	case n.state == Leader:
+		if time.Since(n.lastHeartbeat) >= n.cfg.HeartbeatInterval {
+			n.broadcast()
+		}
+	case time.Now().After(n.electionDeadline):
+		if _, ok := n.member(n.cfg.ID); ok {
+			n.startElection()
+		}
+	}
+	return nil
+}
+
+func (n *Node) startElection() {
+	n.state = Candidate
+	n.term++
+	n.votedFor = n.cfg.ID
+	n.stateDirty = true
+	n.leaderID = ""
+	n.resetElectionDeadline()
+	if !n.persist() {
+		return
+	}
+
+	n.log.Debug("starting election", "term", n.term)
+
+	req := &RequestVoteRequest{
+		Term:         n.term,
+		CandidateID:  n.cfg.ID,
+		LastLogIndex: n.entries.lastIndex(),
+		LastLogTerm:  n.entries.lastTerm(),
+	}
+
+	votes := 1
+	if votes >= n.quorum() {
+		n.becomeLeader()
+		return
+	}
+
+	for _, m := range n.members {
+		if m.ID == n.cfg.ID {
+			continue
+		}
+
+		go func(m Member) {
+			ctx, cancel := context.WithTimeout(context.Background(), n.cfg.ElectionTimeout)
+			defer cancel()
+
+			resp, err := n.transport.RequestVote(ctx, m, req)
+			if err != nil {
+				return
+			}
+
+			n.lock.Lock()
+			defer n.lock.Unlock()
+
+			if resp.Term > n.term {
+				n.stepDown(resp.Term)
+				return
+			}
+			if n.state != Candidate || n.term != req.Term || !resp.VoteGranted {
+				return
+			}
+
+			votes++
+			if votes >= n.quorum() {
+				n.becomeLeader()
+			}
+		}(m)
+	}
+}
+
+func (n *Node) becomeLeader() {
+	n.log.Info("became leader", "term", n.term)
+
+	n.state = Leader
+	n.leaderID = n.cfg.ID
+	for _, m := range n.members {
+		n.nextIndex[m.ID] = n.entries.lastIndex() + 1
+		n.matchIndex[m.ID] = 0
+	}
+
+	n.propose(Entry{Type: EntryNoop})
+}
+
+// stepDown turns the member into a follower, adopting the term if it is newer.
+func (n *Node) stepDown(term uint64) {
+	if term > n.term {
+		n.term = term
+		n.votedFor = ""
+		n.stateDirty = true
+	}
+
+	if n.state == Leader {
+		n.log.Info("stepped down", "term", n.term)
+		n.failWaiters(n.commitIndex, ErrLeadershipLost)
+	}
+
+	n.state = Follower
+	n.resetElectionDeadline()
+}
+
+func (n *Node) stop() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.halt()
+}
+
+// halt stops the node, the caller must hold the lock.
+func (n *Node) halt() {
+	if n.stopped {
+		return
+	}
+
+	n.stopped = true
+	n.failWaiters(0, ErrStopped)
+	n.applied.Broadcast()
+	if n.storage != nil {
+		if err := n.storage.close(); err != nil {
+			n.log.Error("failed to close raft log", "error", err.Error())
+		}
+	}
+}
+
+// persist saves changes of the term, the vote, the log and the snapshot, the
+// member does it before it answers an RPC. It returns false when the node is
+// stopped, a failure to save stops the node as it can not keep its promises.
+func (n *Node) persist() bool {
+	if n.stopped {
+		return false
+	}
+	if n.storage == nil {
+		return true
+	}
+
+	if err := n.save(); err != nil {
+		n.log.Error("failed to save raft state", "error", err.Error())
+		n.err = err
+		n.halt()
+		return false
+	}
+	return true
+}
+
+func (n *Node) save() error {
+	// the snapshot goes first, the log written next may start after it
+	if n.snapshotDirty {
+		err := n.storage.saveSnapshot(persistedSnapshot{
+			Index:   n.entries.firstIndex(),
+			Term:    n.entries.entries[0].Term,
+			Members: n.snapshotMembers,
+			Data:    n.snapshot,
+		})
+		if err != nil {
+			return err
+		}
+		n.snapshotDirty = false
+	}
+
+	if n.entries.rewrite {
+		if err := n.storage.rewriteLog(n.entries.entries[1:]); err != nil {
+			return err
+		}
+	} else if entries := n.entries.unsaved(); len(entries) > 0 {
+		if err := n.storage.appendLog(entries); err != nil {
+			return err
+		}
+	}
+	n.entries.saved()
+
+	if n.stateDirty {
+		if err := n.storage.saveState(hardState{Term: n.term, VotedFor: n.votedFor}); err != nil {
+			return err
+		}
+		n.stateDirty = false
+	}
+	return nil
+}
+
+// failWaiters fails waiters of entries after the index, committed entries are
+// still applied and their waiters get the result.
+func (n *Node) failWaiters(after uint64, err error) {
+	for index, w := range n.waiters {
+		if index > after {
+			w.result <- applyResult{err: err}
+			delete(n.waiters, index)
+		}
+	}
+}
+
+// broadcast sends entries or heartbeats to every follower.
+func (n *Node) broadcast() {
+	n.lastHeartbeat = time.Now()
+	for _, m := range n.members {
+		if m.ID != n.cfg.ID {
+			n.replicate(m)
+		}
+	}
+}
+
+// replicate sends the follower the entries it misses, or the snapshot if they
+// are already compacted. Only one request per follower is in flight.
+func (n *Node) replicate(m Member) {
+	if n.inflight[m.ID] {
+		return
+	}
+	n.inflight[m.ID] = true
+
+	next, ok := n.nextIndex[m.ID]
+	if !ok {
+		next = n.entries.lastIndex() + 1
+		n.nextIndex[m.ID] = next
+	}
+
+	if next <= n.entries.firstIndex() {
+		n.sendSnapshot(m)
+		return
+	}
+
+	prevIndex := next - 1
+	prevTerm, _ := n.entries.term(prevIndex)
+	req := &AppendEntriesRequest{
+		Term:         n.term,
+		LeaderID:     n.cfg.ID,
+		PrevLogIndex: prevIndex,
+		PrevLogTerm:  prevTerm,
+		Entries:      n.entries.between(next, n.entries.lastIndex(), maxEntriesPerRequest),
+		LeaderCommit: n.commitIndex,
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), n.cfg.ElectionTimeout)
+		defer cancel()
+
+		resp, err := n.transport.AppendEntries(ctx, m, req)
+
+		n.lock.Lock()
+		defer n.lock.Unlock()
+
+		n.inflight[m.ID] = false
+		if err != nil {
+			return
+		}
+		if resp.Term > n.term {
+			n.stepDown(resp.Term)
+			return
+		}
+		if n.state != Leader || n.term != req.Term {
+			return
+		}
+
+		if !resp.Success {
+			n.nextIndex[m.ID] = max(1, min(resp.ConflictIndex, n.nextIndex[m.ID]-1))
+			n.replicateIfMember(m.ID)
+			return
+		}
+
+		match := req.PrevLogIndex + uint64(len(req.Entries))
+		n.matchIndex[m.ID] = max(n.matchIndex[m.ID], match)
+		n.nextIndex[m.ID] = match + 1
+		n.advanceCommitIndex()
+
+		if n.nextIndex[m.ID] <= n.entries.lastIndex() {
+			n.replicateIfMember(m.ID)
+		}
+	}()
+}
+
+func (n *Node) sendSnapshot(m Member) {
+	req := &InstallSnapshotRequest{
+		Term:              n.term,
+		LeaderID:          n.cfg.ID,
+		LastIncludedIndex: n.entries.firstIndex(),
+		LastIncludedTerm:  n.entries.entries[0].Term,
+		Members:           n.snapshotMembers,
+		Data:              n.snapshot,
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), n.cfg.ElectionTimeout)
+		defer cancel()
+
+		resp, err := n.transport.InstallSnapshot(ctx, m, req)
+
+		n.lock.Lock()
+		defer n.lock.Unlock()
+
+		n.inflight[m.ID] = false
+		if err != nil {
+			return
+		}
+		if resp.Term > n.term {
+			n.stepDown(resp.Term)
+			return
+		}
+		if n.state != Leader || n.term != req.Term {
+			return
+		}
+
+		n.matchIndex[m.ID] = max(n.matchIndex[m.ID], req.LastIncludedIndex)
+		n.nextIndex[m.ID] = n.matchIndex[m.ID] + 1
+		n.replicateIfMember(m.ID)
+	}()
+}
+
+func (n *Node) replicateIfMember(id string) {
+	if m, ok := n.member(id); ok {
+		n.replicate(m)
+	}
+}
+
+// advanceCommitIndex commits the latest entry of the current term stored by a quorum.
+func (n *Node) advanceCommitIndex() {
+	for index := n.entries.lastIndex(); index > n.commitIndex; index-- {
+		if t, _ := n.entries.term(index); t != n.term {
+			break
+		}
+
+		count := 0
+		for _, m := range n.members {
+			if m.ID == n.cfg.ID || n.matchIndex[m.ID] >= index {
+				count++
+			}
+		}
+
+		if count >= n.quorum() {
+			n.commitIndex = index
+			n.applied.Broadcast()
+			break
+		}
+	}
+
+	// A leader removed from the configuration manages the cluster until the
+	// change is committed and then lets the remaining members elect a new one.
+	if _, ok := n.member(n.cfg.ID); !ok && n.state == Leader && n.entries.lastConfigIndex() <= n.commitIndex {
+		n.stepDown(n.term)
+	}
+}
+
+// HandleRequestVote answers once the term and the vote are saved.
+func (n *Node) HandleRequestVote(req *RequestVoteRequest) (*RequestVoteResponse, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.stopped {
+		return nil, ErrStopped
+	}
+	resp := n.requestVote(req)
+	if !n.persist() {
+		return nil, ErrStopped
+	}
+	return resp, nil
+}
+
+func (n *Node) requestVote(req *RequestVoteRequest) *RequestVoteResponse {
+	// A member that still hears from the leader ignores candidates, so removed
+	// members can not disrupt the cluster.
+	if n.leaderID != "" && n.leaderID != req.CandidateID && time.Since(n.leaderContact) < n.cfg.ElectionTimeout {
+		return &RequestVoteResponse{Term: n.term}
+	}
+
+	if req.Term < n.term {
+		return &RequestVoteResponse{Term: n.term}
+	}
+	if req.Term > n.term {
+		n.stepDown(req.Term)
+		n.leaderID = ""
+	}
+
+	upToDate := req.LastLogTerm > n.entries.lastTerm() ||
+		req.LastLogTerm == n.entries.lastTerm() && req.LastLogIndex >= n.entries.lastIndex()
+	if (n.votedFor == "" || n.votedFor == req.CandidateID) && upToDate {
+		n.votedFor = req.CandidateID
+		n.stateDirty = true
+		n.resetElectionDeadline()
+		return &RequestVoteResponse{Term: n.term, VoteGranted: true}
+	}
+
+	return &RequestVoteResponse{Term: n.term}
+}
+
+// HandleAppendEntries answers once the term and the appended entries are saved.
+func (n *Node) HandleAppendEntries(req *AppendEntriesRequest) (*AppendEntriesResponse, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.stopped {
+		return nil, ErrStopped
+	}
+	resp := n.appendEntries(req)
+	if !n.persist() {
+		return nil, ErrStopped
+	}
+	return resp, nil
+}
+
+func (n *Node) appendEntries(req *AppendEntriesRequest) *AppendEntriesResponse {
+	if req.Term < n.term {
+		return &AppendEntriesResponse{Term: n.term}
+	}
+	if req.Term > n.term || n.state != Follower {
+		n.stepDown(req.Term)
+	}
+	n.followLeader(req.LeaderID)
+
+	resp := &AppendEntriesResponse{Term: n.term}
+	if req.PrevLogIndex > n.entries.lastIndex() {
+		resp.ConflictIndex = n.entries.lastIndex() + 1
+		return resp
+	}
+
+	if req.PrevLogIndex > n.entries.firstIndex() {
+		if t, _ := n.entries.term(req.PrevLogIndex); t != req.PrevLogTerm {
+			// Skip the whole conflicting term instead of one entry per round trip.
+			conflict := req.PrevLogIndex
+			for conflict-1 > n.entries.firstIndex() {
+				if prev, _ := n.entries.term(conflict - 1); prev != t {
+					break
+				}
+				conflict--
+			}
+			resp.ConflictIndex = conflict
+			return resp
+		}
+	}
+
+	for i, e := range req.Entries {
+		if e.Index <= n.entries.firstIndex() {
+			continue
+		}
+		if e.Index <= n.entries.lastIndex() {
+			if t, _ := n.entries.term(e.Index); t == e.Term {
+				continue
+			}
+			n.entries.truncateFrom(e.Index)
+		}
+		n.entries.append(req.Entries[i:]...)
+		break
+	}
+	n.refreshMembers()
+
+	if commit := min(req.LeaderCommit, req.PrevLogIndex+uint64(len(req.Entries))); commit > n.commitIndex {
+		n.commitIndex = commit
+		n.applied.Broadcast()
+	}
+
+	resp.Success = true
+	return resp
+}
+
+// HandleInstallSnapshot answers once the term and the snapshot are saved.
+func (n *Node) HandleInstallSnapshot(req *InstallSnapshotRequest) (*InstallSnapshotResponse, error) {
+	n.fsmLock.Lock()
+	defer n.fsmLock.Unlock()
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.stopped {
+		return nil, ErrStopped
+	}
+	resp := n.installSnapshot(req)
+	if !n.persist() {
+		return nil, ErrStopped
+	}
+	return resp, nil
+}
+
+func (n *Node) installSnapshot(req *InstallSnapshotRequest) *InstallSnapshotResponse {
+	if req.Term < n.term {
+		return &InstallSnapshotResponse{Term: n.term}
+	}
+	if req.Term > n.term || n.state != Follower {
+		n.stepDown(req.Term)
+	}
+	n.followLeader(req.LeaderID)
+
+	if req.LastIncludedIndex <= n.lastApplied {
+		return &InstallSnapshotResponse{Term: n.term}
+	}
+
+	if err := n.fsm.Restore(req.Data); err != nil {
+		n.log.Error("failed to restore snapshot", "error", err.Error())
+		return &InstallSnapshotResponse{Term: n.term}
+	}
+
+	n.entries.compact(req.LastIncludedIndex, req.LastIncludedTerm)
+	n.snapshot = req.Data
+	n.snapshotMembers = req.Members
+	n.snapshotDirty = true
+	n.refreshMembers()
+	n.lastApplied = req.LastIncludedIndex
+	n.commitIndex = max(n.commitIndex, req.LastIncludedIndex)
+
+	n.log.Info("installed snapshot", "index", req.LastIncludedIndex)
+
+	return &InstallSnapshotResponse{Term: n.term}
+}
+
+func (n *Node) followLeader(id string) {
+	n.leaderID = id
+	n.leaderContact = time.Now()
+	n.resetElectionDeadline()
+}
+
+func (n *Node) applyLoop() {
+	for {
+		n.lock.Lock()
+		for !n.stopped && n.lastApplied >= n.commitIndex {
+			n.applied.Wait()
+		}
+		if n.stopped {
+			n.lock.Unlock()
+			return
+		}
+		entries := n.entries.between(n.lastApplied+1, n.commitIndex, 0)
+		n.lock.Unlock()
+
+		n.fsmLock.Lock()
+		n.apply(entries)
+		n.maybeSnapshot()
+		n.fsmLock.Unlock()
+	}
+}
+
+func (n *Node) apply(entries []Entry) {
+	for _, e := range entries {
+		n.lock.Lock()
+		// A snapshot installed meanwhile already contains the entry.
+		skip := e.Index != n.lastApplied+1
+		n.lock.Unlock()
+		if skip {
+			return
+		}
+
+		var value any
+		if e.Type == EntryCommand {
+			value = n.fsm.Apply(e.Data)
+		}
+
+		n.lock.Lock()
+		n.lastApplied = e.Index
+		if w, ok := n.waiters[e.Index]; ok {
+			delete(n.waiters, e.Index)
+			if w.term == e.Term {
+				w.result <- applyResult{value: value}
+			} else {
+				w.result <- applyResult{err: ErrLeadershipLost}
+			}
+		}
+		n.lock.Unlock()
+	}
+}
+
+func (n *Node) maybeSnapshot() {
+	n.lock.Lock()
+	index := n.lastApplied
+	due := n.cfg.SnapshotThreshold > 0 && index-n.entries.firstIndex() >= n.cfg.SnapshotThreshold
+	n.lock.Unlock()
+	if !due {
+		return
+	}
+
+	data, err := n.fsm.Snapshot()
+	if err != nil {
+		n.log.Error("failed to take snapshot", "error", err.Error())
+		return
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if members, ok := n.entries.membersAt(index); ok {
+		n.snapshotMembers = members
+	}
+	term, _ := n.entries.term(index)
+	n.entries.compact(index, term)
+	n.snapshot = data
+	n.snapshotDirty = true
+	n.persist()
+
+	n.log.Debug("compacted log", "index", index)
+}
+
+// refreshMembers uses the latest configuration in the log, even uncommitted.
+func (n *Node) refreshMembers() {
+	if members, ok := n.entries.membersAt(n.entries.lastIndex()); ok {
+		n.setMembers(members)
+		return
+	}
+	n.setMembers(n.snapshotMembers)
+}
+
+func (n *Node) setMembers(members []Member) {
+	n.members = members
+	for _, m := range members {
+		if _, ok := n.nextIndex[m.ID]; !ok && n.state == Leader {
+			n.nextIndex[m.ID] = n.entries.lastIndex() + 1
+		}
+	}
+}
+
+func (n *Node) member(id string) (Member, bool) {
+	for _, m := range n.members {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return Member{}, false
+}
+
+func (n *Node) quorum() int {
+	return len(n.members)/2 + 1
+}
+
+func (n *Node) resetElectionDeadline() {
+	timeout := n.cfg.ElectionTimeout + rand.N(n.cfg.ElectionTimeout)
+	n.electionDeadline = time.Now().Add(timeout)
+}
@@ -0,0 +1,438 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testElectionTimeout   = 50 * time.Millisecond
+	testHeartbeatInterval = 10 * time.Millisecond
+	waitTimeout           = 5 * time.Second
+	waitTick              = 10 * time.Millisecond
+)
+
+// kvFSM applies "key=value" commands and returns the previous value.
+type kvFSM struct {
+	lock sync.Mutex
+	data map[string]string
+}
+
+func (f *kvFSM) Apply(data []byte) any {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key, value, _ := strings.Cut(string(data), "=")
+	prev := f.data[key]
+	f.data[key] = value
+	return prev
+}
+
+func (f *kvFSM) Snapshot() ([]byte, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return json.Marshal(f.data)
+}
+
+func (f *kvFSM) Restore(data []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.data = make(map[string]string)
+	return json.Unmarshal(data, &f.data)
+}
+
+func (f *kvFSM) get(key string) string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.data[key]
+}
+
+func (f *kvFSM) len() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return len(f.data)
+}
+
+type cluster struct {
+	t                 *testing.T
+	network           *InmemNetwork
+	snapshotThreshold uint64
+	// dataDir keeps the state of members in subdirectories, nothing is kept when empty.
+	dataDir string
+	nodes   map[string]*Node
+	fsms    map[string]*kvFSM
+	stops   map[string]func()
+}
+
+func newCluster(t *testing.T, size int, snapshotThreshold uint64) *cluster {
+	c := &cluster{
+		t:                 t,
+		network:           NewInmemNetwork(),
+		snapshotThreshold: snapshotThreshold,
+		nodes:             make(map[string]*Node),
+		fsms:              make(map[string]*kvFSM),
+		stops:             make(map[string]func()),
+	}
+	c.startMembers(size)
+	return c
+}
+
+// newPersistentCluster starts a cluster keeping the state of members, so
+// they can be restarted.
+func newPersistentCluster(t *testing.T, size int, snapshotThreshold uint64) *cluster {
+	c := &cluster{
+		t:                 t,
+		network:           NewInmemNetwork(),
+		snapshotThreshold: snapshotThreshold,
+		dataDir:           t.TempDir(),
+		nodes:             make(map[string]*Node),
+		fsms:              make(map[string]*kvFSM),
+		stops:             make(map[string]func()),
+	}
+	c.startMembers(size)
+	return c
+}
+
+func (c *cluster) startMembers(size int) {
+	var members []Member
+	for i := 1; i <= size; i++ {
+		members = append(members, Member{ID: fmt.Sprintf("n%d", i)})
+	}
+	for _, m := range members {
+		c.start(m.ID, members)
+	}
+}
+
+func (c *cluster) start(id string, members []Member) *Node {
+	fsm := &kvFSM{data: make(map[string]string)}
+	n := New(Config{
+		ID:                id,
+		Members:           members,
+		ElectionTimeout:   testElectionTimeout,
+		HeartbeatInterval: testHeartbeatInterval,
+		SnapshotThreshold: c.snapshotThreshold,
+	}, fsm, c.network.Transport(id), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if c.dataDir != "" {
+		require.NoError(c.t, n.Persist(filepath.Join(c.dataDir, id)))
+	}
+
+	c.network.Register(id, n)
+	c.nodes[id] = n
+	c.fsms[id] = fsm
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.stops[id] = func() {
+		cancel()
+		<-done
+	}
+	c.t.Cleanup(cancel)
+	go func() {
+		defer close(done)
+		_ = n.Run(ctx)
+	}()
+
+	return n
+}
+
+// restart stops the member and starts it again with an empty state machine.
+func (c *cluster) restart(id string) *Node {
+	c.stops[id]()
+	return c.start(id, c.nodes[id].cfg.Members)
+}
+
+// leader waits until one of the connected members, except the excluded ones,
+// becomes the leader.
+func (c *cluster) leader(exclude ...string) *Node {
+	var leader *Node
+	require.Eventually(c.t, func() bool {
+		for id, n := range c.nodes {
+			if !contains(exclude, id) && n.Status().State == Leader {
+				leader = n
+				return true
+			}
+		}
+		return false
+	}, waitTimeout, waitTick)
+
+	return leader
+}
+
+func (c *cluster) follower(leader *Node) *Node {
+	for _, n := range c.nodes {
+		if n != leader {
+			return n
+		}
+	}
+	c.t.Fatal("no followers")
+	return nil
+}
+
+func (c *cluster) apply(n *Node, cmd string) {
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+
+	_, err := n.Apply(ctx, []byte(cmd))
+	require.NoError(c.t, err)
+}
+
+func (c *cluster) waitValue(id, key, value string) {
+	require.Eventually(c.t, func() bool {
+		return c.fsms[id].get(key) == value
+	}, waitTimeout, waitTick, "member %s did not apply %s=%s", id, key, value)
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNode_ElectsSingleLeader(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3, 0)
+	leader := c.leader()
+
+	require.Eventually(t, func() bool {
+		for _, n := range c.nodes {
+			if s := n.Status(); s.Leader != leader.ID() || (n != leader && s.State != Follower) {
+				return false
+			}
+		}
+		return true
+	}, waitTimeout, waitTick)
+}
+
+func TestNode_Apply(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3, 0)
+	leader := c.leader()
+
+	c.apply(leader, "a=1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+	prev, err := leader.Apply(ctx, []byte("a=2"))
+	require.NoError(t, err)
+	require.Equal(t, "1", prev)
+
+	for id := range c.nodes {
+		c.waitValue(id, "a", "2")
+	}
+}
+
+func TestNode_FollowerRejectsWrites(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3, 0)
+	leader := c.leader()
+	follower := c.follower(leader)
+
+	require.Eventually(t, func() bool {
+		return follower.Status().Leader == leader.ID()
+	}, waitTimeout, waitTick)
+
+	_, err := follower.Apply(context.Background(), []byte("a=1"))
+	require.ErrorIs(t, err, ErrNotLeader)
+
+	var notLeader NotLeaderError
+	require.ErrorAs(t, err, &notLeader)
+	require.Equal(t, leader.ID(), notLeader.Leader.ID)
+}
+
+func TestNode_ReelectsAfterLeaderFailure(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3, 0)
+	old := c.leader()
+	c.apply(old, "a=1")
+
+	c.network.Disconnect(old.ID())
+	leader := c.leader(old.ID())
+	c.apply(leader, "a=2")
+
+	c.network.Connect(old.ID())
+	require.Eventually(t, func() bool {
+		return old.Status().State == Follower
+	}, waitTimeout, waitTick)
+	c.waitValue(old.ID(), "a", "2")
+}
+
+func TestNode_MinorityCanNotCommit(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3, 0)
+	leader := c.leader()
+	for id := range c.nodes {
+		if id != leader.ID() {
+			c.network.Disconnect(id)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*testElectionTimeout)
+	defer cancel()
+
+	_, err := leader.Apply(ctx, []byte("a=1"))
+	require.Error(t, err)
+	require.Empty(t, c.fsms[leader.ID()].get("a"))
+}
+
+func TestNode_CatchesUpFromSnapshot(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3, 5)
+	leader := c.leader()
+	lagging := c.follower(leader)
+
+	c.network.Disconnect(lagging.ID())
+	for i := 0; i < 20; i++ {
+		c.apply(leader, fmt.Sprintf("k%d=%d", i, i))
+	}
+
+	require.Eventually(t, func() bool {
+		leader.lock.Lock()
+		defer leader.lock.Unlock()
+		return leader.entries.firstIndex() > 0
+	}, waitTimeout, waitTick, "leader did not compact the log")
+
+	c.network.Connect(lagging.ID())
+	c.waitValue(lagging.ID(), "k19", "19")
+	require.Equal(t, 20, c.fsms[lagging.ID()].len())
+}
+
+func TestNode_MembershipChanges(t *testing.T) {
+	t.Parallel()
+
+	c := newCluster(t, 3, 0)
+	leader := c.leader()
+	c.apply(leader, "a=1")
+
+	joining := c.start("n4", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+
+	require.NoError(t, leader.AddMember(ctx, Member{ID: joining.ID()}))
+	require.ErrorIs(t, leader.AddMember(ctx, Member{ID: joining.ID()}), ErrMemberExists)
+	c.waitValue(joining.ID(), "a", "1")
+	require.Len(t, joining.Status().Members, 4)
+
+	require.NoError(t, leader.RemoveMember(ctx, leader.ID()))
+	next := c.leader(leader.ID())
+	require.Len(t, next.Status().Members, 3)
+
+	c.apply(next, "b=2")
+	c.waitValue(joining.ID(), "b", "2")
+	require.Empty(t, c.fsms[leader.ID()].get("b"))
+}
+
+func TestNode_RestartKeepsState(t *testing.T) {
+	t.Parallel()
+
+	c := newPersistentCluster(t, 3, 5)
+	leader := c.leader()
+	for i := 0; i < 10; i++ {
+		c.apply(leader, fmt.Sprintf("k%d=%d", i, i))
+	}
+	terms := make(map[string]uint64)
+	for id, n := range c.nodes {
+		c.waitValue(id, "k9", "9")
+		terms[id] = n.Status().Term
+	}
+
+	// the whole cluster goes down, only the saved state is left
+	for id := range c.nodes {
+		c.stops[id]()
+	}
+	for id := range terms {
+		n := c.restart(id)
+		require.GreaterOrEqual(t, n.Status().Term, terms[id])
+	}
+
+	leader = c.leader()
+	c.apply(leader, "k10=10")
+	for id := range c.nodes {
+		c.waitValue(id, "k10", "10")
+		require.Equal(t, 11, c.fsms[id].len())
+	}
+}
+
+func TestNode_PersistsVoteAndLog(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	open := func() *Node {
+		n := New(Config{
+			ID:                "n1",
+			Members:           []Member{{ID: "n1"}, {ID: "n2"}, {ID: "n3"}},
+			ElectionTimeout:   testElectionTimeout,
+			HeartbeatInterval: testHeartbeatInterval,
+		}, &kvFSM{data: make(map[string]string)}, NewInmemNetwork().Transport("n1"), slog.New(slog.NewTextHandler(io.Discard, nil)))
+		require.NoError(t, n.Persist(dir))
+		return n
+	}
+	appendEntry := func(n *Node, index uint64) {
+		resp, err := n.HandleAppendEntries(&AppendEntriesRequest{
+			Term: 5, LeaderID: "n2", PrevLogIndex: index - 1, PrevLogTerm: 5,
+			Entries: []Entry{{Index: index, Term: 5, Data: []byte(fmt.Sprintf("k%d=v", index))}},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Success)
+	}
+
+	n := open()
+	vote, err := n.HandleRequestVote(&RequestVoteRequest{Term: 5, CandidateID: "n2"})
+	require.NoError(t, err)
+	require.True(t, vote.VoteGranted)
+	appendEntry(n, 1)
+	n.stop()
+
+	// a crash in the middle of appending leaves a partial entry
+	f, err := os.OpenFile(filepath.Join(dir, logFile), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"Index":2,"Te`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	n = open()
+	require.Equal(t, uint64(5), n.Status().Term)
+	vote, err = n.HandleRequestVote(&RequestVoteRequest{Term: 5, CandidateID: "n3", LastLogIndex: 1, LastLogTerm: 5})
+	require.NoError(t, err)
+	require.False(t, vote.VoteGranted, "voted twice in the same term")
+	require.Equal(t, uint64(1), n.entries.lastIndex())
+	appendEntry(n, 2)
+	n.stop()
+
+	n = open()
+	require.Equal(t, uint64(2), n.entries.lastIndex())
+
+	// a member failing to save its state stops instead of answering
+	require.NoError(t, os.RemoveAll(dir))
+	_, err = n.HandleRequestVote(&RequestVoteRequest{Term: 6, CandidateID: "n3", LastLogIndex: 2, LastLogTerm: 5})
+	require.ErrorIs(t, err, ErrStopped)
+	_, err = n.HandleAppendEntries(&AppendEntriesRequest{Term: 6, LeaderID: "n3"})
+	require.ErrorIs(t, err, ErrStopped)
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+	require.ErrorContains(t, n.Run(ctx), "create temp file")
+}
@@ -0,0 +1,200 @@
+package raft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tmvrus/key-value-storage/internal/atomicfile"
+)
+
+// Files of the data directory.
+const (
+	stateFile    = "state.json"
+	logFile      = "log.jsonl"
+	snapshotFile = "snapshot.json"
+)
+
+// hardState is what a member must not forget once it answered an RPC.
+type hardState struct {
+	Term     uint64 `json:"term"`
+	VotedFor string `json:"voted_for,omitempty"`
+}
+
+// persistedSnapshot is the state machine as of the last compacted entry.
+type persistedSnapshot struct {
+	Index   uint64   `json:"index"`
+	Term    uint64   `json:"term"`
+	Members []Member `json:"members"`
+	Data    []byte   `json:"data"`
+}
+
+// savedState is the content of the data directory loaded on start.
+type savedState struct {
+	state    hardState
+	snapshot *persistedSnapshot
+	entries  []Entry
+}
+
+// diskStorage keeps the hard state, the log and the snapshot in a directory.
+// The log is a JSON line per entry, it is appended to and written anew only
+// when entries are truncated or compacted. All writes are synced.
+type diskStorage struct {
+	dir string
+	log *os.File
+}
+
+// openDiskStorage loads the state saved in the directory, creating the
+// directory if needed. A partially written last entry of the log is dropped,
+// as the entry has never been acknowledged.
+func openDiskStorage(dir string) (*diskStorage, savedState, error) {
+	var saved savedState
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, saved, fmt.Errorf("create raft data dir: %w", err)
+	}
+
+	s := &diskStorage{dir: dir}
+	if _, err := readJSON(s.path(stateFile), &saved.state); err != nil {
+		return nil, saved, fmt.Errorf("read raft state: %w", err)
+	}
+
+	var snapshot persistedSnapshot
+	ok, err := readJSON(s.path(snapshotFile), &snapshot)
+	if err != nil {
+		return nil, saved, fmt.Errorf("read raft snapshot: %w", err)
+	}
+	if ok {
+		saved.snapshot = &snapshot
+	}
+
+	if saved.entries, err = s.readLog(); err != nil {
+		return nil, saved, fmt.Errorf("read raft log: %w", err)
+	}
+	if err = s.openLog(); err != nil {
+		return nil, saved, err
+	}
+	return s, saved, nil
+}
+
+func (s *diskStorage) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+func (s *diskStorage) readLog() ([]Entry, error) {
+	data, err := os.ReadFile(s.path(logFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete < len(data) {
+		if err = os.Truncate(s.path(logFile), int64(complete)); err != nil {
+			return nil, fmt.Errorf("drop partial entry: %w", err)
+		}
+	}
+
+	if complete == 0 {
+		return nil, nil
+	}
+
+	var entries []Entry
+	for _, line := range bytes.Split(data[:complete-1], []byte{'\n'}) {
+		var e Entry
+		if err = json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("decode entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (s *diskStorage) openLog() error {
+	f, err := os.OpenFile(s.path(logFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open raft log: %w", err)
+	}
+	s.log = f
+	return nil
+}
+
+func (s *diskStorage) saveState(st hardState) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("encode raft state: %w", err)
+	}
+	return atomicfile.Write(s.path(stateFile), data)
+}
+
+func (s *diskStorage) saveSnapshot(snapshot persistedSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("encode raft snapshot: %w", err)
+	}
+	return atomicfile.Write(s.path(snapshotFile), data)
+}
+
+// appendLog adds entries to the end of the log with a single write.
+func (s *diskStorage) appendLog(entries []Entry) error {
+	data, err := encodeEntries(entries)
+	if err != nil {
+		return err
+	}
+	if _, err = s.log.Write(data); err != nil {
+		return fmt.Errorf("write raft log: %w", err)
+	}
+	if err = s.log.Sync(); err != nil {
+		return fmt.Errorf("sync raft log: %w", err)
+	}
+	return nil
+}
+
+// rewriteLog replaces the log with the entries.
+func (s *diskStorage) rewriteLog(entries []Entry) error {
+	data, err := encodeEntries(entries)
+	if err != nil {
+		return err
+	}
+	if err = atomicfile.Write(s.path(logFile), data); err != nil {
+		return err
+	}
+
+	// the open file is the replaced one now
+	if err = s.log.Close(); err != nil {
+		return fmt.Errorf("close raft log: %w", err)
+	}
+	return s.openLog()
+}
+
+func (s *diskStorage) close() error {
+	return s.log.Close()
+}
+
+func encodeEntries(entries []Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return nil, fmt.Errorf("encode raft entry %d: %w", e.Index, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// readJSON decodes the file, false when it does not exist.
+func readJSON(path string, v any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
@@ -0,0 +1,64 @@
+package raft
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrUnreachable = errors.New("member is unreachable")
+
+type RequestVoteRequest struct {
+	Term         uint64
+	CandidateID  string
+	LastLogIndex uint64
+	LastLogTerm  uint64
+}
+
+type RequestVoteResponse struct {
+	Term        uint64
+	VoteGranted bool
+}
+
+type AppendEntriesRequest struct {
+	Term         uint64
+	LeaderID     string
+	PrevLogIndex uint64
+	PrevLogTerm  uint64
+	Entries      []Entry
+	LeaderCommit uint64
+}
+
+type AppendEntriesResponse struct {
+	Term    uint64
+	Success bool
+	// ConflictIndex is the index the leader should continue replication from on failure.
+	ConflictIndex uint64
+}
+
+type InstallSnapshotRequest struct {
+	Term              uint64
+	LeaderID          string
+	LastIncludedIndex uint64
+	LastIncludedTerm  uint64
+	Members           []Member
+	Data              []byte
+}
+
+type InstallSnapshotResponse struct {
+	Term uint64
+}
+
+// Transport delivers RPCs to other members of the cluster.
+type Transport interface {
+	RequestVote(ctx context.Context, target Member, req *RequestVoteRequest) (*RequestVoteResponse, error)
+	AppendEntries(ctx context.Context, target Member, req *AppendEntriesRequest) (*AppendEntriesResponse, error)
+	InstallSnapshot(ctx context.Context, target Member, req *InstallSnapshotRequest) (*InstallSnapshotResponse, error)
+}
+
+// Handler processes RPCs received by the transport, it is implemented by Node.
+// An error means the member can not answer, like when it is stopped.
+type Handler interface {
+	HandleRequestVote(req *RequestVoteRequest) (*RequestVoteResponse, error)
+	HandleAppendEntries(req *AppendEntriesRequest) (*AppendEntriesResponse, error)
+	HandleInstallSnapshot(req *InstallSnapshotRequest) (*InstallSnapshotResponse, error)
+}
@@ -0,0 +1,88 @@
+package raft
+
+import (
+	"context"
+	"sync"
+)
+
+// InmemNetwork connects nodes of the same process, it allows to cut members off
+// the network to simulate failures and partitions.
+type InmemNetwork struct {
+	lock         sync.RWMutex
+	handlers     map[string]Handler
+	disconnected map[string]bool
+}
+
+func NewInmemNetwork() *InmemNetwork {
+	return &InmemNetwork{
+		handlers:     make(map[string]Handler),
+		disconnected: make(map[string]bool),
+	}
+}
+
+func (n *InmemNetwork) Register(id string, h Handler) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.handlers[id] = h
+}
+
+// Disconnect drops all RPCs sent to and from the member.
+func (n *InmemNetwork) Disconnect(id string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.disconnected[id] = true
+}
+
+func (n *InmemNetwork) Connect(id string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delete(n.disconnected, id)
+}
+
+// Transport returns the transport sending RPCs on behalf of the member.
+func (n *InmemNetwork) Transport(id string) Transport {
+	return inmemTransport{network: n, from: id}
+}
+
+func (n *InmemNetwork) handler(from, to string) (Handler, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	h, ok := n.handlers[to]
+	if !ok || n.disconnected[from] || n.disconnected[to] {
+		return nil, ErrUnreachable
+	}
+	return h, nil
+}
+
+type inmemTransport struct {
+	network *InmemNetwork
+	from    string
+}
+
+func (t inmemTransport) RequestVote(_ context.Context, target Member, req *RequestVoteRequest) (*RequestVoteResponse, error) {
+	h, err := t.network.handler(t.from, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleRequestVote(req)
+}
+
+func (t inmemTransport) AppendEntries(_ context.Context, target Member, req *AppendEntriesRequest) (*AppendEntriesResponse, error) {
+	h, err := t.network.handler(t.from, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleAppendEntries(req)
+}
+
+func (t inmemTransport) InstallSnapshot(_ context.Context, target Member, req *InstallSnapshotRequest) (*InstallSnapshotResponse, error) {
+	h, err := t.network.handler(t.from, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleInstallSnapshot(req)
+}
@@ -0,0 +1,145 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/netaddr"
+)
+
+const rpcServiceName = "Raft"
+
+// TCPTransport sends RPCs to member addresses with net/rpc, connections are
+// reused. Addresses are host:port, tcp://host:port or unix:///path/to.sock.
+type TCPTransport struct {
+	dialTimeout time.Duration
+
+	lock    sync.Mutex
+	clients map[string]*rpc.Client
+}
+
+func NewTCPTransport(dialTimeout time.Duration) *TCPTransport {
+	return &TCPTransport{
+		dialTimeout: dialTimeout,
+		clients:     make(map[string]*rpc.Client),
+	}
+}
+
+// Serve accepts RPCs from the listener and passes them to the handler until
+// the listener is closed.
+func Serve(l net.Listener, h Handler) {
+	s := rpc.NewServer()
+	if err := s.RegisterName(rpcServiceName, &rpcService{handler: h}); err != nil {
+		panic(fmt.Sprintf("register raft rpc service: %s", err.Error()))
+	}
+	s.Accept(l)
+}
+
+func (t *TCPTransport) RequestVote(ctx context.Context, target Member, req *RequestVoteRequest) (*RequestVoteResponse, error) {
+	resp := &RequestVoteResponse{}
+	return resp, t.call(ctx, target.Address, "RequestVote", req, resp)
+}
+
+func (t *TCPTransport) AppendEntries(ctx context.Context, target Member, req *AppendEntriesRequest) (*AppendEntriesResponse, error) {
+	resp := &AppendEntriesResponse{}
+	return resp, t.call(ctx, target.Address, "AppendEntries", req, resp)
+}
+
+func (t *TCPTransport) InstallSnapshot(ctx context.Context, target Member, req *InstallSnapshotRequest) (*InstallSnapshotResponse, error) {
+	resp := &InstallSnapshotResponse{}
+	return resp, t.call(ctx, target.Address, "InstallSnapshot", req, resp)
+}
+
+// Close closes all connections.
+func (t *TCPTransport) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for addr, c := range t.clients {
+		_ = c.Close()
+		delete(t.clients, addr)
+	}
+}
+
+func (t *TCPTransport) call(ctx context.Context, addr, method string, req, resp any) error {
+	c, err := t.client(addr)
+	if err != nil {
+		return err
+	}
+
+	call := c.Go(rpcServiceName+"."+method, req, resp, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			t.drop(addr, c)
+			return fmt.Errorf("call %s: %w", method, call.Error)
+		}
+		return nil
+	case <-ctx.Done():
+		t.drop(addr, c)
+		return ctx.Err()
+	}
+}
+
+func (t *TCPTransport) client(addr string) (*rpc.Client, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if c, ok := t.clients[addr]; ok {
+		return c, nil
+	}
+
+	conn, err := netaddr.Dial(addr, t.dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+
+	c := rpc.NewClient(conn)
+	t.clients[addr] = c
+	return c, nil
+}
+
+func (t *TCPTransport) drop(addr string, c *rpc.Client) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.clients[addr] == c {
+		delete(t.clients, addr)
+		_ = c.Close()
+	}
+}
+
+type rpcService struct {
+	handler Handler
+}
+
+func (s *rpcService) RequestVote(req *RequestVoteRequest, resp *RequestVoteResponse) error {
+	r, err := s.handler.HandleRequestVote(req)
+	if err != nil {
+		return err
+	}
+	*resp = *r
+	return nil
+}
+
+func (s *rpcService) AppendEntries(req *AppendEntriesRequest, resp *AppendEntriesResponse) error {
+	r, err := s.handler.HandleAppendEntries(req)
+	if err != nil {
+		return err
+	}
+	*resp = *r
+	return nil
+}
+
+func (s *rpcService) InstallSnapshot(req *InstallSnapshotRequest, resp *InstallSnapshotResponse) error {
+	r, err := s.handler.HandleInstallSnapshot(req)
+	if err != nil {
+		return err
+	}
+	*resp = *r
+	return nil
+}
@@ -0,0 +1,98 @@
+package raft
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPTransport(t *testing.T) {
+	t.Parallel()
+
+	var (
+		members   []Member
+		listeners []net.Listener
+	)
+	for _, id := range []string{"n1", "n2", "n3"} {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = l.Close() })
+
+		listeners = append(listeners, l)
+		members = append(members, Member{ID: id, Address: l.Addr().String()})
+	}
+
+	var nodes []*Node
+	fsms := make(map[string]*kvFSM)
+	for i, m := range members {
+		transport := NewTCPTransport(testElectionTimeout)
+		t.Cleanup(transport.Close)
+
+		fsm := &kvFSM{data: make(map[string]string)}
+		n := New(Config{
+			ID:                m.ID,
+			Members:           members,
+			ElectionTimeout:   testElectionTimeout,
+			HeartbeatInterval: testHeartbeatInterval,
+		}, fsm, transport, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		go Serve(listeners[i], n)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go func() { _ = n.Run(ctx) }()
+
+		nodes = append(nodes, n)
+		fsms[m.ID] = fsm
+	}
+
+	var leader *Node
+	require.Eventually(t, func() bool {
+		for _, n := range nodes {
+			if n.Status().State == Leader {
+				leader = n
+				return true
+			}
+		}
+		return false
+	}, waitTimeout, waitTick)
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+	_, err := leader.Apply(ctx, []byte("a=1"))
+	require.NoError(t, err)
+
+	for id, fsm := range fsms {
+		require.Eventually(t, func() bool { return fsm.get("a") == "1" }, waitTimeout, waitTick, id)
+	}
+}
+
+func TestTCPTransport_UnixSocket(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "raft.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	n := New(Config{
+		ID:                "n1",
+		Members:           []Member{{ID: "n1"}, {ID: "n2"}},
+		ElectionTimeout:   testElectionTimeout,
+		HeartbeatInterval: testHeartbeatInterval,
+	}, &kvFSM{data: make(map[string]string)}, NewInmemNetwork().Transport("n1"), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	go Serve(l, n)
+
+	transport := NewTCPTransport(testElectionTimeout)
+	t.Cleanup(transport.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+	resp, err := transport.RequestVote(ctx, Member{ID: "n1", Address: "unix://" + socket}, &RequestVoteRequest{Term: 1, CandidateID: "n2"})
+	require.NoError(t, err)
+	require.True(t, resp.VoteGranted)
+}
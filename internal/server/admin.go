@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sync/atomic"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+// adminServer serves probes of the orchestrator, profiling and the config.
+type adminServer struct {
+	log *slog.Logger
+	// ready is set once the server accepts sessions and cleared on shutdown.
+	ready *atomic.Bool
+	// config returns the config to dump, passwords are redacted before.
+	config func() *config.Config
+}
+
+func (s adminServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /readyz", s.readyz)
+	mux.HandleFunc("GET /config", s.dumpConfig)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// healthz reports the process is alive, it never fails.
+func (s adminServer) healthz(w http.ResponseWriter, _ *http.Request) {
+	s.writeText(w, http.StatusOK, "ok")
+}
+
+func (s adminServer) readyz(w http.ResponseWriter, _ *http.Request) {
+	if !s.ready.Load() {
+		s.writeText(w, http.StatusServiceUnavailable, "not ready")
+		return
+	}
+	s.writeText(w, http.StatusOK, "ok")
+}
+
+func (s adminServer) dumpConfig(w http.ResponseWriter, _ *http.Request) {
+	data, err := s.config().Redacted().Marshal()
+	if err != nil {
+		s.log.Error("failed to marshal config", "error", err.Error())
+		s.writeText(w, http.StatusInternalServerError, "failed to marshal config")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	if _, err := w.Write(data); err != nil {
+		s.log.Error("failed to write config", "error", err.Error())
+	}
+}
+
+func (s adminServer) writeText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(text + "\n")); err != nil {
+		s.log.Error("failed to write response", "error", err.Error())
+	}
+}
+
+// serve serves the listener until ctx is done. Profiles take long, so unlike
+// the gateway the server has no read or write timeouts.
+func (s adminServer) serve(ctx context.Context, l net.Listener) {
+	srv := &http.Server{Handler: s.routes()}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.log.Error("failed to shutdown admin server", "error", err.Error())
+		}
+	}()
+
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.Error("admin server stopped", "error", err.Error())
+	}
+}
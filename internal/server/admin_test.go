@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+func TestAdminServer(t *testing.T) {
+	t.Parallel()
+
+	get := func(t *testing.T, url string) (int, string) {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	cfg := config.NewConfigWithDefaults()
+	cfg.Auth.Users = []config.User{{Name: "alice", Password: "secret"}}
+	a := adminServer{
+		log:    slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		ready:  &atomic.Bool{},
+		config: func() *config.Config { return cfg },
+	}
+	srv := httptest.NewServer(a.routes())
+	t.Cleanup(srv.Close)
+
+	status, body := get(t, srv.URL+"/healthz")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "ok\n", body)
+
+	status, _ = get(t, srv.URL+"/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	a.ready.Store(true)
+	status, _ = get(t, srv.URL+"/readyz")
+	require.Equal(t, http.StatusOK, status)
+
+	status, body = get(t, srv.URL+"/config")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "name: alice")
+	require.Contains(t, body, "password: (redacted)")
+	require.NotContains(t, body, "secret")
+	require.Equal(t, "secret", cfg.Auth.Users[0].Password)
+
+	status, body = get(t, srv.URL+"/debug/pprof/")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "goroutine")
+}
+
+func TestApp_RunAdmin(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.NewConfigWithDefaults()
+	cfg.Network.Address = findFreePort(t)
+	cfg.Admin.Enabled = true
+	cfg.Admin.Address = findFreePort(t)
+
+	ready := func() int {
+		resp, err := http.Get("http://" + cfg.Admin.Address + "/readyz")
+		if err != nil {
+			return 0
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	srv := New(cfg, nil, slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- srv.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return ready() == http.StatusOK }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.True(t, errors.Is(<-stopped, context.Canceled))
+	require.False(t, srv.ready.Load())
+	require.Eventually(t, func() bool { return ready() == 0 }, time.Second, 10*time.Millisecond)
+}
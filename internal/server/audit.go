@@ -0,0 +1,42 @@
+package server
+
+import (
+	"slices"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/audit"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// writeAudit records the mutating or admin command executed at the given
+// time, a failed write is logged as the command has been executed already.
+func (a handler) writeAudit(start time.Time, c domain.Command, err error) {
+	if a.audit == nil || !parser.Mutating(c.Type) && !c.Admin() {
+		return
+	}
+
+	keys := c.Keys()
+	r := audit.Record{
+		Time:    start,
+		Client:  a.session.client,
+		User:    a.session.stats.user,
+		Command: string(c.Type),
+		Keys:    keys,
+		Args:    withoutKeys(displayFields(c)[1:], keys),
+		Err:     err,
+	}
+	if werr := a.audit.Write(r); werr != nil {
+		a.log.Error("failed to write audit record", "error", werr.Error())
+	}
+}
+
+// withoutKeys removes every key from the fields once.
+func withoutKeys(fields, keys []string) []string {
+	for _, k := range keys {
+		if i := slices.Index(fields, k); i >= 0 {
+			fields = slices.Delete(fields, i, i+1)
+		}
+	}
+	return fields
+}
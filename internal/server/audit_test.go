@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/audit"
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Audit(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.NewConfigWithDefaults()
+	cfg.Audit.Output = filepath.Join(t.TempDir(), "audit.log")
+	cfg.Audit.Values = config.AuditValuesInclude
+	l, err := audit.Open(cfg)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	storMock := NewMockstorage(ctrl)
+	storMock.EXPECT().Get(gomock.Any(), "a").Return("1", nil)
+	storMock.EXPECT().MSet(gomock.Any(), []domain.KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "a"}}).Return(nil)
+	storMock.EXPECT().Delete(gomock.Any(), "a").Return(errors.New("disk failure"))
+
+	h := newHandler(slog.New(slog.NewJSONHandler(os.Stdout, nil)), storMock, nil, handlerConfig{})
+	h.audit = l
+	h.users = map[string]string{"alice": "secret"}
+	h.session.client = "10.0.0.1:5000"
+
+	ctx := context.Background()
+	for _, c := range []domain.Command{
+		{Type: domain.CommandAuth, Args: []string{"alice", "secret"}},
+		{Type: domain.CommandGet, Key: "a"},
+		{Type: domain.CommandMSet, Args: []string{"a", "1", "b", "a"}},
+		{Type: domain.CommandDelete, Key: "a"},
+	} {
+		_, _ = h.doCmd(ctx, c)
+	}
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(cfg.Audit.Output)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 3)
+
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+		require.Equal(t, "10.0.0.1:5000", records[i]["client"])
+		require.Equal(t, "alice", records[i]["user"])
+	}
+
+	require.Equal(t, "AUTH", records[0]["command"])
+	require.Equal(t, []any{"alice", redacted}, records[0]["args"])
+	require.Equal(t, "MSET", records[1]["command"])
+	require.Equal(t, []any{"a", "b"}, records[1]["keys"])
+	require.Equal(t, []any{"1", "a"}, records[1]["args"])
+	require.Equal(t, "ok", records[1]["result"])
+	require.Equal(t, "DELETE", records[2]["command"])
+	require.Equal(t, "error", records[2]["result"])
+	require.Equal(t, "disk failure", records[2]["error"])
+}
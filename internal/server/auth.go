@@ -0,0 +1,57 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const redacted = "(redacted)"
+
+var (
+	errInvalidCredentials = errors.New("invalid user name or password")
+	errAuthRequired       = errors.New("authentication required")
+)
+
+func newUsers(users []config.User) map[string]string {
+	m := make(map[string]string, len(users))
+	for _, u := range users {
+		m[u.Name] = u.Password
+	}
+	return m
+}
+
+// auth identifies the session as the user, a session may switch users.
+func (a handler) auth(name, password string) error {
+	expected, ok := a.users[name]
+	if !ok || subtle.ConstantTimeCompare([]byte(expected), []byte(password)) != 1 {
+		return errInvalidCredentials
+	}
+
+	if a.limiter != nil && a.session.stats.user != name {
+		a.limiter.releaseUser(a.session.userLimit)
+		a.session.userLimit = a.limiter.acquireUser(name)
+	}
+
+	a.session.stats.lock.Lock()
+	a.session.stats.user = name
+	a.session.stats.lock.Unlock()
+	return nil
+}
+
+// authorized reports whether the session may run the command: admin commands
+// require AUTH once users are configured.
+func (a handler) authorized(c domain.Command) bool {
+	return len(a.users) == 0 || c.Type == domain.CommandAuth || !c.Admin() || a.session.stats.user != ""
+}
+
+// displayFields returns the command fields to show in logs with the password hidden.
+func displayFields(c domain.Command) []string {
+	fields := c.Fields()
+	if c.Type == domain.CommandAuth {
+		fields[len(fields)-1] = redacted
+	}
+	return fields
+}
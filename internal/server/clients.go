@@ -0,0 +1,204 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+var errClientsTextClient = errors.New("CLIENT is supported by text protocol sessions only")
+
+// clientRegistry keeps live text protocol sessions.
+type clientRegistry struct {
+	lock     sync.Mutex
+	lastID   uint64
+	sessions map[uint64]*session
+}
+
+// clientStats are the session details other sessions read with CLIENT LIST.
+type clientStats struct {
+	lock        sync.Mutex
+	name        string
+	user        string
+	createdAt   time.Time
+	activeAt    time.Time
+	lastCommand domain.CommandType
+
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+}
+
+type clientInfo struct {
+	id          uint64
+	addr        string
+	name        string
+	user        string
+	age         time.Duration
+	idle        time.Duration
+	lastCommand domain.CommandType
+	bytesIn     int64
+	bytesOut    int64
+}
+
+func newClientRegistry() *clientRegistry {
+	return &clientRegistry{sessions: make(map[uint64]*session)}
+}
+
+// register assigns the session ID and returns the context canceled when the
+// session is killed or unregistered. Killing also interrupts a pending read of
+// the connection, so the handler goroutine notices the context and closes the
+// session.
+func (r *clientRegistry) register(ctx context.Context, s *session, conn socket) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.kill = func() {
+		cancel()
+		_ = conn.SetReadDeadline(time.Now())
+	}
+
+	now := time.Now()
+	s.stats.lock.Lock()
+	s.stats.createdAt = now
+	s.stats.activeAt = now
+	s.stats.lock.Unlock()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.lastID++
+	s.id = r.lastID
+	r.sessions[s.id] = s
+	return ctx
+}
+
+func (r *clientRegistry) unregister(s *session) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.sessions, s.id)
+	s.cancel()
+}
+
+// list returns live sessions ordered by ID.
+func (r *clientRegistry) list() []clientInfo {
+	r.lock.Lock()
+	sessions := make([]*session, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+	r.lock.Unlock()
+
+	slices.SortFunc(sessions, func(a, b *session) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	now := time.Now()
+	res := make([]clientInfo, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, s.info(now))
+	}
+	return res
+}
+
+// kill closes sessions matching the filter and returns their number.
+func (r *clientRegistry) kill(match func(*session) bool) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n := 0
+	for _, s := range r.sessions {
+		if match(s) {
+			s.kill()
+			n++
+		}
+	}
+	return n
+}
+
+// touch records the command the session is about to execute.
+func (s *session) touch(t domain.CommandType) {
+	s.stats.lock.Lock()
+	defer s.stats.lock.Unlock()
+
+	s.stats.activeAt = time.Now()
+	s.stats.lastCommand = t
+}
+
+func (s *session) info(now time.Time) clientInfo {
+	s.stats.lock.Lock()
+	defer s.stats.lock.Unlock()
+
+	return clientInfo{
+		id:          s.id,
+		addr:        s.client,
+		name:        s.stats.name,
+		user:        s.stats.user,
+		age:         now.Sub(s.stats.createdAt),
+		idle:        now.Sub(s.stats.activeAt),
+		lastCommand: s.stats.lastCommand,
+		bytesIn:     s.stats.bytesIn.Load(),
+		bytesOut:    s.stats.bytesOut.Load(),
+	}
+}
+
+func (a handler) doClientCmd(_ context.Context, c domain.Command) (string, error) {
+	if a.clients == nil {
+		return "", errClientsTextClient
+	}
+
+	switch c.Args[0] {
+	case domain.ClientList:
+		return clientListResult(a.clients.list()), nil
+	case domain.ClientKill:
+		match := func(s *session) bool { return s.client == c.Args[2] }
+		if c.Args[1] == domain.ClientKillID {
+			id, err := strconv.ParseUint(c.Args[2], 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid client id: %w", err)
+			}
+			match = func(s *session) bool { return s.id == id }
+		}
+		return strconv.Itoa(a.clients.kill(match)), nil
+	case domain.ClientSetName:
+		a.session.stats.lock.Lock()
+		a.session.stats.name = c.Args[1]
+		a.session.stats.lock.Unlock()
+		return "", nil
+	case domain.ClientGetName:
+		if name := a.session.info(time.Now()).name; name != "" {
+			return name, nil
+		}
+		return nilResult, nil
+	default:
+		return "", fmt.Errorf("invalid client subcommand: %q", c.Args[0])
+	}
+}
+
+// clientListResult lists a line per session of space separated field=value
+// pairs, ages are in seconds.
+func clientListResult(clients []clientInfo) string {
+	lines := make([]string, 0, len(clients))
+	for _, c := range clients {
+		lines = append(lines, strings.Join([]string{
+			"id=" + strconv.FormatUint(c.id, 10),
+			"addr=" + c.addr,
+			"name=" + c.name,
+			"user=" + c.user,
+			"age=" + strconv.FormatInt(int64(c.age.Seconds()), 10),
+			"idle=" + strconv.FormatInt(int64(c.idle.Seconds()), 10),
+			"cmd=" + strings.ToLower(string(c.lastCommand)),
+			"in=" + strconv.FormatInt(c.bytesIn, 10),
+			"out=" + strconv.FormatInt(c.bytesOut, 10),
+		}, " "))
+	}
+	return multiResult(lines)
+}
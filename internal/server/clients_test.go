@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestHandler_Client(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	clients := newClientRegistry()
+
+	type testClient struct {
+		conn   net.Conn
+		reader *bufio.Reader
+		done   chan struct{}
+	}
+
+	call := func(t *testing.T, c testClient, in string) string {
+		_, err := c.conn.Write([]byte(in + "\n"))
+		require.NoError(t, err)
+		line, err := c.reader.ReadString('\n')
+		require.NoError(t, err)
+		return strings.TrimSuffix(line, "\n")
+	}
+
+	start := func(t *testing.T, address string) testClient {
+		server, client := net.Pipe()
+		t.Cleanup(func() { _ = client.Close() })
+
+		h := newHandler(log, nil, server, handlerConfig{timeout: time.Minute, bufferSize: 1024})
+		h.clients = clients
+		h.session.client = address
+
+		c := testClient{conn: client, reader: bufio.NewReader(client), done: make(chan struct{})}
+		go func() {
+			h.startHandling(context.Background())
+			_ = server.Close()
+			close(c.done)
+		}()
+
+		// the first reply means the session is registered
+		require.Equal(t, "(nil)", call(t, c, "CLIENT GETNAME"))
+		return c
+	}
+
+	a := start(t, "10.0.0.1:5000")
+	b := start(t, "10.0.0.2:5000")
+	c := start(t, "10.0.0.3:5000")
+
+	require.Equal(t, "OK", call(t, a, "CLIENT SETNAME worker"))
+	require.Equal(t, "worker", call(t, a, "CLIENT GETNAME"))
+
+	require.Equal(t, "3", call(t, a, "CLIENT LIST"))
+	lines := make([]string, 3)
+	for i := range lines {
+		line, err := a.reader.ReadString('\n')
+		require.NoError(t, err)
+		lines[i] = line
+	}
+	require.Regexp(t, `^id=1 addr=10\.0\.0\.1:5000 name=worker user= age=\d+ idle=\d+ cmd=client in=\d+ out=\d+\n$`, lines[0])
+	require.Regexp(t, `^id=2 addr=10\.0\.0\.2:5000 name= user= age=\d+ idle=\d+ cmd=client `, lines[1])
+	require.Regexp(t, `^id=3 addr=10\.0\.0\.3:5000 name= user= age=\d+ idle=\d+ cmd=client in=15 out=6\n$`, lines[2])
+
+	require.Equal(t, "1", call(t, a, "CLIENT KILL ID 2"))
+	<-b.done
+	_, err := b.reader.ReadString('\n')
+	require.ErrorIs(t, err, io.EOF)
+
+	require.Equal(t, "1", call(t, a, "CLIENT KILL ADDR 10.0.0.3:5000"))
+	<-c.done
+
+	require.Equal(t, "0", call(t, a, "CLIENT KILL ID 2"))
+	require.Len(t, clients.list(), 1)
+
+	// killing itself replies before the session is closed
+	require.Equal(t, "1", call(t, a, "CLIENT KILL ID 1"))
+	<-a.done
+	require.Empty(t, clients.list())
+
+	_, err = newHandler(log, nil, nil, handlerConfig{}).doCmd(context.Background(), domain.Command{
+		Type: domain.CommandClient, Args: []string{domain.ClientList},
+	})
+	require.ErrorIs(t, err, errClientsTextClient)
+}
+
+func TestClientRegistry_Unregister(t *testing.T) {
+	t.Parallel()
+
+	clients := newClientRegistry()
+	s := &session{}
+	ctx := clients.register(context.Background(), s, nil)
+	require.NoError(t, ctx.Err())
+
+	// a session ending normally releases its context
+	clients.unregister(s)
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.Empty(t, clients.list())
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const (
+	clusterStateFile     = "cluster-state.json"
+	migrationJournalFile = "migration.json"
+)
+
+var errClusterDisabled = errors.New("cluster is disabled")
+
+func newCluster(cfg *config.Config) (*cluster.Cluster, error) {
+	nodes := make([]cluster.NodeSlots, 0, len(cfg.Cluster.Nodes))
+	for _, n := range cfg.Cluster.Nodes {
+		ns := cluster.NodeSlots{Node: cluster.Node{ID: n.ID, Address: n.Address}}
+		for _, s := range n.Slots {
+			r, err := cluster.ParseSlotRange(s)
+			if err != nil {
+				return nil, fmt.Errorf("node %q: %w", n.ID, err)
+			}
+			ns.Slots = append(ns.Slots, r)
+		}
+		nodes = append(nodes, ns)
+	}
+
+	return cluster.New(cfg.Cluster.NodeID, nodes)
+}
+
+// newMigratingCluster builds the cluster and the migrator moving its slots,
+// both restore their state from the state directory if it is configured.
+func newMigratingCluster(cfg *config.Config, st storage, l *slog.Logger) (*cluster.Cluster, *cluster.Migrator, error) {
+	c, err := newCluster(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster config: %w", err)
+	}
+
+	var journal string
+	if dir := cfg.Cluster.StateDir; dir != "" {
+		if err = c.Persist(filepath.Join(dir, clusterStateFile)); err != nil {
+			return nil, nil, fmt.Errorf("cluster state: %w", err)
+		}
+		journal = filepath.Join(dir, migrationJournalFile)
+	}
+
+	dial := newPeerDialer(cfg.Network.MaxMessageSize.Int(), l)
+	m, err := cluster.NewMigrator(c, st, dial, journal, cfg.Cluster.MigrationBatchSize, l)
+	if err != nil {
+		return nil, nil, fmt.Errorf("slot migrator: %w", err)
+	}
+	if p := m.Progress(); p.State == cluster.MigrationInterrupted {
+		l.Warn("slot migration was interrupted, resume it with CLUSTER MIGRATE RESUME",
+			"range", p.Range.String(), "target", p.Target, "slot", p.Slot)
+	}
+
+	return c, m, nil
+}
+
+// route checks the command keys belong to a single slot served by this node.
+// The returned func leaves the slot and must be called after the command is
+// executed, so a migration never moves keys from under a running command.
+// Blocking commands do not stay in the slot as they could stall a migration.
+func (a handler) route(ctx context.Context, c domain.Command, asking bool) (func(), error) {
+	keys := c.Keys()
+	if len(keys) == 0 {
+		return func() {}, nil
+	}
+
+	slot := cluster.KeySlot(keys[0])
+	for _, k := range keys[1:] {
+		if cluster.KeySlot(k) != slot {
+			return nil, cluster.ErrCrossSlot
+		}
+	}
+
+	release := func() {}
+	if !c.Blocking() {
+		release = a.cluster.Enter(slot)
+	}
+
+	err := a.cluster.Route(slot, asking, func() bool {
+		for _, k := range keys {
+			if ok, err := a.storage.Exists(ctx, k); err != nil || !ok {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (a handler) doClusterCmd(ctx context.Context, c domain.Command) (string, error) {
+	if c.Args[0] == domain.ClusterKeySlot {
+		return strconv.Itoa(cluster.KeySlot(c.Args[1])), nil
+	}
+	if a.cluster == nil {
+		return "", errClusterDisabled
+	}
+
+	switch c.Args[0] {
+	case domain.ClusterSlots:
+		return clusterSlotsResult(a.cluster.Slots()), nil
+	case domain.ClusterSetSlot:
+		return "", a.setSlot(c.Args[1:])
+	case domain.ClusterMigrate:
+		return a.migrate(c.Args[1:])
+	default:
+		return "", fmt.Errorf("invalid cluster subcommand: %q", c.Args[0])
+	}
+}
+
+// clusterSlotsResult gives a line per slot range: start, end, node ID and address.
+func clusterSlotsResult(nodes []cluster.NodeSlots) string {
+	var lines []string
+	for _, n := range nodes {
+		for _, r := range n.Slots {
+			lines = append(lines, fmt.Sprintf("%d %d %s %s", r.Start, r.End, n.ID, n.Address))
+		}
+	}
+	return multiResult(lines)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Cluster(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	// "foo" hashes to slot 12182, "bar" to 5061
+	newTestCluster := func(t *testing.T) *cluster.Cluster {
+		c, err := cluster.New("a", []cluster.NodeSlots{
+			{Node: cluster.Node{ID: "a", Address: "10.0.0.1:3223"}, Slots: []cluster.SlotRange{{Start: 0, End: 8191}}},
+			{Node: cluster.Node{ID: "b", Address: "10.0.0.2:3223"}, Slots: []cluster.SlotRange{{Start: 8192, End: 16383}}},
+		})
+		require.NoError(t, err)
+		return c
+	}
+
+	do := func(h handler, in string) (string, error) {
+		cmd, err := parser.Parse(in)
+		require.NoError(t, err)
+		return h.doCmd(context.Background(), cmd)
+	}
+
+	t.Run("serves owned slot", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Get(gomock.Any(), "bar").Return("1", nil)
+
+		h := newHandler(log, storMock, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+
+		out, err := do(h, "GET bar")
+		require.NoError(t, err)
+		require.Equal(t, "1", out)
+	})
+
+	t.Run("redirects to the owner", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHandler(log, nil, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+
+		_, err := do(h, "SET foo 1")
+		require.EqualError(t, err, "MOVED 12182 10.0.0.2:3223")
+	})
+
+	t.Run("rejects keys of different slots", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHandler(log, nil, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+
+		_, err := do(h, "MGET foo bar")
+		require.ErrorIs(t, err, cluster.ErrCrossSlot)
+	})
+
+	t.Run("asks to retry missing key of migrating slot", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Exists(gomock.Any(), "bar").Return(false, nil)
+
+		h := newHandler(log, storMock, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+		require.NoError(t, h.cluster.SetMigrating(5061, "b"))
+
+		_, err := do(h, "GET bar")
+		require.EqualError(t, err, "ASK 5061 10.0.0.2:3223")
+	})
+
+	t.Run("serves importing slot after ASKING only", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Set(gomock.Any(), "foo", "1").Return(nil)
+
+		h := newHandler(log, storMock, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+		require.NoError(t, h.cluster.SetImporting(12182, "b"))
+
+		out, err := do(h, "ASKING")
+		require.NoError(t, err)
+		require.Empty(t, out)
+
+		_, err = do(h, "SET foo 1")
+		require.NoError(t, err)
+
+		_, err = do(h, "SET foo 1")
+		require.EqualError(t, err, "MOVED 12182 10.0.0.2:3223")
+	})
+
+	t.Run("lists slots", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHandler(log, nil, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+
+		out, err := do(h, "CLUSTER SLOTS")
+		require.NoError(t, err)
+		require.Equal(t, "2\n0 8191 a 10.0.0.1:3223\n8192 16383 b 10.0.0.2:3223", out)
+
+		out, err = do(h, "CLUSTER KEYSLOT foo")
+		require.NoError(t, err)
+		require.Equal(t, "12182", out)
+	})
+
+	t.Run("cluster is disabled", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := do(newHandler(log, nil, nil, handlerConfig{}), "CLUSTER SLOTS")
+		require.ErrorIs(t, err, errClusterDisabled)
+	})
+
+	t.Run("sets slot states", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHandler(log, nil, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+
+		_, err := do(h, "CLUSTER SETSLOT 12182 IMPORTING b")
+		require.NoError(t, err)
+		_, err = do(h, "CLUSTER SETSLOT 12182 NODE a")
+		require.NoError(t, err)
+		_, err = do(h, "CLUSTER SETSLOT 5061 MIGRATING b")
+		require.NoError(t, err)
+		_, err = do(h, "CLUSTER SETSLOT 5061 STABLE")
+		require.NoError(t, err)
+
+		out, err := do(h, "CLUSTER SLOTS")
+		require.NoError(t, err)
+		require.Equal(t, "4\n0 8191 a 10.0.0.1:3223\n8192 12181 b 10.0.0.2:3223\n"+
+			"12182 12182 a 10.0.0.1:3223\n12183 16383 b 10.0.0.2:3223", out)
+
+		_, err = do(h, "CLUSTER SETSLOT 16384 NODE a")
+		require.Error(t, err)
+		_, err = do(h, "CLUSTER SETSLOT 1-2 NODE a")
+		require.Error(t, err)
+		_, err = do(h, "CLUSTER SETSLOT 1 NODE z")
+		require.ErrorIs(t, err, cluster.ErrUnknownNode)
+	})
+
+	t.Run("reports migration progress", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHandler(log, nil, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+
+		_, err := do(h, "CLUSTER MIGRATE STATUS")
+		require.ErrorIs(t, err, errClusterDisabled)
+
+		h.migrator, err = cluster.NewMigrator(h.cluster, nil, nil, "", 10, log)
+		require.NoError(t, err)
+
+		out, err := do(h, "CLUSTER MIGRATE STATUS")
+		require.NoError(t, err)
+		require.Equal(t, "1\nstate idle", out)
+
+		_, err = do(h, "CLUSTER MIGRATE RESUME")
+		require.ErrorIs(t, err, cluster.ErrNothingToResume)
+		_, err = do(h, "CLUSTER MIGRATE 10-5 b")
+		require.Error(t, err)
+		_, err = do(h, "CLUSTER MIGRATE 0-10 a")
+		require.ErrorIs(t, err, cluster.ErrInvalidMigrationDst)
+
+		require.Equal(t, "7\nstate failed\nrange 0-10\ntarget b\nslot 3\nslots_done 3\nkeys_moved 12\nerror boom", migrationResult(cluster.Progress{
+			State: cluster.MigrationFailed, Range: cluster.SlotRange{Start: 0, End: 10}, Target: "b",
+			Slot: 3, SlotsDone: 3, KeysMoved: 12, Error: "boom",
+		}))
+	})
+
+	t.Run("migration outlives the session", func(t *testing.T) {
+		t.Parallel()
+
+		migrated := make(chan context.Context, 1)
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Keys(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]string, error) {
+			migrated <- ctx
+			return nil, nil
+		})
+
+		h := newHandler(log, storMock, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+		dial := func(string) (cluster.Peer, error) { return nopPeer{}, nil }
+		var err error
+		h.migrator, err = cluster.NewMigrator(h.cluster, storMock, dial, "", 10, log)
+		require.NoError(t, err)
+
+		// the session is killed right after starting the migration
+		sessionCtx, kill := context.WithCancel(context.Background())
+		cmd, err := parser.Parse("CLUSTER MIGRATE 0-0 b")
+		require.NoError(t, err)
+		_, err = h.doCmd(sessionCtx, cmd)
+		require.NoError(t, err)
+		kill()
+
+		require.NoError(t, (<-migrated).Err())
+		require.Eventually(t, func() bool {
+			return h.migrator.Progress().State == cluster.MigrationDone
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("restores dumped keys", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().RestoreKey(gomock.Any(), "foo", []byte("data")).Return(nil)
+		storMock.EXPECT().RestoreKey(gomock.Any(), "{foo}.x", []byte("more")).Return(nil)
+
+		h := newHandler(log, storMock, nil, handlerConfig{})
+		h.cluster = newTestCluster(t)
+		require.NoError(t, h.cluster.SetImporting(12182, "b"))
+
+		_, err := do(h, "ASKING")
+		require.NoError(t, err)
+		out, err := do(h, "RESTORE foo ZGF0YQ== {foo}.x bW9yZQ==")
+		require.NoError(t, err)
+		require.Empty(t, out)
+
+		_, err = do(h, "RESTORE bar !!!")
+		require.Error(t, err)
+	})
+}
+
+// nopPeer accepts every migration command.
+type nopPeer struct{}
+
+func (nopPeer) SetSlot(context.Context, int, string, string) error { return nil }
+
+func (nopPeer) Restore(context.Context, []cluster.KeyDump) error { return nil }
+
+func (nopPeer) Close() error { return nil }
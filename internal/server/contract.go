@@ -1,9 +1,12 @@
-//go:generate go run go.uber.org/mock/mockgen@v0.4.0 -source=contract.go -destination=./contract_mock_test.go -package=app
+//go:generate go run go.uber.org/mock/mockgen@v0.4.0 -source=contract.go -destination=./contract_mock_test.go -package=server
 package server
 
 import (
+	"context"
 	"net"
 
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/internal/raft"
 	stor "github.com/tmvrus/key-value-storage/internal/storage"
 )
 
@@ -14,3 +17,11 @@ type storage interface {
 type socket interface {
 	net.Conn
 }
+
+// replicator executes mutating commands once the cluster commits them.
+type replicator interface {
+	Apply(ctx context.Context, c domain.Command) (string, error)
+	AddMember(ctx context.Context, m raft.Member) error
+	RemoveMember(ctx context.Context, id string) error
+	Status() raft.Status
+}
@@ -3,10 +3,10 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=contract.go -destination=./contract_mock_test.go -package=app
+//	mockgen -source=contract.go -destination=./contract_mock_test.go -package=server
 //
 
-// Package app is a generated GoMock package.
+// Package server is a generated GoMock package.
 package server
 
 import (
@@ -15,6 +15,8 @@ import (
 	reflect "reflect"
 	time "time"
 
+	domain "github.com/tmvrus/key-value-storage/internal/domain"
+	raft "github.com/tmvrus/key-value-storage/internal/raft"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +43,53 @@ func (m *Mockstorage) EXPECT() *MockstorageMockRecorder {
 	return m.recorder
 }
 
+// Append mocks base method.
+func (m *Mockstorage) Append(cxt context.Context, key, value string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", cxt, key, value)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockstorageMockRecorder) Append(cxt, key, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*Mockstorage)(nil).Append), cxt, key, value)
+}
+
+// BLPop mocks base method.
+func (m *Mockstorage) BLPop(cxt context.Context, keys []string, timeout time.Duration) (domain.KeyValue, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BLPop", cxt, keys, timeout)
+	ret0, _ := ret[0].(domain.KeyValue)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BLPop indicates an expected call of BLPop.
+func (mr *MockstorageMockRecorder) BLPop(cxt, keys, timeout any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BLPop", reflect.TypeOf((*Mockstorage)(nil).BLPop), cxt, keys, timeout)
+}
+
+// BRPop mocks base method.
+func (m *Mockstorage) BRPop(cxt context.Context, keys []string, timeout time.Duration) (domain.KeyValue, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BRPop", cxt, keys, timeout)
+	ret0, _ := ret[0].(domain.KeyValue)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BRPop indicates an expected call of BRPop.
+func (mr *MockstorageMockRecorder) BRPop(cxt, keys, timeout any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BRPop", reflect.TypeOf((*Mockstorage)(nil).BRPop), cxt, keys, timeout)
+}
+
 // Delete mocks base method.
 func (m *Mockstorage) Delete(cxt context.Context, key string) error {
 	m.ctrl.T.Helper()
@@ -55,6 +104,36 @@ func (mr *MockstorageMockRecorder) Delete(cxt, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*Mockstorage)(nil).Delete), cxt, key)
 }
 
+// Dump mocks base method.
+func (m *Mockstorage) Dump(cxt context.Context, key string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dump", cxt, key)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dump indicates an expected call of Dump.
+func (mr *MockstorageMockRecorder) Dump(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dump", reflect.TypeOf((*Mockstorage)(nil).Dump), cxt, key)
+}
+
+// Exists mocks base method.
+func (m *Mockstorage) Exists(cxt context.Context, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", cxt, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockstorageMockRecorder) Exists(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*Mockstorage)(nil).Exists), cxt, key)
+}
+
 // Get mocks base method.
 func (m *Mockstorage) Get(cxt context.Context, key string) (string, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +149,500 @@ func (mr *MockstorageMockRecorder) Get(cxt, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*Mockstorage)(nil).Get), cxt, key)
 }
 
+// GetRange mocks base method.
+func (m *Mockstorage) GetRange(cxt context.Context, key string, start, end int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRange", cxt, key, start, end)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRange indicates an expected call of GetRange.
+func (mr *MockstorageMockRecorder) GetRange(cxt, key, start, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRange", reflect.TypeOf((*Mockstorage)(nil).GetRange), cxt, key, start, end)
+}
+
+// GetSet mocks base method.
+func (m *Mockstorage) GetSet(cxt context.Context, key, value string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSet", cxt, key, value)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSet indicates an expected call of GetSet.
+func (mr *MockstorageMockRecorder) GetSet(cxt, key, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSet", reflect.TypeOf((*Mockstorage)(nil).GetSet), cxt, key, value)
+}
+
+// HDel mocks base method.
+func (m *Mockstorage) HDel(cxt context.Context, key string, fields []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HDel", cxt, key, fields)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HDel indicates an expected call of HDel.
+func (mr *MockstorageMockRecorder) HDel(cxt, key, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HDel", reflect.TypeOf((*Mockstorage)(nil).HDel), cxt, key, fields)
+}
+
+// HGet mocks base method.
+func (m *Mockstorage) HGet(cxt context.Context, key, field string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HGet", cxt, key, field)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HGet indicates an expected call of HGet.
+func (mr *MockstorageMockRecorder) HGet(cxt, key, field any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HGet", reflect.TypeOf((*Mockstorage)(nil).HGet), cxt, key, field)
+}
+
+// HGetAll mocks base method.
+func (m *Mockstorage) HGetAll(cxt context.Context, key string) ([]domain.KeyValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HGetAll", cxt, key)
+	ret0, _ := ret[0].([]domain.KeyValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HGetAll indicates an expected call of HGetAll.
+func (mr *MockstorageMockRecorder) HGetAll(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HGetAll", reflect.TypeOf((*Mockstorage)(nil).HGetAll), cxt, key)
+}
+
+// HIncrBy mocks base method.
+func (m *Mockstorage) HIncrBy(cxt context.Context, key, field string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HIncrBy", cxt, key, field, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HIncrBy indicates an expected call of HIncrBy.
+func (mr *MockstorageMockRecorder) HIncrBy(cxt, key, field, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HIncrBy", reflect.TypeOf((*Mockstorage)(nil).HIncrBy), cxt, key, field, delta)
+}
+
+// HKeys mocks base method.
+func (m *Mockstorage) HKeys(cxt context.Context, key string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HKeys", cxt, key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HKeys indicates an expected call of HKeys.
+func (mr *MockstorageMockRecorder) HKeys(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HKeys", reflect.TypeOf((*Mockstorage)(nil).HKeys), cxt, key)
+}
+
+// HLen mocks base method.
+func (m *Mockstorage) HLen(cxt context.Context, key string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HLen", cxt, key)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HLen indicates an expected call of HLen.
+func (mr *MockstorageMockRecorder) HLen(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HLen", reflect.TypeOf((*Mockstorage)(nil).HLen), cxt, key)
+}
+
+// HSet mocks base method.
+func (m *Mockstorage) HSet(cxt context.Context, key string, fields []domain.KeyValue) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HSet", cxt, key, fields)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HSet indicates an expected call of HSet.
+func (mr *MockstorageMockRecorder) HSet(cxt, key, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HSet", reflect.TypeOf((*Mockstorage)(nil).HSet), cxt, key, fields)
+}
+
+// HVals mocks base method.
+func (m *Mockstorage) HVals(cxt context.Context, key string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HVals", cxt, key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HVals indicates an expected call of HVals.
+func (mr *MockstorageMockRecorder) HVals(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HVals", reflect.TypeOf((*Mockstorage)(nil).HVals), cxt, key)
+}
+
+// IncrBy mocks base method.
+func (m *Mockstorage) IncrBy(cxt context.Context, key string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrBy", cxt, key, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrBy indicates an expected call of IncrBy.
+func (mr *MockstorageMockRecorder) IncrBy(cxt, key, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrBy", reflect.TypeOf((*Mockstorage)(nil).IncrBy), cxt, key, delta)
+}
+
+// IncrByFloat mocks base method.
+func (m *Mockstorage) IncrByFloat(cxt context.Context, key string, delta float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrByFloat", cxt, key, delta)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrByFloat indicates an expected call of IncrByFloat.
+func (mr *MockstorageMockRecorder) IncrByFloat(cxt, key, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrByFloat", reflect.TypeOf((*Mockstorage)(nil).IncrByFloat), cxt, key, delta)
+}
+
+// Keys mocks base method.
+func (m *Mockstorage) Keys(cxt context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Keys", cxt)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Keys indicates an expected call of Keys.
+func (mr *MockstorageMockRecorder) Keys(cxt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keys", reflect.TypeOf((*Mockstorage)(nil).Keys), cxt)
+}
+
+// LLen mocks base method.
+func (m *Mockstorage) LLen(cxt context.Context, key string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LLen", cxt, key)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LLen indicates an expected call of LLen.
+func (mr *MockstorageMockRecorder) LLen(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LLen", reflect.TypeOf((*Mockstorage)(nil).LLen), cxt, key)
+}
+
+// LPop mocks base method.
+func (m *Mockstorage) LPop(cxt context.Context, key string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LPop", cxt, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LPop indicates an expected call of LPop.
+func (mr *MockstorageMockRecorder) LPop(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LPop", reflect.TypeOf((*Mockstorage)(nil).LPop), cxt, key)
+}
+
+// LPush mocks base method.
+func (m *Mockstorage) LPush(cxt context.Context, key string, values []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LPush", cxt, key, values)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LPush indicates an expected call of LPush.
+func (mr *MockstorageMockRecorder) LPush(cxt, key, values any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LPush", reflect.TypeOf((*Mockstorage)(nil).LPush), cxt, key, values)
+}
+
+// LRange mocks base method.
+func (m *Mockstorage) LRange(cxt context.Context, key string, start, stop int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LRange", cxt, key, start, stop)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LRange indicates an expected call of LRange.
+func (mr *MockstorageMockRecorder) LRange(cxt, key, start, stop any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LRange", reflect.TypeOf((*Mockstorage)(nil).LRange), cxt, key, start, stop)
+}
+
+// LTrim mocks base method.
+func (m *Mockstorage) LTrim(cxt context.Context, key string, start, stop int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LTrim", cxt, key, start, stop)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LTrim indicates an expected call of LTrim.
+func (mr *MockstorageMockRecorder) LTrim(cxt, key, start, stop any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LTrim", reflect.TypeOf((*Mockstorage)(nil).LTrim), cxt, key, start, stop)
+}
+
+// MDelete mocks base method.
+func (m *Mockstorage) MDelete(cxt context.Context, keys []string) ([]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MDelete", cxt, keys)
+	ret0, _ := ret[0].([]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MDelete indicates an expected call of MDelete.
+func (mr *MockstorageMockRecorder) MDelete(cxt, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MDelete", reflect.TypeOf((*Mockstorage)(nil).MDelete), cxt, keys)
+}
+
+// MGet mocks base method.
+func (m *Mockstorage) MGet(cxt context.Context, keys []string) ([]*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MGet", cxt, keys)
+	ret0, _ := ret[0].([]*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MGet indicates an expected call of MGet.
+func (mr *MockstorageMockRecorder) MGet(cxt, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MGet", reflect.TypeOf((*Mockstorage)(nil).MGet), cxt, keys)
+}
+
+// MSet mocks base method.
+func (m *Mockstorage) MSet(cxt context.Context, pairs []domain.KeyValue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MSet", cxt, pairs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MSet indicates an expected call of MSet.
+func (mr *MockstorageMockRecorder) MSet(cxt, pairs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MSet", reflect.TypeOf((*Mockstorage)(nil).MSet), cxt, pairs)
+}
+
+// RPop mocks base method.
+func (m *Mockstorage) RPop(cxt context.Context, key string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RPop", cxt, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RPop indicates an expected call of RPop.
+func (mr *MockstorageMockRecorder) RPop(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RPop", reflect.TypeOf((*Mockstorage)(nil).RPop), cxt, key)
+}
+
+// RPush mocks base method.
+func (m *Mockstorage) RPush(cxt context.Context, key string, values []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RPush", cxt, key, values)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RPush indicates an expected call of RPush.
+func (mr *MockstorageMockRecorder) RPush(cxt, key, values any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RPush", reflect.TypeOf((*Mockstorage)(nil).RPush), cxt, key, values)
+}
+
+// Restore mocks base method.
+func (m *Mockstorage) Restore(cxt context.Context, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", cxt, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockstorageMockRecorder) Restore(cxt, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*Mockstorage)(nil).Restore), cxt, data)
+}
+
+// RestoreKey mocks base method.
+func (m *Mockstorage) RestoreKey(cxt context.Context, key string, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreKey", cxt, key, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreKey indicates an expected call of RestoreKey.
+func (mr *MockstorageMockRecorder) RestoreKey(cxt, key, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreKey", reflect.TypeOf((*Mockstorage)(nil).RestoreKey), cxt, key, data)
+}
+
+// SAdd mocks base method.
+func (m *Mockstorage) SAdd(cxt context.Context, key string, members []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SAdd", cxt, key, members)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SAdd indicates an expected call of SAdd.
+func (mr *MockstorageMockRecorder) SAdd(cxt, key, members any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SAdd", reflect.TypeOf((*Mockstorage)(nil).SAdd), cxt, key, members)
+}
+
+// SCard mocks base method.
+func (m *Mockstorage) SCard(cxt context.Context, key string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SCard", cxt, key)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SCard indicates an expected call of SCard.
+func (mr *MockstorageMockRecorder) SCard(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SCard", reflect.TypeOf((*Mockstorage)(nil).SCard), cxt, key)
+}
+
+// SDiff mocks base method.
+func (m *Mockstorage) SDiff(cxt context.Context, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SDiff", cxt, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SDiff indicates an expected call of SDiff.
+func (mr *MockstorageMockRecorder) SDiff(cxt, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SDiff", reflect.TypeOf((*Mockstorage)(nil).SDiff), cxt, keys)
+}
+
+// SInter mocks base method.
+func (m *Mockstorage) SInter(cxt context.Context, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SInter", cxt, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SInter indicates an expected call of SInter.
+func (mr *MockstorageMockRecorder) SInter(cxt, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SInter", reflect.TypeOf((*Mockstorage)(nil).SInter), cxt, keys)
+}
+
+// SIsMember mocks base method.
+func (m *Mockstorage) SIsMember(cxt context.Context, key, member string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SIsMember", cxt, key, member)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SIsMember indicates an expected call of SIsMember.
+func (mr *MockstorageMockRecorder) SIsMember(cxt, key, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SIsMember", reflect.TypeOf((*Mockstorage)(nil).SIsMember), cxt, key, member)
+}
+
+// SMembers mocks base method.
+func (m *Mockstorage) SMembers(cxt context.Context, key string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SMembers", cxt, key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SMembers indicates an expected call of SMembers.
+func (mr *MockstorageMockRecorder) SMembers(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SMembers", reflect.TypeOf((*Mockstorage)(nil).SMembers), cxt, key)
+}
+
+// SRem mocks base method.
+func (m *Mockstorage) SRem(cxt context.Context, key string, members []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SRem", cxt, key, members)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SRem indicates an expected call of SRem.
+func (mr *MockstorageMockRecorder) SRem(cxt, key, members any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SRem", reflect.TypeOf((*Mockstorage)(nil).SRem), cxt, key, members)
+}
+
+// SUnion mocks base method.
+func (m *Mockstorage) SUnion(cxt context.Context, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SUnion", cxt, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SUnion indicates an expected call of SUnion.
+func (mr *MockstorageMockRecorder) SUnion(cxt, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SUnion", reflect.TypeOf((*Mockstorage)(nil).SUnion), cxt, keys)
+}
+
 // Set mocks base method.
 func (m *Mockstorage) Set(cxt context.Context, key, value string) error {
 	m.ctrl.T.Helper()
@@ -84,6 +657,277 @@ func (mr *MockstorageMockRecorder) Set(cxt, key, value any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*Mockstorage)(nil).Set), cxt, key, value)
 }
 
+// SetNX mocks base method.
+func (m *Mockstorage) SetNX(cxt context.Context, key, value string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNX", cxt, key, value)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNX indicates an expected call of SetNX.
+func (mr *MockstorageMockRecorder) SetNX(cxt, key, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNX", reflect.TypeOf((*Mockstorage)(nil).SetNX), cxt, key, value)
+}
+
+// SetRange mocks base method.
+func (m *Mockstorage) SetRange(cxt context.Context, key string, offset int, value string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRange", cxt, key, offset, value)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRange indicates an expected call of SetRange.
+func (mr *MockstorageMockRecorder) SetRange(cxt, key, offset, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRange", reflect.TypeOf((*Mockstorage)(nil).SetRange), cxt, key, offset, value)
+}
+
+// Snapshot mocks base method.
+func (m *Mockstorage) Snapshot(cxt context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", cxt)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockstorageMockRecorder) Snapshot(cxt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*Mockstorage)(nil).Snapshot), cxt)
+}
+
+// StrLen mocks base method.
+func (m *Mockstorage) StrLen(cxt context.Context, key string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StrLen", cxt, key)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StrLen indicates an expected call of StrLen.
+func (mr *MockstorageMockRecorder) StrLen(cxt, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StrLen", reflect.TypeOf((*Mockstorage)(nil).StrLen), cxt, key)
+}
+
+// XAck mocks base method.
+func (m *Mockstorage) XAck(cxt context.Context, key, group string, ids []domain.StreamID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XAck", cxt, key, group, ids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XAck indicates an expected call of XAck.
+func (mr *MockstorageMockRecorder) XAck(cxt, key, group, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XAck", reflect.TypeOf((*Mockstorage)(nil).XAck), cxt, key, group, ids)
+}
+
+// XAdd mocks base method.
+func (m *Mockstorage) XAdd(cxt context.Context, key string, id domain.StreamID, fields []domain.KeyValue) (domain.StreamID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XAdd", cxt, key, id, fields)
+	ret0, _ := ret[0].(domain.StreamID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XAdd indicates an expected call of XAdd.
+func (mr *MockstorageMockRecorder) XAdd(cxt, key, id, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XAdd", reflect.TypeOf((*Mockstorage)(nil).XAdd), cxt, key, id, fields)
+}
+
+// XGroupCreate mocks base method.
+func (m *Mockstorage) XGroupCreate(cxt context.Context, key, group string, id domain.StreamID, latest, mkStream bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XGroupCreate", cxt, key, group, id, latest, mkStream)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// XGroupCreate indicates an expected call of XGroupCreate.
+func (mr *MockstorageMockRecorder) XGroupCreate(cxt, key, group, id, latest, mkStream any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XGroupCreate", reflect.TypeOf((*Mockstorage)(nil).XGroupCreate), cxt, key, group, id, latest, mkStream)
+}
+
+// XPending mocks base method.
+func (m *Mockstorage) XPending(cxt context.Context, key, group, consumer string) ([]domain.PendingEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XPending", cxt, key, group, consumer)
+	ret0, _ := ret[0].([]domain.PendingEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XPending indicates an expected call of XPending.
+func (mr *MockstorageMockRecorder) XPending(cxt, key, group, consumer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XPending", reflect.TypeOf((*Mockstorage)(nil).XPending), cxt, key, group, consumer)
+}
+
+// XRange mocks base method.
+func (m *Mockstorage) XRange(cxt context.Context, key string, start, end domain.StreamID, count int) ([]domain.StreamEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XRange", cxt, key, start, end, count)
+	ret0, _ := ret[0].([]domain.StreamEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XRange indicates an expected call of XRange.
+func (mr *MockstorageMockRecorder) XRange(cxt, key, start, end, count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XRange", reflect.TypeOf((*Mockstorage)(nil).XRange), cxt, key, start, end, count)
+}
+
+// XRead mocks base method.
+func (m *Mockstorage) XRead(cxt context.Context, offsets []domain.StreamOffset, count int, block time.Duration) ([]domain.StreamEntries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XRead", cxt, offsets, count, block)
+	ret0, _ := ret[0].([]domain.StreamEntries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XRead indicates an expected call of XRead.
+func (mr *MockstorageMockRecorder) XRead(cxt, offsets, count, block any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XRead", reflect.TypeOf((*Mockstorage)(nil).XRead), cxt, offsets, count, block)
+}
+
+// XReadGroup mocks base method.
+func (m *Mockstorage) XReadGroup(cxt context.Context, group, consumer string, offsets []domain.StreamOffset, count int, block time.Duration) ([]domain.StreamEntries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XReadGroup", cxt, group, consumer, offsets, count, block)
+	ret0, _ := ret[0].([]domain.StreamEntries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XReadGroup indicates an expected call of XReadGroup.
+func (mr *MockstorageMockRecorder) XReadGroup(cxt, group, consumer, offsets, count, block any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XReadGroup", reflect.TypeOf((*Mockstorage)(nil).XReadGroup), cxt, group, consumer, offsets, count, block)
+}
+
+// ZAdd mocks base method.
+func (m *Mockstorage) ZAdd(cxt context.Context, key string, members []domain.ScoredMember) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZAdd", cxt, key, members)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ZAdd indicates an expected call of ZAdd.
+func (mr *MockstorageMockRecorder) ZAdd(cxt, key, members any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZAdd", reflect.TypeOf((*Mockstorage)(nil).ZAdd), cxt, key, members)
+}
+
+// ZIncrBy mocks base method.
+func (m *Mockstorage) ZIncrBy(cxt context.Context, key, member string, delta float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZIncrBy", cxt, key, member, delta)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ZIncrBy indicates an expected call of ZIncrBy.
+func (mr *MockstorageMockRecorder) ZIncrBy(cxt, key, member, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZIncrBy", reflect.TypeOf((*Mockstorage)(nil).ZIncrBy), cxt, key, member, delta)
+}
+
+// ZRange mocks base method.
+func (m *Mockstorage) ZRange(cxt context.Context, key string, start, stop int) ([]domain.ScoredMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZRange", cxt, key, start, stop)
+	ret0, _ := ret[0].([]domain.ScoredMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ZRange indicates an expected call of ZRange.
+func (mr *MockstorageMockRecorder) ZRange(cxt, key, start, stop any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZRange", reflect.TypeOf((*Mockstorage)(nil).ZRange), cxt, key, start, stop)
+}
+
+// ZRangeByScore mocks base method.
+func (m *Mockstorage) ZRangeByScore(cxt context.Context, key string, r domain.ScoreRange) ([]domain.ScoredMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZRangeByScore", cxt, key, r)
+	ret0, _ := ret[0].([]domain.ScoredMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ZRangeByScore indicates an expected call of ZRangeByScore.
+func (mr *MockstorageMockRecorder) ZRangeByScore(cxt, key, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZRangeByScore", reflect.TypeOf((*Mockstorage)(nil).ZRangeByScore), cxt, key, r)
+}
+
+// ZRank mocks base method.
+func (m *Mockstorage) ZRank(cxt context.Context, key, member string) (int, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZRank", cxt, key, member)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ZRank indicates an expected call of ZRank.
+func (mr *MockstorageMockRecorder) ZRank(cxt, key, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZRank", reflect.TypeOf((*Mockstorage)(nil).ZRank), cxt, key, member)
+}
+
+// ZRem mocks base method.
+func (m *Mockstorage) ZRem(cxt context.Context, key string, members []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZRem", cxt, key, members)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ZRem indicates an expected call of ZRem.
+func (mr *MockstorageMockRecorder) ZRem(cxt, key, members any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZRem", reflect.TypeOf((*Mockstorage)(nil).ZRem), cxt, key, members)
+}
+
+// ZScore mocks base method.
+func (m *Mockstorage) ZScore(cxt context.Context, key, member string) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZScore", cxt, key, member)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ZScore indicates an expected call of ZScore.
+func (mr *MockstorageMockRecorder) ZScore(cxt, key, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZScore", reflect.TypeOf((*Mockstorage)(nil).ZScore), cxt, key, member)
+}
+
 // Mocksocket is a mock of socket interface.
 type Mocksocket struct {
 	ctrl     *gomock.Controller
@@ -220,3 +1064,83 @@ func (mr *MocksocketMockRecorder) Write(b any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*Mocksocket)(nil).Write), b)
 }
+
+// Mockreplicator is a mock of replicator interface.
+type Mockreplicator struct {
+	ctrl     *gomock.Controller
+	recorder *MockreplicatorMockRecorder
+}
+
+// MockreplicatorMockRecorder is the mock recorder for Mockreplicator.
+type MockreplicatorMockRecorder struct {
+	mock *Mockreplicator
+}
+
+// NewMockreplicator creates a new mock instance.
+func NewMockreplicator(ctrl *gomock.Controller) *Mockreplicator {
+	mock := &Mockreplicator{ctrl: ctrl}
+	mock.recorder = &MockreplicatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockreplicator) EXPECT() *MockreplicatorMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m_2 *Mockreplicator) AddMember(ctx context.Context, m raft.Member) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "AddMember", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockreplicatorMockRecorder) AddMember(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*Mockreplicator)(nil).AddMember), ctx, m)
+}
+
+// Apply mocks base method.
+func (m *Mockreplicator) Apply(ctx context.Context, c domain.Command) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, c)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockreplicatorMockRecorder) Apply(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*Mockreplicator)(nil).Apply), ctx, c)
+}
+
+// RemoveMember mocks base method.
+func (m *Mockreplicator) RemoveMember(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockreplicatorMockRecorder) RemoveMember(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*Mockreplicator)(nil).RemoveMember), ctx, id)
+}
+
+// Status mocks base method.
+func (m *Mockreplicator) Status() raft.Status {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(raft.Status)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockreplicatorMockRecorder) Status() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*Mockreplicator)(nil).Status))
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// connReader reads the connection of a text protocol session. While a
+// blocking command waits nothing else reads the connection, so watch does it
+// to notice the client is gone, bytes it reads are handed to the next Read.
+type connReader struct {
+	conn    socket
+	pending []byte
+	// err is the read error watch got, it is returned once pending is drained.
+	err error
+}
+
+func (r *connReader) Read(p []byte) (int, error) {
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.conn.Read(p)
+}
+
+// watch returns ctx canceled once the client disconnects. Read must not be
+// called until the returned stop func is.
+func (r *connReader) watch(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	// the command may block longer than the idle timeout, failing to lift
+	// the deadline means the connection is broken already
+	if err := r.conn.SetReadDeadline(time.Time{}); err != nil {
+		r.err = err
+		cancel()
+		return ctx, cancel
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		buf := make([]byte, 512)
+		n, err := r.conn.Read(buf)
+		r.pending = append(r.pending, buf[:n]...)
+		// a deadline error comes from stop, data means the client is alive
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			r.err = err
+			cancel()
+		}
+	}()
+
+	return ctx, func() {
+		// the past deadline unblocks the read, the session sets a new one
+		// before reading the next command
+		_ = r.conn.SetReadDeadline(time.Now())
+		<-done
+		cancel()
+	}
+}
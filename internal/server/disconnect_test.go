@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/storage/engine/inmemory"
+)
+
+func TestHandler_BlockingDisconnect(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	st := inmemory.New()
+	ctx := context.Background()
+
+	start := func(t *testing.T) (net.Conn, <-chan struct{}) {
+		server, client := net.Pipe()
+		t.Cleanup(func() { _ = client.Close() })
+
+		done := make(chan struct{})
+		go func() {
+			newHandler(log, st, server, handlerConfig{timeout: time.Minute, bufferSize: 1024}).startHandling(ctx)
+			_ = server.Close()
+			close(done)
+		}()
+		return client, done
+	}
+
+	t.Run("client leaving while blocked does not take the element", func(t *testing.T) {
+		t.Parallel()
+
+		client, done := start(t)
+		_, err := client.Write([]byte("BLPOP gone 0\n"))
+		require.NoError(t, err)
+		require.NoError(t, client.Close())
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("session is still blocked after the client left")
+		}
+
+		_, err = st.LPush(ctx, "gone", []string{"job"})
+		require.NoError(t, err)
+		n, err := st.LLen(ctx, "gone")
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	})
+
+	t.Run("commands sent while blocked are served after", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := start(t)
+		reader := bufio.NewReader(client)
+
+		_, err := client.Write([]byte("BLPOP pipelined 0\n"))
+		require.NoError(t, err)
+		_, err = client.Write([]byte("LLEN pipelined\n"))
+		require.NoError(t, err)
+
+		_, err = st.RPush(ctx, "pipelined", []string{"a", "b"})
+		require.NoError(t, err)
+
+		for _, want := range []string{"2\n", "pipelined\n", "a\n", "1\n"} {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			require.Equal(t, want, line)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// executor runs commands against the storage, it is shared by client sessions
+// and by the replicated state machine.
+type executor struct {
+	storage storage
+	// watches gets every key of a successful mutating command, even when the
+	// command left the key unchanged like SETNX does for an existing key. It may be nil.
+	watches *watchHub
+}
+
+func (e executor) execute(ctx context.Context, c domain.Command) (string, error) {
+	res, err := e.run(ctx, c)
+	if err == nil && parser.Mutating(c.Type) {
+		e.watches.publish(c)
+	}
+	return res, err
+}
+
+func (e executor) run(ctx context.Context, c domain.Command) (string, error) {
+	switch c.Type {
+	case domain.CommandGet:
+		return e.storage.Get(ctx, c.Key)
+	case domain.CommandDelete:
+		return "", e.storage.Delete(ctx, c.Key)
+	case domain.CommandSet:
+		return "", e.storage.Set(ctx, c.Key, c.Value)
+	case domain.CommandIncr:
+		return e.incrBy(ctx, c.Key, 1)
+	case domain.CommandDecr:
+		return e.incrBy(ctx, c.Key, -1)
+	case domain.CommandIncrBy:
+		delta, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return "", domain.ErrNotInteger
+		}
+		return e.incrBy(ctx, c.Key, delta)
+	case domain.CommandIncrByFloat:
+		delta, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return "", domain.ErrNotFloat
+		}
+		f, err := e.storage.IncrByFloat(ctx, c.Key, delta)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case domain.CommandAppend:
+		n, err := e.storage.Append(ctx, c.Key, c.Value)
+		return strconv.Itoa(n), err
+	case domain.CommandGetRange:
+		return e.getRange(ctx, c)
+	case domain.CommandSetRange:
+		offset, err := strconv.Atoi(c.Args[0])
+		if err != nil {
+			return "", domain.ErrNotInteger
+		}
+		n, err := e.storage.SetRange(ctx, c.Key, offset, c.Value)
+		return strconv.Itoa(n), err
+	case domain.CommandStrLen:
+		n, err := e.storage.StrLen(ctx, c.Key)
+		return strconv.Itoa(n), err
+	case domain.CommandGetSet:
+		return optionalResult(e.storage.GetSet(ctx, c.Key, c.Value))
+	case domain.CommandSetNX:
+		ok, err := e.storage.SetNX(ctx, c.Key, c.Value)
+		return boolResult(ok), err
+	case domain.CommandMGet:
+		return e.mGet(ctx, c.Args)
+	case domain.CommandMSet:
+		pairs := make([]domain.KeyValue, 0, len(c.Args)/2)
+		for i := 0; i+1 < len(c.Args); i += 2 {
+			pairs = append(pairs, domain.KeyValue{Key: c.Args[i], Value: c.Args[i+1]})
+		}
+		return "", e.storage.MSet(ctx, pairs)
+	case domain.CommandMDelete:
+		deleted, err := e.storage.MDelete(ctx, c.Args)
+		if err != nil {
+			return "", err
+		}
+		lines := make([]string, len(deleted))
+		for i := range deleted {
+			lines[i] = boolResult(deleted[i])
+		}
+		return multiResult(lines), nil
+	case domain.CommandRestore:
+		return "", e.restore(ctx, c.Args)
+	case domain.CommandHSet, domain.CommandHGet, domain.CommandHDel, domain.CommandHGetAll,
+		domain.CommandHKeys, domain.CommandHVals, domain.CommandHLen, domain.CommandHIncrBy:
+		return e.doHashCmd(ctx, c)
+	case domain.CommandLPush, domain.CommandRPush, domain.CommandLPop, domain.CommandRPop, domain.CommandLRange,
+		domain.CommandLLen, domain.CommandLTrim, domain.CommandBLPop, domain.CommandBRPop:
+		return e.doListCmd(ctx, c)
+	case domain.CommandSAdd, domain.CommandSRem, domain.CommandSMembers, domain.CommandSIsMember,
+		domain.CommandSInter, domain.CommandSUnion, domain.CommandSDiff, domain.CommandSCard:
+		return e.doSetCmd(ctx, c)
+	case domain.CommandZAdd, domain.CommandZRem, domain.CommandZScore, domain.CommandZRank,
+		domain.CommandZRange, domain.CommandZRangeByScore, domain.CommandZIncrBy:
+		return e.doSortedSetCmd(ctx, c)
+	case domain.CommandXAdd, domain.CommandXRange, domain.CommandXRead, domain.CommandXGroup,
+		domain.CommandXReadGroup, domain.CommandXAck, domain.CommandXPending:
+		return e.doStreamCmd(ctx, c)
+	default:
+		return "", fmt.Errorf("invalid cmd type: %q", c.Type)
+	}
+}
+
+func (e executor) incrBy(ctx context.Context, key string, delta int64) (string, error) {
+	n, err := e.storage.IncrBy(ctx, key, delta)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(n, 10), nil
+}
+
+func (e executor) getRange(ctx context.Context, c domain.Command) (string, error) {
+	start, end, err := rangeArgs(c.Args)
+	if err != nil {
+		return "", err
+	}
+
+	v, err := e.storage.GetRange(ctx, c.Key, start, end)
+	if err != nil {
+		return "", err
+	}
+	if v == "" {
+		return emptyResult, nil
+	}
+	return v, nil
+}
+
+// restore stores values of keys dumped by another node, args are keys
+// followed by base64 encoded dumps.
+func (e executor) restore(ctx context.Context, args []string) error {
+	dumps := make([][]byte, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		data, err := base64.StdEncoding.DecodeString(args[i+1])
+		if err != nil {
+			return fmt.Errorf("decode dump of %q: %w", args[i], err)
+		}
+		dumps = append(dumps, data)
+	}
+
+	for i, data := range dumps {
+		if err := e.storage.RestoreKey(ctx, args[i*2], data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e executor) mGet(ctx context.Context, keys []string) (string, error) {
+	values, err := e.storage.MGet(ctx, keys)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(values))
+	for i, v := range values {
+		switch {
+		case v == nil:
+			lines[i] = nilResult
+		case *v == "":
+			lines[i] = emptyResult
+		default:
+			lines[i] = *v
+		}
+	}
+	return multiResult(lines), nil
+}
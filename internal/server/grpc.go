@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/pkg/kvspb"
+)
+
+var errWatcherLagged = errors.New("watcher is too slow to keep up with changes")
+
+// grpcService serves the gRPC API, every call runs as a separate session
+// routed like a text protocol session.
+type grpcService struct {
+	kvspb.UnimplementedKeyValueServer
+
+	log        *slog.Logger
+	newSession func(client string) handler
+	watches    *watchHub
+}
+
+func (g grpcService) Get(ctx context.Context, req *kvspb.GetRequest) (*kvspb.GetResponse, error) {
+	v, err := g.do(ctx, domain.Command{Type: domain.CommandGet, Key: req.GetKey()})
+	if err != nil {
+		return nil, err
+	}
+	return &kvspb.GetResponse{Value: v}, nil
+}
+
+func (g grpcService) Set(ctx context.Context, req *kvspb.SetRequest) (*kvspb.SetResponse, error) {
+	if err := parser.CheckArgs(domain.CommandSet, req.GetKey(), req.GetValue()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := g.do(ctx, domain.Command{Type: domain.CommandSet, Key: req.GetKey(), Value: req.GetValue()}); err != nil {
+		return nil, err
+	}
+	return &kvspb.SetResponse{}, nil
+}
+
+func (g grpcService) Delete(ctx context.Context, req *kvspb.DeleteRequest) (*kvspb.DeleteResponse, error) {
+	if _, err := g.do(ctx, domain.Command{Type: domain.CommandDelete, Key: req.GetKey()}); err != nil {
+		return nil, err
+	}
+	return &kvspb.DeleteResponse{}, nil
+}
+
+// MGet reads the storage directly, the text reply can not tell a missing key
+// from a value looking like the nil reply.
+func (g grpcService) MGet(ctx context.Context, req *kvspb.MGetRequest) (*kvspb.MGetResponse, error) {
+	if len(req.GetKeys()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "keys are required")
+	}
+
+	h := g.newSession(peerAddress(ctx))
+	release, err := h.admit(ctx, domain.Command{Type: domain.CommandMGet, Args: req.GetKeys()}, false)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	defer release()
+
+	values, err := h.storage.MGet(ctx, req.GetKeys())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &kvspb.MGetResponse{Values: make([]*kvspb.OptionalValue, len(values))}
+	for i, v := range values {
+		resp.Values[i] = &kvspb.OptionalValue{}
+		if v != nil {
+			resp.Values[i].Found, resp.Values[i].Value = true, *v
+		}
+	}
+	return resp, nil
+}
+
+func (g grpcService) MSet(ctx context.Context, req *kvspb.MSetRequest) (*kvspb.MSetResponse, error) {
+	if len(req.GetPairs()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "pairs are required")
+	}
+
+	args := make([]string, 0, len(req.GetPairs())*2)
+	for _, p := range req.GetPairs() {
+		args = append(args, p.GetKey(), p.GetValue())
+	}
+	if err := parser.CheckArgs(domain.CommandMSet, args...); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := g.do(ctx, domain.Command{Type: domain.CommandMSet, Args: args}); err != nil {
+		return nil, err
+	}
+	return &kvspb.MSetResponse{}, nil
+}
+
+func (g grpcService) MDelete(ctx context.Context, req *kvspb.MDeleteRequest) (*kvspb.MDeleteResponse, error) {
+	if len(req.GetKeys()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "keys are required")
+	}
+
+	res, err := g.do(ctx, domain.Command{Type: domain.CommandMDelete, Args: req.GetKeys()})
+	if err != nil {
+		return nil, err
+	}
+
+	// the reply is the number of keys followed by a 0 or 1 line per key
+	lines := strings.Split(res, "\n")[1:]
+	resp := &kvspb.MDeleteResponse{Deleted: make([]bool, len(lines))}
+	for i, l := range lines {
+		resp.Deleted[i] = l == "1"
+	}
+	return resp, nil
+}
+
+func (g grpcService) Watch(req *kvspb.WatchRequest, stream grpc.ServerStreamingServer[kvspb.WatchEvent]) error {
+	w := g.watches.subscribe(req.GetKeys(), req.GetPrefix())
+	defer g.watches.unsubscribe(w)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-w.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, errWatcherLagged.Error())
+			}
+
+			err := stream.Send(&kvspb.WatchEvent{Key: e.key, Command: string(e.command), Value: e.value})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (g grpcService) do(ctx context.Context, c domain.Command) (string, error) {
+	res, err := g.newSession(peerAddress(ctx)).doCmd(ctx, c)
+	return res, grpcError(err)
+}
+
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// grpcError maps command errors to status codes.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var redirect cluster.RedirectError
+	code := codes.Internal
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, domain.ErrWrongType):
+		code = codes.FailedPrecondition
+	case errors.As(err, &redirect), errors.Is(err, cluster.ErrCrossSlot):
+		code = codes.FailedPrecondition
+	case errors.Is(err, domain.ErrNotLeader), errors.Is(err, cluster.ErrClusterDown):
+		code = codes.Unavailable
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
+
+// serve runs the service on the listener until ctx is done.
+func (g grpcService) serve(ctx context.Context, l net.Listener, maxMessageSize int) {
+	srv := grpc.NewServer(grpc.MaxRecvMsgSize(maxMessageSize))
+	kvspb.RegisterKeyValueServer(srv, g)
+
+	go func() {
+		<-ctx.Done()
+
+		// watch streams never finish on their own
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			srv.Stop()
+		}
+	}()
+
+	if err := srv.Serve(l); err != nil {
+		g.log.Error("grpc service stopped", "error", err.Error())
+	}
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/pkg/kvspb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCService(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	newClient := func(t *testing.T, st storage) kvspb.KeyValueClient {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		watches := newWatchHub()
+		g := grpcService{
+			log: log,
+			newSession: func(string) handler {
+				h := newHandler(log, st, nil, handlerConfig{})
+				h.watches = watches
+				return h
+			},
+			watches: watches,
+		}
+
+		l := bufconn.Listen(1024 * 1024)
+		go g.serve(ctx, l, 1024*1024)
+
+		conn, err := grpc.NewClient("passthrough:///bufconn",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		return kvspb.NewKeyValueClient(conn)
+	}
+
+	t.Run("serves single keys", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Set(gomock.Any(), "a", "1").Return(nil)
+		storMock.EXPECT().Get(gomock.Any(), "a").Return("1", nil)
+		storMock.EXPECT().Delete(gomock.Any(), "a").Return(nil)
+		storMock.EXPECT().Get(gomock.Any(), "a").Return("", domain.ErrNotFound)
+
+		c := newClient(t, storMock)
+		ctx := context.Background()
+
+		_, err := c.Set(ctx, &kvspb.SetRequest{Key: "a", Value: "1"})
+		require.NoError(t, err)
+
+		resp, err := c.Get(ctx, &kvspb.GetRequest{Key: "a"})
+		require.NoError(t, err)
+		require.Equal(t, "1", resp.GetValue())
+
+		_, err = c.Delete(ctx, &kvspb.DeleteRequest{Key: "a"})
+		require.NoError(t, err)
+
+		_, err = c.Get(ctx, &kvspb.GetRequest{Key: "a"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = c.Set(ctx, &kvspb.SetRequest{Key: "a"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = c.Set(ctx, &kvspb.SetRequest{Key: "a", Value: "1\nFLUSHALL"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("serves batches", func(t *testing.T) {
+		t.Parallel()
+
+		one, empty := "1", ""
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().MSet(gomock.Any(), []domain.KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}).Return(nil)
+		storMock.EXPECT().MGet(gomock.Any(), []string{"a", "b", "c"}).Return([]*string{&one, &empty, nil}, nil)
+		storMock.EXPECT().MDelete(gomock.Any(), []string{"a", "c"}).Return([]bool{true, false}, nil)
+
+		c := newClient(t, storMock)
+		ctx := context.Background()
+
+		_, err := c.MSet(ctx, &kvspb.MSetRequest{Pairs: []*kvspb.KeyValuePair{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}})
+		require.NoError(t, err)
+
+		got, err := c.MGet(ctx, &kvspb.MGetRequest{Keys: []string{"a", "b", "c"}})
+		require.NoError(t, err)
+		require.Len(t, got.GetValues(), 3)
+		require.Equal(t, "1", got.GetValues()[0].GetValue())
+		require.True(t, got.GetValues()[1].GetFound())
+		require.False(t, got.GetValues()[2].GetFound())
+
+		deleted, err := c.MDelete(ctx, &kvspb.MDeleteRequest{Keys: []string{"a", "c"}})
+		require.NoError(t, err)
+		require.Equal(t, []bool{true, false}, deleted.GetDeleted())
+
+		_, err = c.MGet(ctx, &kvspb.MGetRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = c.MSet(ctx, &kvspb.MSetRequest{Pairs: []*kvspb.KeyValuePair{{Key: "a", Value: "1"}, {Key: "b"}}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = c.MSet(ctx, &kvspb.MSetRequest{Pairs: []*kvspb.KeyValuePair{{Key: "a b", Value: "1"}}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("streams changes of watched keys", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		c := newClient(t, storMock)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := c.Watch(ctx, &kvspb.WatchRequest{Prefix: "user:"})
+		require.NoError(t, err)
+
+		// the watcher is registered asynchronously, keep writing until it sees a change
+		go func() {
+			for ctx.Err() == nil {
+				_, _ = c.Set(ctx, &kvspb.SetRequest{Key: "other", Value: "x"})
+				_, _ = c.Set(ctx, &kvspb.SetRequest{Key: "user:1", Value: "John"})
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+
+		e, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "user:1", e.GetKey())
+		require.Equal(t, "SET", e.GetCommand())
+		require.Equal(t, "John", e.GetValue())
+	})
+}
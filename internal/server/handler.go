@@ -8,38 +8,112 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/tmvrus/key-value-storage/internal/audit"
+	"github.com/tmvrus/key-value-storage/internal/cluster"
 	"github.com/tmvrus/key-value-storage/internal/compute/parser"
 	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+const (
+	nilResult   = "(nil)"
+	emptyResult = `""`
+)
+
+const tracerName = "github.com/tmvrus/key-value-storage/internal/server"
+
 type handlerConfig struct {
 	timeout    time.Duration
 	bufferSize int
 }
 
 type handler struct {
-	log     *slog.Logger
-	storage storage
-	conn    socket
-	cfg     handlerConfig
+	executor
+
+	log  *slog.Logger
+	conn socket
+	cfg  handlerConfig
+	// replicator is set when mutating commands go through the consensus log.
+	replicator replicator
+	// cluster is set when the keyspace is sharded across nodes.
+	cluster *cluster.Cluster
+	// migrator is set along with cluster.
+	migrator *cluster.Migrator
+	// serverCtx is done when the server stops, unlike the session context
+	// it is not canceled when the client leaves or is killed.
+	serverCtx context.Context
+	// limiter is set when client rate limits are configured.
+	limiter *rateLimiter
+	// slowLog is set when slow commands are recorded.
+	slowLog  *slowLog
+	monitors *monitorHub
+	// clients is set for text protocol sessions.
+	clients *clientRegistry
+	// tracer is set when tracing is enabled.
+	tracer trace.Tracer
+	// audit is set when mutating and admin commands are recorded.
+	audit *audit.Log
+	// users maps user names to passwords accepted by AUTH.
+	users   map[string]string
+	session *session
+}
+
+// session keeps the state of the client connection between commands.
+type session struct {
+	// id, kill and cancel are set when the session is registered.
+	id     uint64
+	kill   func()
+	cancel context.CancelFunc
+	// client is the remote address of the client.
+	client string
+	// asking allows the next command to access a slot being imported.
+	asking bool
+	// ipLimit and userLimit are nil when not limited.
+	ipLimit   *clientLimit
+	userLimit *clientLimit
+	stats     clientStats
 }
 
 func newHandler(l *slog.Logger, st storage, s socket, cfg handlerConfig) handler {
 	return handler{
-		log:     l,
-		storage: st,
-		conn:    s,
-		cfg:     cfg,
+		executor:  executor{storage: st},
+		log:       l,
+		conn:      s,
+		cfg:       cfg,
+		serverCtx: context.Background(),
+		session:   &session{},
 	}
 }
 
 func (a handler) startHandling(ctx context.Context) {
-	input := bufio.NewScanner(a.conn)
+	reader := &connReader{conn: a.conn}
+	input := bufio.NewScanner(reader)
 	input.Buffer(make([]byte, a.cfg.bufferSize), a.cfg.bufferSize)
 
+	ctx, span := a.startSpan(ctx, "session", trace.WithAttributes(attribute.String("kvs.client", a.session.client)))
+	defer span.End()
+
+	if a.clients != nil {
+		ctx = a.clients.register(ctx, a.session, a.conn)
+		defer a.clients.unregister(a.session)
+	}
+
+	if a.limiter != nil {
+		a.session.ipLimit = a.limiter.acquireIP(remoteIP(a.conn))
+		defer func() {
+			a.limiter.releaseIP(a.session.ipLimit)
+			a.limiter.releaseUser(a.session.userLimit)
+		}()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -51,13 +125,20 @@ func (a handler) startHandling(ctx context.Context) {
 			a.handleError(err, "set read deadline")
 			return
 		}
+		// a killed session is canceled before its read deadline is reset
+		if ctx.Err() != nil {
+			return
+		}
 
 		if !input.Scan() {
-			a.handleError(input.Err(), "scan")
+			if ctx.Err() == nil {
+				a.handleError(input.Err(), "scan")
+			}
 			return
 		}
 
 		text := input.Text()
+		a.session.stats.bytesIn.Add(int64(len(text) + 1))
 		if err := input.Err(); err != nil {
 			if a.handleError(err, "read command") || errors.Is(err, os.ErrDeadlineExceeded) {
 				return
@@ -65,7 +146,21 @@ func (a handler) startHandling(ctx context.Context) {
 			continue
 		}
 
-		cmd, err := parser.Parse(text)
+		if err := a.limit(ctx, len(text)+1); err != nil {
+			if !errors.Is(err, errRateLimited) {
+				return
+			}
+			if a.handleError(a.writeError(err), "limit command") {
+				return
+			}
+			continue
+		}
+
+		// commands of a client propagating its trace join that trace
+		header, text := tracing.SplitHeader(text)
+		cmdCtx := tracing.Extract(ctx, header)
+
+		cmd, err := a.parse(cmdCtx, text)
 		if err != nil {
 			if a.handleError(a.writeError(err), "parse command") {
 				return
@@ -73,7 +168,19 @@ func (a handler) startHandling(ctx context.Context) {
 			continue
 		}
 
-		res, err := a.doCmd(ctx, cmd)
+		a.session.touch(cmd.Type)
+		if cmd.Type == domain.CommandMonitor {
+			a.handleError(a.monitor(ctx), "monitor")
+			return
+		}
+
+		execCtx, stopWatch := cmdCtx, func() {}
+		if cmd.Blocking() {
+			// a client leaving while blocked must not take the element it waits for
+			execCtx, stopWatch = reader.watch(cmdCtx)
+		}
+		res, err := a.doCmd(execCtx, cmd)
+		stopWatch()
 		if err != nil {
 			if a.handleError(a.writeError(err), "exec cmd") {
 				return
@@ -82,12 +189,27 @@ func (a handler) startHandling(ctx context.Context) {
 		}
 
 		err = a.writeResult(res)
-		if a.handleError(err, "write result") {
+		if a.handleError(err, "write result") || cmd.Type == domain.CommandQuit {
 			return
 		}
 	}
 }
 
+// startSpan starts a span when tracing is enabled, ctx is left as is otherwise.
+func (a handler) startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if a.tracer == nil {
+		return ctx, noop.Span{}
+	}
+	return a.tracer.Start(ctx, name, opts...)
+}
+
+func (a handler) parse(ctx context.Context, text string) (domain.Command, error) {
+	_, span := a.startSpan(ctx, "parse")
+	cmd, err := parser.Parse(text)
+	tracing.End(span, err)
+	return cmd, err
+}
+
 func (a handler) writeResult(res string) error {
 	if res == "" {
 		res = "OK"
@@ -118,7 +240,8 @@ func (a handler) writeStringLn(s string) error {
 		return fmt.Errorf("set write deadline: %w", err)
 	}
 
-	_, err = a.conn.Write([]byte(s + "\n"))
+	n, err := a.conn.Write([]byte(s + "\n"))
+	a.session.stats.bytesOut.Add(int64(n))
 	if err != nil {
 		return fmt.Errorf("failed to write conn: %w", err)
 	}
@@ -130,15 +253,83 @@ func (a handler) writeError(err error) error {
 	return a.writeStringLn(msg)
 }
 
-func (a handler) doCmd(ctx context.Context, c domain.Command) (string, error) {
+func (a handler) doCmd(ctx context.Context, c domain.Command) (res string, err error) {
+	ctx, span := a.startSpan(ctx, string(c.Type), trace.WithAttributes(attribute.String("kvs.client", a.session.client)))
+	defer func() { tracing.End(span, err) }()
+
+	asking := a.session.asking
+	a.session.asking = false
+
+	if a.audit != nil {
+		defer func(start time.Time) { a.writeAudit(start, c, err) }(time.Now())
+	}
+
+	if a.slowLog != nil && !c.Blocking() {
+		defer a.slowLog.observe(time.Now(), a.session.client, c)
+	}
+	a.monitors.publish(a.session, c)
+
+	if !a.authorized(c) {
+		return "", errAuthRequired
+	}
+
 	switch c.Type {
-	case domain.CommandGet:
-		return a.storage.Get(ctx, c.Key)
-	case domain.CommandDelete:
-		return "", a.storage.Delete(ctx, c.Key)
-	case domain.CommandSet:
-		return "", a.storage.Set(ctx, c.Key, c.Value)
-	default:
-		return "", fmt.Errorf("invalid cmd type: %q", c.Type)
+	case domain.CommandRaft:
+		return a.doRaftCmd(ctx, c)
+	case domain.CommandCluster:
+		return a.doClusterCmd(ctx, c)
+	case domain.CommandAsking:
+		a.session.asking = true
+		return "", nil
+	case domain.CommandAuth:
+		return "", a.auth(c.Args[0], c.Args[1])
+	case domain.CommandClient:
+		return a.doClientCmd(ctx, c)
+	case domain.CommandSlowLog:
+		return a.doSlowLogCmd(ctx, c)
+	case domain.CommandMonitor:
+		return "", errMonitorTextClient
+	case domain.CommandPing, domain.CommandEcho, domain.CommandQuit, domain.CommandHelp, domain.CommandCommand:
+		return metaResult(c)
+	}
+
+	release, err := a.admit(ctx, c, asking)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	if a.replicator != nil && parser.Mutating(c.Type) {
+		if c.Blocking() {
+			return "", errBlockingReplicated
+		}
+		return a.replicator.Apply(ctx, c)
+	}
+
+	return a.execute(ctx, c)
+}
+
+// admit checks the command may be served by this node, the returned func must
+// be called once the command is executed.
+func (a handler) admit(ctx context.Context, c domain.Command, asking bool) (func(), error) {
+	if a.cluster == nil {
+		return func() {}, nil
+	}
+	return a.route(ctx, c, asking)
+}
+
+// multiResult formats a multi-line reply: the number of lines goes first,
+// followed by the lines themselves.
+func multiResult(lines []string) string {
+	if len(lines) == 0 {
+		return "0"
+	}
+	return strconv.Itoa(len(lines)) + "\n" + strings.Join(lines, "\n")
+}
+
+func boolResult(b bool) string {
+	if b {
+		return "1"
 	}
+	return "0"
 }
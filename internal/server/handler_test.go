@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/tmvrus/key-value-storage/internal/domain"
 	"go.uber.org/mock/gomock"
 )
 
@@ -187,6 +189,361 @@ func TestApp_Session(t *testing.T) {
 
 		newHandler(log, storMock, socketMock, cfg).startHandling(ctx)
 	})
+
+	t.Run("able to increment counter and write new value", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		storMock := NewMockstorage(ctrl)
+		socketMock := NewMocksocket(ctrl)
+		ctx := context.Background()
+
+		socketMock.EXPECT().SetReadDeadline(inFuture{t}).Return(nil).Times(2)
+		cmd := []byte("INCRBY KEY 10\n")
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, cmd), io.EOF
+			}).Times(1)
+
+		storMock.EXPECT().IncrBy(ctx, "KEY", int64(10)).Return(int64(15), nil)
+
+		socketMock.EXPECT().SetWriteDeadline(inFuture{t}).Return(nil)
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("15\n")}).Return(0, nil)
+
+		newHandler(log, storMock, socketMock, cfg).startHandling(ctx)
+	})
+
+	t.Run("able to report not a number error on increment", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		storMock := NewMockstorage(ctrl)
+		socketMock := NewMocksocket(ctrl)
+		ctx := context.Background()
+
+		socketMock.EXPECT().SetReadDeadline(inFuture{t}).Return(nil).Times(2)
+		cmd := []byte("DECR KEY\n")
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, cmd), io.EOF
+			}).Times(1)
+
+		storMock.EXPECT().IncrBy(ctx, "KEY", int64(-1)).Return(int64(0), domain.ErrNotInteger)
+
+		socketMock.EXPECT().SetWriteDeadline(inFuture{t}).Return(nil)
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("ERROR: value is not an integer\n")}).Return(0, nil)
+
+		newHandler(log, storMock, socketMock, cfg).startHandling(ctx)
+	})
+}
+
+func TestApp_Commands(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	cfg := handlerConfig{
+		timeout:    time.Minute,
+		bufferSize: 1024,
+	}
+
+	tt := []struct {
+		name   string
+		in     string
+		expect func(m *MockstorageMockRecorder)
+		out    string
+		// blocking commands make the session watch the connection
+		blocking bool
+	}{
+		{
+			name: "APPEND returns new length",
+			in:   "APPEND KEY VALUE",
+			expect: func(m *MockstorageMockRecorder) {
+				m.Append(gomock.Any(), "KEY", "VALUE").Return(10, nil)
+			},
+			out: "10",
+		},
+		{
+			name: "GETRANGE returns empty string",
+			in:   "GETRANGE KEY 5 1",
+			expect: func(m *MockstorageMockRecorder) {
+				m.GetRange(gomock.Any(), "KEY", 5, 1).Return("", nil)
+			},
+			out: `""`,
+		},
+		{
+			name: "SETRANGE returns new length",
+			in:   "SETRANGE KEY 3 VALUE",
+			expect: func(m *MockstorageMockRecorder) {
+				m.SetRange(gomock.Any(), "KEY", 3, "VALUE").Return(8, nil)
+			},
+			out: "8",
+		},
+		{
+			name: "GETSET returns nil for absent key",
+			in:   "GETSET KEY VALUE",
+			expect: func(m *MockstorageMockRecorder) {
+				m.GetSet(gomock.Any(), "KEY", "VALUE").Return("", false, nil)
+			},
+			out: "(nil)",
+		},
+		{
+			name: "GETSET returns old value",
+			in:   "GETSET KEY VALUE",
+			expect: func(m *MockstorageMockRecorder) {
+				m.GetSet(gomock.Any(), "KEY", "VALUE").Return("OLD", true, nil)
+			},
+			out: "OLD",
+		},
+		{
+			name: "SETNX reports existing key",
+			in:   "SETNX KEY VALUE",
+			expect: func(m *MockstorageMockRecorder) {
+				m.SetNX(gomock.Any(), "KEY", "VALUE").Return(false, nil)
+			},
+			out: "0",
+		},
+		{
+			name: "MGET returns values line by line",
+			in:   "MGET KEY1 KEY2",
+			expect: func(m *MockstorageMockRecorder) {
+				v := "VALUE"
+				m.MGet(gomock.Any(), []string{"KEY1", "KEY2"}).Return([]*string{&v, nil}, nil)
+			},
+			out: "2\nVALUE\n(nil)",
+		},
+		{
+			name: "MSET stores pairs",
+			in:   "MSET KEY1 VALUE1 KEY2 VALUE2",
+			expect: func(m *MockstorageMockRecorder) {
+				m.MSet(gomock.Any(), []domain.KeyValue{{Key: "KEY1", Value: "VALUE1"}, {Key: "KEY2", Value: "VALUE2"}}).Return(nil)
+			},
+			out: "OK",
+		},
+		{
+			name: "MDELETE reports per key result",
+			in:   "MDELETE KEY1 KEY2",
+			expect: func(m *MockstorageMockRecorder) {
+				m.MDelete(gomock.Any(), []string{"KEY1", "KEY2"}).Return([]bool{false, true}, nil)
+			},
+			out: "2\n0\n1",
+		},
+		{
+			name: "HSET returns number of added fields",
+			in:   "HSET KEY F1 V1 F2 V2",
+			expect: func(m *MockstorageMockRecorder) {
+				m.HSet(gomock.Any(), "KEY", []domain.KeyValue{{Key: "F1", Value: "V1"}, {Key: "F2", Value: "V2"}}).Return(2, nil)
+			},
+			out: "2",
+		},
+		{
+			name: "HGETALL returns fields and values",
+			in:   "HGETALL KEY",
+			expect: func(m *MockstorageMockRecorder) {
+				m.HGetAll(gomock.Any(), "KEY").Return([]domain.KeyValue{{Key: "F1", Value: "V1"}}, nil)
+			},
+			out: "2\nF1\nV1",
+		},
+		{
+			name: "HGET reports wrong type",
+			in:   "HGET KEY F1",
+			expect: func(m *MockstorageMockRecorder) {
+				m.HGet(gomock.Any(), "KEY", "F1").Return("", domain.ErrWrongType)
+			},
+			out: "ERROR: WRONGTYPE operation against a key holding the wrong kind of value",
+		},
+		{
+			name: "LPUSH returns list length",
+			in:   "LPUSH KEY A B",
+			expect: func(m *MockstorageMockRecorder) {
+				m.LPush(gomock.Any(), "KEY", []string{"A", "B"}).Return(2, nil)
+			},
+			out: "2",
+		},
+		{
+			name: "LPOP returns nil for empty list",
+			in:   "LPOP KEY",
+			expect: func(m *MockstorageMockRecorder) {
+				m.LPop(gomock.Any(), "KEY").Return("", false, nil)
+			},
+			out: "(nil)",
+		},
+		{
+			name:     "BLPOP returns key and value",
+			in:       "BLPOP KEY1 KEY2 1.5",
+			blocking: true,
+			expect: func(m *MockstorageMockRecorder) {
+				m.BLPop(gomock.Any(), []string{"KEY1", "KEY2"}, 1500*time.Millisecond).
+					Return(domain.KeyValue{Key: "KEY2", Value: "VALUE"}, true, nil)
+			},
+			out: "2\nKEY2\nVALUE",
+		},
+		{
+			name:     "BRPOP returns nil on timeout",
+			in:       "BRPOP KEY 0",
+			blocking: true,
+			expect: func(m *MockstorageMockRecorder) {
+				m.BRPop(gomock.Any(), []string{"KEY"}, time.Duration(0)).Return(domain.KeyValue{}, false, nil)
+			},
+			out: "(nil)",
+		},
+		{
+			name: "SMEMBERS returns members line by line",
+			in:   "SMEMBERS KEY",
+			expect: func(m *MockstorageMockRecorder) {
+				m.SMembers(gomock.Any(), "KEY").Return([]string{"A", "B"}, nil)
+			},
+			out: "2\nA\nB",
+		},
+		{
+			name: "SUNION returns empty reply",
+			in:   "SUNION KEY1 KEY2",
+			expect: func(m *MockstorageMockRecorder) {
+				m.SUnion(gomock.Any(), []string{"KEY1", "KEY2"}).Return(nil, nil)
+			},
+			out: "0",
+		},
+		{
+			name: "SISMEMBER reports membership",
+			in:   "SISMEMBER KEY A",
+			expect: func(m *MockstorageMockRecorder) {
+				m.SIsMember(gomock.Any(), "KEY", "A").Return(true, nil)
+			},
+			out: "1",
+		},
+		{
+			name: "ZADD returns number of added members",
+			in:   "ZADD KEY 1.5 A 2 B",
+			expect: func(m *MockstorageMockRecorder) {
+				m.ZAdd(gomock.Any(), "KEY", []domain.ScoredMember{{Member: "A", Score: 1.5}, {Member: "B", Score: 2}}).Return(2, nil)
+			},
+			out: "2",
+		},
+		{
+			name: "ZRANGE returns members with scores",
+			in:   "ZRANGE KEY 0 -1 WITHSCORES",
+			expect: func(m *MockstorageMockRecorder) {
+				m.ZRange(gomock.Any(), "KEY", 0, -1).Return([]domain.ScoredMember{{Member: "A", Score: 1.5}}, nil)
+			},
+			out: "2\nA\n1.5",
+		},
+		{
+			name: "ZRANGEBYSCORE passes exclusive bounds",
+			in:   "ZRANGEBYSCORE KEY (1 +inf",
+			expect: func(m *MockstorageMockRecorder) {
+				r := domain.ScoreRange{Min: 1, Max: math.Inf(1), MinExclusive: true}
+				m.ZRangeByScore(gomock.Any(), "KEY", r).Return([]domain.ScoredMember{{Member: "A", Score: 1.5}}, nil)
+			},
+			out: "1\nA",
+		},
+		{
+			name: "ZSCORE returns nil for absent member",
+			in:   "ZSCORE KEY A",
+			expect: func(m *MockstorageMockRecorder) {
+				m.ZScore(gomock.Any(), "KEY", "A").Return(0.0, false, nil)
+			},
+			out: "(nil)",
+		},
+		{
+			name: "XADD returns generated ID",
+			in:   "XADD KEY * F1 V1",
+			expect: func(m *MockstorageMockRecorder) {
+				m.XAdd(gomock.Any(), "KEY", domain.StreamID{}, []domain.KeyValue{{Key: "F1", Value: "V1"}}).
+					Return(domain.StreamID{Ms: 10, Seq: 1}, nil)
+			},
+			out: "10-1",
+		},
+		{
+			name: "XRANGE returns entry per line",
+			in:   "XRANGE KEY - + COUNT 5",
+			expect: func(m *MockstorageMockRecorder) {
+				m.XRange(gomock.Any(), "KEY", domain.StreamID{}, domain.MaxStreamID, 5).
+					Return([]domain.StreamEntry{{ID: domain.StreamID{Ms: 10}, Fields: []domain.KeyValue{{Key: "F1", Value: "V1"}}}}, nil)
+			},
+			out: "1\n10-0 F1 V1",
+		},
+		{
+			name:     "XREAD returns nil on timeout",
+			in:       "XREAD BLOCK 100 STREAMS KEY1 KEY2 $ 5",
+			blocking: true,
+			expect: func(m *MockstorageMockRecorder) {
+				offsets := []domain.StreamOffset{{Key: "KEY1", Latest: true}, {Key: "KEY2", ID: domain.StreamID{Ms: 5}}}
+				m.XRead(gomock.Any(), offsets, 0, 100*time.Millisecond).Return(nil, nil)
+			},
+			out: "(nil)",
+		},
+		{
+			name: "XREADGROUP returns entries prefixed with key",
+			in:   "XREADGROUP GROUP G C COUNT 1 STREAMS KEY >",
+			expect: func(m *MockstorageMockRecorder) {
+				offsets := []domain.StreamOffset{{Key: "KEY", Latest: true}}
+				m.XReadGroup(gomock.Any(), "G", "C", offsets, 1, -time.Millisecond).
+					Return([]domain.StreamEntries{{Key: "KEY", Entries: []domain.StreamEntry{{ID: domain.StreamID{Ms: 1}}}}}, nil)
+			},
+			out: "1\nKEY 1-0",
+		},
+		{
+			name: "XGROUP reports missing stream",
+			in:   "XGROUP CREATE KEY G $",
+			expect: func(m *MockstorageMockRecorder) {
+				m.XGroupCreate(gomock.Any(), "KEY", "G", domain.StreamID{}, true, false).Return(domain.ErrNoGroup)
+			},
+			out: "ERROR: NOGROUP no such key or consumer group",
+		},
+		{
+			name: "XPENDING returns pending entries",
+			in:   "XPENDING KEY G",
+			expect: func(m *MockstorageMockRecorder) {
+				m.XPending(gomock.Any(), "KEY", "G", "").
+					Return([]domain.PendingEntry{{ID: domain.StreamID{Ms: 1}, Consumer: "C", Idle: time.Second, Deliveries: 2}}, nil)
+			},
+			out: "1\n1-0 C 1000 2",
+		},
+	}
+
+	for _, c := range tt {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			storMock := NewMockstorage(ctrl)
+			socketMock := NewMocksocket(ctrl)
+
+			if c.blocking {
+				// the session clears and expires the read deadline while it
+				// watches the connection, the client is gone by then
+				notInFuture := gomock.Cond(func(x any) bool { return !x.(time.Time).After(time.Now()) })
+				socketMock.EXPECT().SetReadDeadline(notInFuture).Return(nil).Times(2)
+			}
+			socketMock.EXPECT().SetReadDeadline(inFuture{t}).Return(nil).Times(2)
+			cmd := []byte(c.in + "\n")
+			socketMock.
+				EXPECT().
+				Read(gomock.Any()).
+				DoAndReturn(func(p []byte) (int, error) {
+					return copy(p, cmd), io.EOF
+				}).Times(1)
+			if c.blocking {
+				socketMock.EXPECT().Read(gomock.Any()).Return(0, io.EOF)
+			}
+
+			c.expect(storMock.EXPECT())
+
+			socketMock.EXPECT().SetWriteDeadline(inFuture{t}).Return(nil)
+			socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte(c.out + "\n")}).Return(0, nil)
+
+			newHandler(log, storMock, socketMock, cfg).startHandling(context.Background())
+		})
+	}
 }
 
 type inFuture struct {
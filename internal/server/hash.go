@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e executor) doHashCmd(ctx context.Context, c domain.Command) (string, error) {
+	switch c.Type {
+	case domain.CommandHSet:
+		fields := make([]domain.KeyValue, 0, len(c.Args)/2)
+		for i := 0; i+1 < len(c.Args); i += 2 {
+			fields = append(fields, domain.KeyValue{Key: c.Args[i], Value: c.Args[i+1]})
+		}
+		n, err := e.storage.HSet(ctx, c.Key, fields)
+		return strconv.Itoa(n), err
+	case domain.CommandHGet:
+		return e.storage.HGet(ctx, c.Key, c.Args[0])
+	case domain.CommandHDel:
+		n, err := e.storage.HDel(ctx, c.Key, c.Args)
+		return strconv.Itoa(n), err
+	case domain.CommandHGetAll:
+		fields, err := e.storage.HGetAll(ctx, c.Key)
+		if err != nil {
+			return "", err
+		}
+		lines := make([]string, 0, len(fields)*2)
+		for _, f := range fields {
+			lines = append(lines, f.Key, f.Value)
+		}
+		return multiResult(lines), nil
+	case domain.CommandHKeys:
+		keys, err := e.storage.HKeys(ctx, c.Key)
+		if err != nil {
+			return "", err
+		}
+		return multiResult(keys), nil
+	case domain.CommandHVals:
+		values, err := e.storage.HVals(ctx, c.Key)
+		if err != nil {
+			return "", err
+		}
+		return multiResult(values), nil
+	case domain.CommandHLen:
+		n, err := e.storage.HLen(ctx, c.Key)
+		return strconv.Itoa(n), err
+	case domain.CommandHIncrBy:
+		delta, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return "", domain.ErrNotInteger
+		}
+		n, err := e.storage.HIncrBy(ctx, c.Key, c.Args[0], delta)
+		return strconv.FormatInt(n, 10), err
+	default:
+		return "", fmt.Errorf("invalid hash cmd type: %q", c.Type)
+	}
+}
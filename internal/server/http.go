@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// shutdownTimeout limits waiting for requests in flight when the server stops.
+const shutdownTimeout = 5 * time.Second
+
+var errTooManyRequests = errors.New("too many concurrent requests")
+
+// httpGateway serves storage commands as JSON over HTTP. Every request runs
+// as a separate session sharing the limit with text protocol sessions.
+type httpGateway struct {
+	log *slog.Logger
+	// newSession builds a handler routing commands like a text protocol session does.
+	newSession     func(client string) handler
+	sessionLimiter *sessionLimiter
+	maxBodySize    int64
+}
+
+type keyValueJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+type batchRequestJSON struct {
+	Operations []batchOperationJSON `json:"operations"`
+}
+
+type batchOperationJSON struct {
+	// Op is one of get, set and delete.
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+type batchResponseJSON struct {
+	Results []batchResultJSON `json:"results"`
+}
+
+type batchResultJSON struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (g httpGateway) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/keys/{key}", g.get)
+	mux.HandleFunc("PUT /v1/keys/{key}", g.put)
+	mux.HandleFunc("DELETE /v1/keys/{key}", g.delete)
+	mux.HandleFunc("POST /v1/batch", g.batch)
+	return g.limit(mux)
+}
+
+// limit rejects requests over the session limit and bodies over the max message size.
+func (g httpGateway) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !g.sessionLimiter.acquire() {
+			g.writeError(w, http.StatusServiceUnavailable, errTooManyRequests)
+			return
+		}
+		defer g.sessionLimiter.release()
+
+		r.Body = http.MaxBytesReader(w, r.Body, g.maxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (g httpGateway) get(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	v, err := g.do(r, domain.Command{Type: domain.CommandGet, Key: key})
+	if err != nil {
+		g.writeError(w, httpStatus(err), err)
+		return
+	}
+
+	g.writeJSON(w, http.StatusOK, keyValueJSON{Key: key, Value: v})
+}
+
+func (g httpGateway) put(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Value *string `json:"value"`
+	}
+	if !g.decode(w, r, &body) {
+		return
+	}
+	if body.Value == nil {
+		g.writeError(w, http.StatusBadRequest, errors.New("value is required"))
+		return
+	}
+	key := r.PathValue("key")
+	if err := parser.CheckArgs(domain.CommandSet, key, *body.Value); err != nil {
+		g.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	_, err := g.do(r, domain.Command{Type: domain.CommandSet, Key: key, Value: *body.Value})
+	if err != nil {
+		g.writeError(w, httpStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g httpGateway) delete(w http.ResponseWriter, r *http.Request) {
+	_, err := g.do(r, domain.Command{Type: domain.CommandDelete, Key: r.PathValue("key")})
+	if err != nil {
+		g.writeError(w, httpStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// batch runs operations one by one, a failed operation does not stop the
+// others and gets its own status.
+func (g httpGateway) batch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequestJSON
+	if !g.decode(w, r, &req) {
+		return
+	}
+
+	cmds := make([]domain.Command, len(req.Operations))
+	for i, op := range req.Operations {
+		c, err := batchCommand(op)
+		if err != nil {
+			g.writeError(w, http.StatusBadRequest, fmt.Errorf("operation %d: %w", i, err))
+			return
+		}
+		cmds[i] = c
+	}
+
+	resp := batchResponseJSON{Results: make([]batchResultJSON, len(cmds))}
+	for i, c := range cmds {
+		res := batchResultJSON{Key: c.Key, Status: http.StatusOK}
+		v, err := g.do(r, c)
+		switch {
+		case err != nil:
+			res.Status, res.Error = httpStatus(err), err.Error()
+		case c.Type == domain.CommandGet:
+			res.Value = v
+		default:
+			res.Status = http.StatusNoContent
+		}
+		resp.Results[i] = res
+	}
+
+	g.writeJSON(w, http.StatusOK, resp)
+}
+
+func batchCommand(op batchOperationJSON) (domain.Command, error) {
+	if op.Key == "" {
+		return domain.Command{}, errors.New("key is required")
+	}
+
+	var c domain.Command
+	args := []string{op.Key}
+	switch op.Op {
+	case "get":
+		c = domain.Command{Type: domain.CommandGet, Key: op.Key}
+	case "set":
+		c = domain.Command{Type: domain.CommandSet, Key: op.Key, Value: op.Value}
+		args = append(args, op.Value)
+	case "delete":
+		c = domain.Command{Type: domain.CommandDelete, Key: op.Key}
+	default:
+		return domain.Command{}, fmt.Errorf("unsupported operation %q", op.Op)
+	}
+	if err := parser.CheckArgs(c.Type, args...); err != nil {
+		return domain.Command{}, err
+	}
+	return c, nil
+}
+
+func (g httpGateway) do(r *http.Request, c domain.Command) (string, error) {
+	ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return g.newSession(r.RemoteAddr).doCmd(ctx, c)
+}
+
+// decode reads the JSON body and replies with an error when it fails.
+func (g httpGateway) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		g.writeError(w, http.StatusRequestEntityTooLarge, err)
+	} else {
+		g.writeError(w, http.StatusBadRequest, fmt.Errorf("decode body: %w", err))
+	}
+	return false
+}
+
+func (g httpGateway) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		g.log.Error("failed to write response", "error", err.Error())
+	}
+}
+
+func (g httpGateway) writeError(w http.ResponseWriter, status int, err error) {
+	g.writeJSON(w, status, errorJSON{Error: err.Error()})
+}
+
+// httpStatus maps command errors to response codes.
+func httpStatus(err error) int {
+	var redirect cluster.RedirectError
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrWrongType):
+		return http.StatusConflict
+	case errors.As(err, &redirect):
+		return http.StatusMisdirectedRequest
+	case errors.Is(err, domain.ErrNotLeader), errors.Is(err, cluster.ErrClusterDown):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// serve runs the gateway on the listener until ctx is done.
+func (g httpGateway) serve(ctx context.Context, l net.Listener, idleTimeout time.Duration) {
+	srv := &http.Server{
+		Handler:     g.routes(),
+		ReadTimeout: idleTimeout,
+		IdleTimeout: idleTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			g.log.Error("failed to shutdown http gateway", "error", err.Error())
+		}
+	}()
+
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		g.log.Error("http gateway stopped", "error", err.Error())
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHTTPGateway(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	newGateway := func(t *testing.T, st storage, limit int) *httptest.Server {
+		g := httpGateway{
+			log:            log,
+			newSession:     func(string) handler { return newHandler(log, st, nil, handlerConfig{}) },
+			sessionLimiter: newSessionLimiter(limit),
+			maxBodySize:    256,
+		}
+		srv := httptest.NewServer(g.routes())
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	do := func(t *testing.T, method, url, body string) (int, string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, strings.TrimSpace(string(data))
+	}
+
+	t.Run("gets, puts and deletes key", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Set(gomock.Any(), "user:1", "John_Smith").Return(nil)
+		storMock.EXPECT().Get(gomock.Any(), "user:1").Return("John_Smith", nil)
+		storMock.EXPECT().Delete(gomock.Any(), "user:1").Return(nil)
+		srv := newGateway(t, storMock, 1)
+
+		status, body := do(t, http.MethodPut, srv.URL+"/v1/keys/user:1", `{"value":"John_Smith"}`)
+		require.Equal(t, http.StatusNoContent, status)
+		require.Empty(t, body)
+
+		status, body = do(t, http.MethodGet, srv.URL+"/v1/keys/user:1", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"key":"user:1","value":"John_Smith"}`, body)
+
+		status, _ = do(t, http.MethodDelete, srv.URL+"/v1/keys/user:1", "")
+		require.Equal(t, http.StatusNoContent, status)
+	})
+
+	t.Run("maps errors to statuses", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Get(gomock.Any(), "missing").Return("", domain.ErrNotFound)
+		storMock.EXPECT().Get(gomock.Any(), "list").Return("", domain.ErrWrongType)
+		storMock.EXPECT().Delete(gomock.Any(), "missing").Return(domain.ErrNotFound)
+		srv := newGateway(t, storMock, 1)
+
+		status, body := do(t, http.MethodGet, srv.URL+"/v1/keys/missing", "")
+		require.Equal(t, http.StatusNotFound, status)
+		require.JSONEq(t, `{"error":"not found"}`, body)
+
+		status, _ = do(t, http.MethodGet, srv.URL+"/v1/keys/list", "")
+		require.Equal(t, http.StatusConflict, status)
+
+		status, _ = do(t, http.MethodDelete, srv.URL+"/v1/keys/missing", "")
+		require.Equal(t, http.StatusNotFound, status)
+
+		status, _ = do(t, http.MethodPut, srv.URL+"/v1/keys/k", `{"val":"1"}`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = do(t, http.MethodPut, srv.URL+"/v1/keys/k", `{"value":""}`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = do(t, http.MethodPut, srv.URL+"/v1/keys/k", `{"value":"two words"}`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = do(t, http.MethodPut, srv.URL+"/v1/keys/a%20b", `{"value":"1"}`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = do(t, http.MethodPut, srv.URL+"/v1/keys/k", `{"value":"`+strings.Repeat("x", 300)+`"}`)
+		require.Equal(t, http.StatusRequestEntityTooLarge, status)
+
+		status, _ = do(t, http.MethodPost, srv.URL+"/v1/keys/k", "")
+		require.Equal(t, http.StatusMethodNotAllowed, status)
+	})
+
+	t.Run("runs batch", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Set(gomock.Any(), "a", "1").Return(nil)
+		storMock.EXPECT().Get(gomock.Any(), "a").Return("1", nil)
+		storMock.EXPECT().Delete(gomock.Any(), "b").Return(domain.ErrNotFound)
+		srv := newGateway(t, storMock, 1)
+
+		status, body := do(t, http.MethodPost, srv.URL+"/v1/batch",
+			`{"operations":[{"op":"set","key":"a","value":"1"},{"op":"get","key":"a"},{"op":"delete","key":"b"}]}`)
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"results":[
+			{"key":"a","status":204},
+			{"key":"a","value":"1","status":200},
+			{"key":"b","status":404,"error":"not found"}
+		]}`, body)
+
+		status, _ = do(t, http.MethodPost, srv.URL+"/v1/batch", `{"operations":[{"op":"incr","key":"a"}]}`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = do(t, http.MethodPost, srv.URL+"/v1/batch", `{"operations":[{"op":"set","key":"a","value":"1\nFLUSHALL"}]}`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = do(t, http.MethodPost, srv.URL+"/v1/batch", `{"operations":[{"op":"delete","key":"a b"}]}`)
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("redirects to cluster owner", func(t *testing.T) {
+		t.Parallel()
+
+		c, err := cluster.New("a", []cluster.NodeSlots{
+			{Node: cluster.Node{ID: "a", Address: "10.0.0.1:3223"}, Slots: []cluster.SlotRange{{Start: 0, End: 8191}}},
+			{Node: cluster.Node{ID: "b", Address: "10.0.0.2:3223"}, Slots: []cluster.SlotRange{{Start: 8192, End: 16383}}},
+		})
+		require.NoError(t, err)
+
+		g := httpGateway{
+			log: log,
+			newSession: func(string) handler {
+				h := newHandler(log, nil, nil, handlerConfig{})
+				h.cluster = c
+				return h
+			},
+			sessionLimiter: newSessionLimiter(1),
+			maxBodySize:    256,
+		}
+		srv := httptest.NewServer(g.routes())
+		t.Cleanup(srv.Close)
+
+		status, body := do(t, http.MethodGet, srv.URL+"/v1/keys/foo", "")
+		require.Equal(t, http.StatusMisdirectedRequest, status)
+		require.JSONEq(t, `{"error":"MOVED 12182 10.0.0.2:3223"}`, body)
+	})
+
+	t.Run("obeys session limit", func(t *testing.T) {
+		t.Parallel()
+
+		srv := newGateway(t, nil, 0)
+
+		status, _ := do(t, http.MethodGet, srv.URL+"/v1/keys/a", "")
+		require.Equal(t, http.StatusServiceUnavailable, status)
+	})
+}
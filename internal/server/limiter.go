@@ -0,0 +1,42 @@
+package server
+
+import "sync"
+
+// sessionLimiter limits the number of concurrent sessions, the limit may be
+// changed while sessions run: lowering it rejects new sessions until enough
+// running ones finish.
+type sessionLimiter struct {
+	lock   sync.Mutex
+	active int
+	max    int
+}
+
+func newSessionLimiter(n int) *sessionLimiter {
+	return &sessionLimiter{max: n}
+}
+
+// acquire reports whether a new session may start, a started session must be released.
+func (l *sessionLimiter) acquire() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.active >= l.max {
+		return false
+	}
+	l.active++
+	return true
+}
+
+func (l *sessionLimiter) release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.active--
+}
+
+func (l *sessionLimiter) resize(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.max = n
+}
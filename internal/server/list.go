@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e executor) doListCmd(ctx context.Context, c domain.Command) (string, error) {
+	switch c.Type {
+	case domain.CommandLPush:
+		n, err := e.storage.LPush(ctx, c.Key, c.Args)
+		return strconv.Itoa(n), err
+	case domain.CommandRPush:
+		n, err := e.storage.RPush(ctx, c.Key, c.Args)
+		return strconv.Itoa(n), err
+	case domain.CommandLPop:
+		return optionalResult(e.storage.LPop(ctx, c.Key))
+	case domain.CommandRPop:
+		return optionalResult(e.storage.RPop(ctx, c.Key))
+	case domain.CommandLRange:
+		start, stop, err := rangeArgs(c.Args)
+		if err != nil {
+			return "", err
+		}
+		items, err := e.storage.LRange(ctx, c.Key, start, stop)
+		if err != nil {
+			return "", err
+		}
+		return multiResult(items), nil
+	case domain.CommandLLen:
+		n, err := e.storage.LLen(ctx, c.Key)
+		return strconv.Itoa(n), err
+	case domain.CommandLTrim:
+		start, stop, err := rangeArgs(c.Args)
+		if err != nil {
+			return "", err
+		}
+		return "", e.storage.LTrim(ctx, c.Key, start, stop)
+	case domain.CommandBLPop, domain.CommandBRPop:
+		return e.blockingPop(ctx, c)
+	default:
+		return "", fmt.Errorf("invalid list cmd type: %q", c.Type)
+	}
+}
+
+func (e executor) blockingPop(ctx context.Context, c domain.Command) (string, error) {
+	seconds, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return "", domain.ErrNotFloat
+	}
+	timeout := time.Duration(seconds * float64(time.Second))
+
+	pop := e.storage.BLPop
+	if c.Type == domain.CommandBRPop {
+		pop = e.storage.BRPop
+	}
+
+	kv, ok, err := pop(ctx, c.Args, timeout)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return nilResult, nil
+	}
+	return multiResult([]string{kv.Key, kv.Value}), nil
+}
+
+func rangeArgs(args []string) (int, int, error) {
+	start, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, 0, domain.ErrNotInteger
+	}
+	stop, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, 0, domain.ErrNotInteger
+	}
+	return start, stop, nil
+}
+
+// optionalResult formats a value which may be absent.
+func optionalResult(v string, ok bool, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return nilResult, nil
+	}
+	if v == "" {
+		return emptyResult, nil
+	}
+	return v, nil
+}
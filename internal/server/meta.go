@@ -0,0 +1,43 @@
+package server
+
+import (
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const pongResult = "PONG"
+
+// metaResult replies to commands describing the protocol and checking the
+// connection, none of them touches the storage.
+func metaResult(c domain.Command) (string, error) {
+	switch c.Type {
+	case domain.CommandPing:
+		if c.Value == "" {
+			return pongResult, nil
+		}
+		return c.Value, nil
+	case domain.CommandEcho:
+		return c.Value, nil
+	case domain.CommandHelp:
+		return helpResult(c.Args)
+	case domain.CommandCommand:
+		return multiResult(parser.CommandLines()), nil
+	default:
+		// QUIT replies OK, the session is closed once the reply is written
+		return "", nil
+	}
+}
+
+// helpResult lists usages of all commands or of the given one.
+func helpResult(args []string) (string, error) {
+	var t domain.CommandType
+	if len(args) == 1 {
+		t = domain.CommandType(args[0])
+	}
+
+	lines, err := parser.HelpLines(t)
+	if err != nil {
+		return "", err
+	}
+	return multiResult(lines), nil
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+)
+
+func TestHandler_Meta(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	server, client := net.Pipe()
+	t.Cleanup(func() { _ = client.Close() })
+
+	h := newHandler(log, nil, server, handlerConfig{timeout: time.Minute, bufferSize: 1024})
+
+	done := make(chan struct{})
+	go func() {
+		h.startHandling(context.Background())
+		close(done)
+	}()
+
+	reader := bufio.NewReader(client)
+	call := func(line string) string {
+		_, err := client.Write([]byte(line + "\n"))
+		require.NoError(t, err)
+		reply, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return reply
+	}
+	readLines := func(n string) []string {
+		count, err := strconv.Atoi(n[:len(n)-1])
+		require.NoError(t, err)
+		lines := make([]string, count)
+		for i := range lines {
+			lines[i], err = reader.ReadString('\n')
+			require.NoError(t, err)
+		}
+		return lines
+	}
+
+	require.Equal(t, "PONG\n", call("PING"))
+	require.Equal(t, "hello\n", call("PING hello"))
+	require.Equal(t, "hello\n", call("ECHO hello"))
+
+	require.Equal(t, []string{"SET key value\n"}, readLines(call("HELP SET")))
+	require.Len(t, readLines(call("HELP")), len(parser.Commands()))
+
+	commands := readLines(call("COMMAND"))
+	require.Len(t, commands, len(parser.Commands()))
+	require.Contains(t, commands, "GET 2\n")
+	require.Contains(t, commands, "MGET -2\n")
+	require.Contains(t, commands, "QUIT 1\n")
+
+	// the session ends after the reply and the server closes the connection
+	require.Equal(t, "OK\n", call("QUIT"))
+	<-done
+	require.NoError(t, server.Close())
+
+	_, err := reader.ReadString('\n')
+	require.ErrorIs(t, err, io.EOF)
+
+	// commands built without parsing are not trusted to name a command
+	_, err = helpResult([]string{"FLUSHALL"})
+	require.Error(t, err)
+}
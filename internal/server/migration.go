@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/internal/netaddr"
+	"github.com/tmvrus/key-value-storage/pkg/client"
+)
+
+const peerDialTimeout = 5 * time.Second
+
+var errDumpTooLarge = errors.New("value dump exceeds the max message size")
+
+// nodePeer sends migration commands to another node over the text protocol.
+type nodePeer struct {
+	conn   net.Conn
+	client *client.Client
+	// maxLine limits RESTORE commands, nodes share the max message size.
+	maxLine int
+}
+
+func newPeerDialer(maxLine int, l *slog.Logger) cluster.Dialer {
+	return func(address string) (cluster.Peer, error) {
+		conn, err := netaddr.Dial(address, peerDialTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("dial %q: %w", address, err)
+		}
+		return nodePeer{conn: conn, client: client.NewClient(conn, l), maxLine: maxLine}, nil
+	}
+}
+
+func (p nodePeer) SetSlot(_ context.Context, slot int, state, node string) error {
+	_, err := p.client.Do(string(domain.CommandCluster), domain.ClusterSetSlot, strconv.Itoa(slot), state, node)
+	return err
+}
+
+// Restore packs dumps into as few RESTORE commands as the line limit allows,
+// each preceded by ASKING as the slot is importing on the peer.
+func (p nodePeer) Restore(_ context.Context, dumps []cluster.KeyDump) error {
+	args := []string{string(domain.CommandRestore)}
+	size := len(domain.CommandRestore)
+
+	flush := func() error {
+		if len(args) == 1 {
+			return nil
+		}
+		if _, err := p.client.Do(string(domain.CommandAsking)); err != nil {
+			return err
+		}
+		if _, err := p.client.Do(args...); err != nil {
+			return err
+		}
+		args, size = args[:1], len(domain.CommandRestore)
+		return nil
+	}
+
+	for _, d := range dumps {
+		data := base64.StdEncoding.EncodeToString(d.Data)
+		n := len(d.Key) + len(data) + 2
+		if len(domain.CommandRestore)+n >= p.maxLine {
+			return fmt.Errorf("key %q: %w", d.Key, errDumpTooLarge)
+		}
+		if size+n >= p.maxLine {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		args = append(args, d.Key, data)
+		size += n
+	}
+
+	return flush()
+}
+
+func (p nodePeer) Close() error {
+	return p.conn.Close()
+}
+
+// setSlot applies CLUSTER SETSLOT sent by the migrating node.
+func (a handler) setSlot(args []string) error {
+	slot, err := parseSingleSlot(args[0])
+	if err != nil {
+		return err
+	}
+
+	switch args[1] {
+	case domain.SlotImporting:
+		return a.cluster.SetImporting(slot, args[2])
+	case domain.SlotMigrating:
+		return a.cluster.SetMigrating(slot, args[2])
+	case domain.SlotNode:
+		return a.cluster.SetOwner(slot, args[2])
+	case domain.SlotStable:
+		return a.cluster.SetStable(slot)
+	default:
+		return fmt.Errorf("invalid slot state: %q", args[1])
+	}
+}
+
+// migrate runs migrations with the server context, they outlive the session
+// and must not stop when the session is killed.
+func (a handler) migrate(args []string) (string, error) {
+	if a.migrator == nil {
+		return "", errClusterDisabled
+	}
+
+	switch args[0] {
+	case domain.MigrateStatus:
+		return migrationResult(a.migrator.Progress()), nil
+	case domain.MigrateResume:
+		return "", a.migrator.Resume(a.serverCtx)
+	default:
+		r, err := cluster.ParseSlotRange(args[0])
+		if err != nil {
+			return "", err
+		}
+		return "", a.migrator.Start(a.serverCtx, r, args[1])
+	}
+}
+
+// migrationResult gives a "name value" line per progress field.
+func migrationResult(p cluster.Progress) string {
+	lines := []string{"state " + string(p.State)}
+	if p.State != cluster.MigrationIdle {
+		lines = append(lines,
+			"range "+p.Range.String(),
+			"target "+p.Target,
+			"slot "+strconv.Itoa(p.Slot),
+			"slots_done "+strconv.Itoa(p.SlotsDone),
+			"keys_moved "+strconv.Itoa(p.KeysMoved),
+		)
+	}
+	if p.Error != "" {
+		lines = append(lines, "error "+strings.ReplaceAll(p.Error, "\n", " "))
+	}
+	return multiResult(lines)
+}
+
+func parseSingleSlot(s string) (int, error) {
+	r, err := cluster.ParseSlotRange(s)
+	if err != nil {
+		return 0, err
+	}
+	if r.Start != r.End {
+		return 0, fmt.Errorf("invalid slot %q", s)
+	}
+	return r.Start, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+	"github.com/tmvrus/key-value-storage/pkg/client"
+)
+
+func TestNodePeer(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	// newPeer returns the peer and the channel of lines the node receives,
+	// the node replies OK to every line.
+	newPeer := func(t *testing.T, maxLine int) (nodePeer, <-chan string) {
+		conn, node := net.Pipe()
+		t.Cleanup(func() {
+			_ = conn.Close()
+			_ = node.Close()
+		})
+
+		lines := make(chan string, 16)
+		go func() {
+			defer close(lines)
+			input := bufio.NewScanner(node)
+			for input.Scan() {
+				lines <- input.Text()
+				if _, err := node.Write([]byte("OK\n")); err != nil {
+					return
+				}
+			}
+		}()
+
+		return nodePeer{conn: conn, client: client.NewClient(conn, log), maxLine: maxLine}, lines
+	}
+
+	t.Run("sets slot", func(t *testing.T) {
+		t.Parallel()
+
+		p, lines := newPeer(t, 1024)
+		require.NoError(t, p.SetSlot(context.Background(), 42, "IMPORTING", "a"))
+		require.Equal(t, "CLUSTER SETSLOT 42 IMPORTING a", <-lines)
+	})
+
+	t.Run("splits restore by line limit", func(t *testing.T) {
+		t.Parallel()
+
+		p, lines := newPeer(t, 40)
+		dumps := []cluster.KeyDump{
+			{Key: "a", Data: []byte("first")},
+			{Key: "b", Data: []byte("second")},
+			{Key: "c", Data: []byte("third")},
+		}
+		require.NoError(t, p.Restore(context.Background(), dumps))
+
+		require.Equal(t, "ASKING", <-lines)
+		require.Equal(t, "RESTORE a Zmlyc3Q= b c2Vjb25k", <-lines)
+		require.Equal(t, "ASKING", <-lines)
+		require.Equal(t, "RESTORE c dGhpcmQ=", <-lines)
+	})
+
+	t.Run("rejects dump over line limit", func(t *testing.T) {
+		t.Parallel()
+
+		p, _ := newPeer(t, 16)
+		err := p.Restore(context.Background(), []cluster.KeyDump{{Key: "a", Data: []byte("too large value")}})
+		require.ErrorIs(t, err, errDumpTooLarge)
+	})
+
+	t.Run("dials unix socket", func(t *testing.T) {
+		t.Parallel()
+
+		socket := filepath.Join(t.TempDir(), "node.sock")
+		l, err := net.Listen("unix", socket)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = l.Close() })
+
+		lines := make(chan string, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			input := bufio.NewScanner(conn)
+			if input.Scan() {
+				lines <- input.Text()
+				_, _ = conn.Write([]byte("OK\n"))
+			}
+		}()
+
+		p, err := newPeerDialer(1024, log)("unix://" + socket)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = p.Close() })
+		require.NoError(t, p.SetSlot(context.Background(), 7, "IMPORTING", "a"))
+		require.Equal(t, "CLUSTER SETSLOT 7 IMPORTING a", <-lines)
+	})
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// monitorBufferSize is the number of commands a monitor may lag behind before it is closed.
+const monitorBufferSize = 1024
+
+var (
+	errMonitorLagged     = errors.New("monitor is too slow to keep up with commands")
+	errMonitorDisabled   = errors.New("monitor is disabled")
+	errMonitorTextClient = errors.New("MONITOR is supported by text protocol sessions only")
+)
+
+// monitorHub delivers every executed command to monitors without blocking
+// the sessions executing them: a monitor with a full buffer is closed and dropped.
+type monitorHub struct {
+	lock     sync.Mutex
+	monitors map[*monitor]struct{}
+}
+
+type monitor struct {
+	// session is the session turned into the monitor, its commands are not delivered.
+	session *session
+	lines   chan string
+	// overflowed is set before lines is closed when the monitor lagged behind.
+	overflowed bool
+}
+
+func newMonitorHub() *monitorHub {
+	return &monitorHub{monitors: make(map[*monitor]struct{})}
+}
+
+func (h *monitorHub) subscribe(s *session) *monitor {
+	m := &monitor{session: s, lines: make(chan string, monitorBufferSize)}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.monitors[m] = struct{}{}
+	return m
+}
+
+func (h *monitorHub) unsubscribe(m *monitor) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.monitors[m]; ok {
+		delete(h.monitors, m)
+		close(m.lines)
+	}
+}
+
+// publish delivers the command of the session to monitors, nil hub does nothing.
+func (h *monitorHub) publish(s *session, c domain.Command) {
+	if h == nil {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if len(h.monitors) == 0 {
+		return
+	}
+
+	line := monitorLine(time.Now(), s.client, c)
+	for m := range h.monitors {
+		if m.session == s {
+			continue
+		}
+		select {
+		case m.lines <- line:
+		default:
+			m.overflowed = true
+			delete(h.monitors, m)
+			close(m.lines)
+		}
+	}
+}
+
+// monitorLine formats the command as the unix time with microseconds, the
+// client address and the command.
+func monitorLine(t time.Time, client string, c domain.Command) string {
+	return fmt.Sprintf("%d.%06d %s %s", t.Unix(), t.Nanosecond()/int(time.Microsecond), client,
+		strings.Join(displayFields(c), " "))
+}
+
+// monitor turns the session into a stream of commands executed by other
+// sessions until the client disconnects or the context is done.
+func (a handler) monitor(ctx context.Context) error {
+	if a.monitors == nil {
+		return a.writeError(errMonitorDisabled)
+	}
+
+	m := a.monitors.subscribe(a.session)
+	defer a.monitors.unsubscribe(m)
+
+	if err := a.writeResult(""); err != nil {
+		return err
+	}
+
+	// input is ignored, reading only detects the client is gone
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := a.conn.SetReadDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("reset read deadline: %w", err)
+	}
+	go func() {
+		defer cancel()
+		buf := make([]byte, 512)
+		for {
+			if _, err := a.conn.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-m.lines:
+			if !ok {
+				if m.overflowed {
+					return a.writeError(errMonitorLagged)
+				}
+				return nil
+			}
+			if err := a.writeStringLn(line); err != nil {
+				return err
+			}
+		}
+	}
+}
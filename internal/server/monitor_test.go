@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMonitorHub(t *testing.T) {
+	t.Parallel()
+
+	h := newMonitorHub()
+	s := &session{client: "10.0.0.1:5000"}
+	own := h.subscribe(s)
+	m := h.subscribe(&session{})
+
+	h.publish(s, domain.Command{Type: domain.CommandGet, Key: "k"})
+	require.Empty(t, own.lines)
+	require.Regexp(t, `^\d+\.\d{6} 10\.0\.0\.1:5000 GET k$`, <-m.lines)
+
+	h.publish(s, domain.Command{Type: domain.CommandAuth, Args: []string{"alice", "secret"}})
+	require.Regexp(t, `AUTH alice \(redacted\)$`, <-m.lines)
+
+	for range monitorBufferSize + 1 {
+		h.publish(s, domain.Command{Type: domain.CommandGet, Key: "k"})
+	}
+	for range m.lines {
+	}
+	require.True(t, m.overflowed)
+
+	h.unsubscribe(own)
+	require.Empty(t, h.monitors)
+
+	var nilHub *monitorHub
+	nilHub.publish(s, domain.Command{Type: domain.CommandGet, Key: "k"})
+}
+
+func TestHandler_Monitor(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	monitors := newMonitorHub()
+
+	server, client := net.Pipe()
+	t.Cleanup(func() { _ = client.Close() })
+
+	h := newHandler(log, nil, server, handlerConfig{timeout: time.Minute, bufferSize: 1024})
+	h.monitors = monitors
+
+	done := make(chan struct{})
+	go func() {
+		h.startHandling(context.Background())
+		close(done)
+	}()
+
+	reader := bufio.NewReader(client)
+	_, err := client.Write([]byte("MONITOR\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "OK\n", line)
+
+	ctrl := gomock.NewController(t)
+	storMock := NewMockstorage(ctrl)
+	storMock.EXPECT().Set(gomock.Any(), "a", "1").Return(nil)
+
+	other := newHandler(log, storMock, nil, handlerConfig{})
+	other.monitors = monitors
+	other.session.client = "10.0.0.2:5000"
+
+	_, err = other.doCmd(context.Background(), domain.Command{Type: domain.CommandSet, Key: "a", Value: "1"})
+	require.NoError(t, err)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Regexp(t, `^\d+\.\d{6} 10\.0\.0\.2:5000 SET a 1\n$`, line)
+
+	_, err = other.doCmd(context.Background(), domain.Command{Type: domain.CommandMonitor})
+	require.ErrorIs(t, err, errMonitorTextClient)
+
+	require.NoError(t, client.Close())
+	<-done
+	require.Empty(t, monitors.monitors)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+var errRateLimited = errors.New("rate limited")
+
+// tokenBucket refills rate tokens per second and holds a second worth of
+// them, zero rate is unlimited.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// available reports whether n tokens may be taken, a request bigger than the
+// bucket needs it full.
+func (b *tokenBucket) available(n float64, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate == 0 {
+		return true
+	}
+	b.refill(now)
+	return b.tokens >= min(n, b.rate)
+}
+
+// reserve takes n tokens, going into debt when there are not enough of them,
+// and returns how long to wait until the debt is paid off.
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate == 0 {
+		return 0
+	}
+	b.refill(now)
+	b.tokens -= min(n, b.rate)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// setRate changes the rate keeping the tokens the bucket has, up to the new size.
+func (b *tokenBucket) setRate(rate float64, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	if b.rate == 0 {
+		b.tokens = rate
+	}
+	b.rate = rate
+	b.tokens = min(b.tokens, rate)
+}
+
+// clientLimit is shared by all sessions of the same IP or the same user.
+type clientLimit struct {
+	key      string
+	sessions int
+	commands *tokenBucket
+	bytes    *tokenBucket
+}
+
+func newClientLimit(key string, rate config.Rate, now time.Time) *clientLimit {
+	return &clientLimit{
+		key:      key,
+		commands: newTokenBucket(rate.Commands, now),
+		bytes:    newTokenBucket(float64(rate.Bytes), now),
+	}
+}
+
+func (l *clientLimit) setRate(rate config.Rate, now time.Time) {
+	l.commands.setRate(rate.Commands, now)
+	l.bytes.setRate(float64(rate.Bytes), now)
+}
+
+type bucketRequest struct {
+	bucket *tokenBucket
+	n      float64
+}
+
+// rateLimiter keeps limits of connected clients, a limit is dropped along
+// with the last session using it. Limits may be changed while clients are
+// connected.
+type rateLimiter struct {
+	throttle atomic.Bool
+
+	mu      sync.Mutex
+	perIP   config.Rate
+	perUser config.Rate
+	ips     map[string]*clientLimit
+	users   map[string]*clientLimit
+}
+
+func newRateLimiter(cfg *config.Config) *rateLimiter {
+	r := &rateLimiter{
+		perIP:   cfg.RateLimit.PerIP,
+		perUser: cfg.RateLimit.PerUser,
+		ips:     make(map[string]*clientLimit),
+		users:   make(map[string]*clientLimit),
+	}
+	r.throttle.Store(cfg.RateLimit.Throttle)
+	return r
+}
+
+// update applies limits of the config to connected clients as well.
+func (r *rateLimiter) update(cfg *config.Config) {
+	r.throttle.Store(cfg.RateLimit.Throttle)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.perIP = cfg.RateLimit.PerIP
+	for _, l := range r.ips {
+		l.setRate(r.perIP, now)
+	}
+	r.perUser = cfg.RateLimit.PerUser
+	for _, l := range r.users {
+		l.setRate(r.perUser, now)
+	}
+}
+
+// acquireIP returns the limit of the IP, nil when the client has no IP like
+// unix socket clients.
+func (r *rateLimiter) acquireIP(ip string) *clientLimit {
+	if ip == "" {
+		return nil
+	}
+	return r.acquire(r.ips, &r.perIP, ip)
+}
+
+// acquireUser returns the limit of the authenticated user.
+func (r *rateLimiter) acquireUser(name string) *clientLimit {
+	return r.acquire(r.users, &r.perUser, name)
+}
+
+// acquire takes the rate by pointer as it is read under the lock.
+func (r *rateLimiter) acquire(limits map[string]*clientLimit, rate *config.Rate, key string) *clientLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := limits[key]
+	if !ok {
+		l = newClientLimit(key, *rate, time.Now())
+		limits[key] = l
+	}
+	l.sessions++
+	return l
+}
+
+func (r *rateLimiter) releaseIP(l *clientLimit) {
+	r.release(r.ips, l)
+}
+
+func (r *rateLimiter) releaseUser(l *clientLimit) {
+	r.release(r.users, l)
+}
+
+func (r *rateLimiter) release(limits map[string]*clientLimit, l *clientLimit) {
+	if l == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l.sessions--
+	if l.sessions == 0 {
+		delete(limits, l.key)
+	}
+}
+
+// wait admits a command of the size against the limits. Over the limit the
+// command is either rejected with errRateLimited or delayed until the
+// tokens are refilled, unless the context is done first.
+func (r *rateLimiter) wait(ctx context.Context, size int, limits ...*clientLimit) error {
+	requests := make([]bucketRequest, 0, 2*len(limits))
+	for _, l := range limits {
+		if l != nil {
+			requests = append(requests, bucketRequest{bucket: l.commands, n: 1}, bucketRequest{bucket: l.bytes, n: float64(size)})
+		}
+	}
+
+	now := time.Now()
+	if !r.throttle.Load() {
+		for _, req := range requests {
+			if !req.bucket.available(req.n, now) {
+				return errRateLimited
+			}
+		}
+	}
+
+	var delay time.Duration
+	for _, req := range requests {
+		delay = max(delay, req.bucket.reserve(req.n, now))
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// remoteIP returns the IP of a TCP client, empty for other clients.
+func remoteIP(conn net.Conn) string {
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.IP.String()
+	}
+	return ""
+}
+
+// limit admits the next command of the session, size is the command line length.
+func (a handler) limit(ctx context.Context, size int) error {
+	if a.limiter == nil {
+		return nil
+	}
+	return a.limiter.wait(ctx, size, a.session.ipLimit, a.session.userLimit)
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	b := newTokenBucket(10, now)
+
+	require.True(t, b.available(10, now))
+	require.Zero(t, b.reserve(8, now))
+	require.False(t, b.available(3, now))
+	require.True(t, b.available(3, now.Add(100*time.Millisecond)))
+
+	// bigger than the bucket needs it full
+	require.False(t, b.available(100, now.Add(100*time.Millisecond)))
+	require.True(t, b.available(100, now.Add(time.Second)))
+
+	b = newTokenBucket(10, now)
+	require.Zero(t, b.reserve(10, now))
+	require.Equal(t, 500*time.Millisecond, b.reserve(5, now))
+	require.Equal(t, time.Second, b.reserve(5, now))
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	newLimiter := func(throttle bool) *rateLimiter {
+		cfg := &config.Config{}
+		cfg.RateLimit.PerIP = config.Rate{Commands: 2, Bytes: 100}
+		cfg.RateLimit.PerUser = config.Rate{Commands: 1}
+		cfg.RateLimit.Throttle = throttle
+		return newRateLimiter(cfg)
+	}
+
+	t.Run("unlimited without limits", func(t *testing.T) {
+		t.Parallel()
+
+		r := newRateLimiter(&config.Config{})
+		ip := r.acquireIP("10.0.0.1")
+		for range 100 {
+			require.NoError(t, r.wait(context.Background(), 1000, ip))
+		}
+	})
+
+	t.Run("updates limits of connected clients", func(t *testing.T) {
+		t.Parallel()
+
+		r := newRateLimiter(&config.Config{})
+		ctx := context.Background()
+		ip := r.acquireIP("10.0.0.1")
+		require.NoError(t, r.wait(ctx, 10, ip))
+
+		cfg := &config.Config{}
+		cfg.RateLimit.PerIP = config.Rate{Commands: 1}
+		r.update(cfg)
+		require.NoError(t, r.wait(ctx, 10, ip))
+		require.ErrorIs(t, r.wait(ctx, 10, ip), errRateLimited)
+
+		r.update(&config.Config{})
+		require.NoError(t, r.wait(ctx, 10, ip))
+	})
+
+	t.Run("rejects over the limit", func(t *testing.T) {
+		t.Parallel()
+
+		r := newLimiter(false)
+		ctx := context.Background()
+
+		ip := r.acquireIP("10.0.0.1")
+		require.Same(t, ip, r.acquireIP("10.0.0.1"))
+		require.NotSame(t, ip, r.acquireIP("10.0.0.2"))
+		require.Nil(t, r.acquireIP(""))
+
+		require.NoError(t, r.wait(ctx, 10, ip))
+		require.NoError(t, r.wait(ctx, 10, ip))
+		require.ErrorIs(t, r.wait(ctx, 10, ip), errRateLimited)
+
+		other := r.acquireIP("10.0.0.3")
+		require.NoError(t, r.wait(ctx, 60, other))
+		require.ErrorIs(t, r.wait(ctx, 60, other), errRateLimited)
+
+		user := r.acquireUser("alice")
+		require.NoError(t, r.wait(ctx, 10, nil, user))
+		require.ErrorIs(t, r.wait(ctx, 10, nil, user), errRateLimited)
+	})
+
+	t.Run("throttles over the limit", func(t *testing.T) {
+		t.Parallel()
+
+		r := newLimiter(true)
+		user := r.acquireUser("alice")
+
+		start := time.Now()
+		require.NoError(t, r.wait(context.Background(), 10, nil, user))
+		require.NoError(t, r.wait(context.Background(), 10, nil, user))
+		require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, r.wait(ctx, 10, nil, user), context.Canceled)
+	})
+
+	t.Run("drops limits of gone clients", func(t *testing.T) {
+		t.Parallel()
+
+		r := newLimiter(false)
+		a := r.acquireIP("10.0.0.1")
+		b := r.acquireIP("10.0.0.1")
+
+		r.releaseIP(a)
+		require.Len(t, r.ips, 1)
+		r.releaseIP(b)
+		require.Empty(t, r.ips)
+
+		r.releaseUser(nil)
+	})
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	cfg := &config.Config{}
+	cfg.Auth.Users = []config.User{{Name: "alice", Password: "secret"}}
+	cfg.RateLimit.PerUser = config.Rate{Commands: 1}
+
+	t.Run("authenticates users", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHandler(log, nil, nil, handlerConfig{})
+		h.users = newUsers(cfg.Auth.Users)
+		h.limiter = newRateLimiter(cfg)
+
+		for _, in := range []string{"AUTH alice wrong", "AUTH bob secret"} {
+			cmd, err := parser.Parse(in)
+			require.NoError(t, err)
+			_, err = h.doCmd(context.Background(), cmd)
+			require.ErrorIs(t, err, errInvalidCredentials)
+		}
+		require.Empty(t, h.session.stats.user)
+
+		cmd, err := parser.Parse("AUTH alice secret")
+		require.NoError(t, err)
+		out, err := h.doCmd(context.Background(), cmd)
+		require.NoError(t, err)
+		require.Empty(t, out)
+		require.Equal(t, "alice", h.session.stats.user)
+		require.NotNil(t, h.session.userLimit)
+	})
+
+	t.Run("requires auth for admin commands", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHandler(log, nil, nil, handlerConfig{})
+		h.users = newUsers(cfg.Auth.Users)
+		do := func(in string) error {
+			cmd, err := parser.Parse(in)
+			require.NoError(t, err)
+			_, err = h.doCmd(context.Background(), cmd)
+			return err
+		}
+
+		for _, in := range []string{"SLOWLOG RESET", "CLIENT KILL ID 1", "RAFT REMOVE n2", "CLUSTER SETSLOT 1 STABLE"} {
+			require.ErrorIs(t, do(in), errAuthRequired, in)
+		}
+		require.ErrorIs(t, do("SLOWLOG LEN"), errSlowLogDisabled)
+
+		require.NoError(t, do("AUTH alice secret"))
+		require.ErrorIs(t, do("SLOWLOG RESET"), errSlowLogDisabled)
+
+		h.users = nil
+		h.session.stats.user = ""
+		require.ErrorIs(t, do("SLOWLOG RESET"), errSlowLogDisabled)
+	})
+
+	t.Run("limits authenticated session", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Set(gomock.Any(), "a", "1").Return(nil)
+
+		server, client := net.Pipe()
+		t.Cleanup(func() { _ = client.Close() })
+
+		h := newHandler(log, storMock, server, handlerConfig{timeout: time.Minute, bufferSize: 1024})
+		h.users = newUsers(cfg.Auth.Users)
+		h.limiter = newRateLimiter(cfg)
+
+		done := make(chan struct{})
+		go func() {
+			h.startHandling(context.Background())
+			close(done)
+		}()
+
+		reader := bufio.NewReader(client)
+		for _, step := range []struct{ in, want string }{
+			{"AUTH alice secret", "OK"},
+			{"SET a 1", "OK"},
+			{"SET a 1", "ERROR: rate limited"},
+		} {
+			_, err := client.Write([]byte(step.in + "\n"))
+			require.NoError(t, err)
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			require.Equal(t, step.want+"\n", line, step.in)
+		}
+
+		require.NoError(t, client.Close())
+		<-done
+		require.Empty(t, h.limiter.users)
+	})
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"slices"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+// liveSettings are the settings Reload changes, they apply to sessions
+// started after the reload.
+type liveSettings struct {
+	idleTimeout    time.Duration
+	maxMessageSize int
+	// cfg is the config loaded last, settings needing a restart included.
+	cfg *config.Config
+}
+
+func newLiveSettings(cfg *config.Config) *liveSettings {
+	return &liveSettings{
+		idleTimeout:    cfg.Network.IdleTimeout,
+		maxMessageSize: cfg.Network.MaxMessageSize.Int(),
+		cfg:            cfg,
+	}
+}
+
+// Reload applies reloadable settings of the validated config: idle timeout and
+// max message size to new text protocol sessions, max connections and rate
+// limits right away. Changes of other settings are logged as they need a restart,
+// the HTTP gateway and the gRPC service keep network limits they started with.
+func (s Server) Reload(cfg *config.Config) {
+	last := s.live.Swap(newLiveSettings(cfg)).cfg
+	s.sessionLimiter.resize(int(cfg.Network.MaxConnections))
+	s.limiter.update(cfg)
+
+	for _, name := range restartPending(s.cfg, last, cfg) {
+		s.log.Warn("changed setting needs a restart to take effect", "setting", name)
+	}
+	s.log.Info("config reloaded",
+		"idle_timeout", cfg.Network.IdleTimeout.String(),
+		"max_message_size", cfg.Network.MaxMessageSize.Int(),
+		"max_connections", cfg.Network.MaxConnections,
+	)
+}
+
+// restartPending lists settings of the next config differing from the running
+// ones, skipping changes reported by the reload of the last config already.
+func restartPending(running, last, next *config.Config) []string {
+	changed := config.RestartRequired(last, next)
+	return slices.DeleteFunc(config.RestartRequired(running, next), func(name string) bool {
+		return !slices.Contains(changed, name)
+	})
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+func TestSessionLimiter(t *testing.T) {
+	t.Parallel()
+
+	l := newSessionLimiter(2)
+	require.True(t, l.acquire())
+	require.True(t, l.acquire())
+	require.False(t, l.acquire())
+
+	l.resize(1)
+	l.release()
+	require.False(t, l.acquire())
+	l.release()
+	require.True(t, l.acquire())
+
+	l.resize(3)
+	require.True(t, l.acquire())
+	require.True(t, l.acquire())
+	require.False(t, l.acquire())
+}
+
+func TestServer_Reload(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	cfg := config.NewConfigWithDefaults()
+	cfg.Network.MaxConnections = 1
+	srv := New(cfg, nil, log)
+
+	next := config.NewConfigWithDefaults()
+	next.Network.MaxConnections = 2
+	next.Network.IdleTimeout = time.Hour
+	next.Network.MaxMessageSize = 4096
+	next.RateLimit.PerIP = config.Rate{Commands: 1}
+	next.Network.Address = "127.0.0.1:4000"
+	srv.Reload(next)
+
+	require.Equal(t, &liveSettings{idleTimeout: time.Hour, maxMessageSize: 4096, cfg: next}, srv.live.Load())
+	require.True(t, srv.sessionLimiter.acquire())
+	require.True(t, srv.sessionLimiter.acquire())
+	require.Equal(t, config.Rate{Commands: 1}, srv.limiter.perIP)
+	// settings needing a restart are not applied
+	require.Equal(t, "127.0.0.1:3223", srv.cfg.Network.Address)
+}
+
+func TestServer_ReloadWarnsOnce(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	srv := New(config.NewConfigWithDefaults(), nil, slog.New(slog.NewTextHandler(&out, nil)))
+	reload := func(address string, maxConnections uint) string {
+		out.Reset()
+		next := config.NewConfigWithDefaults()
+		next.Network.Address = address
+		next.Network.MaxConnections = maxConnections
+		srv.Reload(next)
+		return out.String()
+	}
+
+	require.Contains(t, reload("127.0.0.1:4000", 10), "setting=network.address")
+	// already reported
+	require.NotContains(t, reload("127.0.0.1:4000", 20), "needs a restart")
+	// back to the running value
+	require.NotContains(t, reload("127.0.0.1:3223", 20), "needs a restart")
+	require.Contains(t, reload("127.0.0.1:4001", 20), "setting=network.address")
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/internal/netaddr"
+	"github.com/tmvrus/key-value-storage/internal/raft"
+)
+
+var (
+	errRaftDisabled       = errors.New("raft is disabled")
+	errBlockingReplicated = errors.New("blocking commands are not supported with raft enabled")
+)
+
+// replicatedCommand is a raft log entry. Time is taken by the leader, so all
+// members generate the same time-based values like stream IDs.
+type replicatedCommand struct {
+	Command domain.Command
+	Time    time.Time
+}
+
+type commandResult struct {
+	value string
+	err   error
+}
+
+// commandFSM executes committed commands against the local storage.
+type commandFSM struct {
+	executor executor
+}
+
+func (f commandFSM) Apply(data []byte) any {
+	var rc replicatedCommand
+	if err := json.Unmarshal(data, &rc); err != nil {
+		return commandResult{err: fmt.Errorf("decode replicated command: %w", err)}
+	}
+
+	ctx := domain.ContextWithNow(context.Background(), rc.Time)
+	value, err := f.executor.execute(ctx, rc.Command)
+	return commandResult{value: value, err: err}
+}
+
+func (f commandFSM) Snapshot() ([]byte, error) {
+	return f.executor.storage.Snapshot(context.Background())
+}
+
+func (f commandFSM) Restore(data []byte) error {
+	return f.executor.storage.Restore(context.Background(), data)
+}
+
+// raftReplicator acknowledges mutating commands after a quorum commits them,
+// followers answer with the leader address instead. Reads are served by every
+// member from its local storage and may be stale on followers.
+type raftReplicator struct {
+	log     *slog.Logger
+	address string
+	// socketMode is the mode of the unix socket the node listens on.
+	socketMode os.FileMode
+	dataDir    string
+	node       *raft.Node
+	transport  *raft.TCPTransport
+}
+
+func newRaftReplicator(cfg *config.Config, e executor, l *slog.Logger) *raftReplicator {
+	members := make([]raft.Member, 0, len(cfg.Raft.Members))
+	for _, m := range cfg.Raft.Members {
+		members = append(members, raft.Member{ID: m.ID, Address: m.Address, ClientAddress: m.ClientAddress})
+	}
+
+	transport := raft.NewTCPTransport(cfg.Raft.HeartbeatInterval)
+	node := raft.New(raft.Config{
+		ID:                cfg.Raft.NodeID,
+		Members:           members,
+		ElectionTimeout:   cfg.Raft.ElectionTimeout,
+		HeartbeatInterval: cfg.Raft.HeartbeatInterval,
+		SnapshotThreshold: cfg.Raft.SnapshotThreshold,
+	}, commandFSM{executor: e}, transport, l)
+
+	return &raftReplicator{
+		log:        l,
+		address:    cfg.Raft.Address,
+		socketMode: os.FileMode(cfg.Network.SocketMode),
+		dataDir:    cfg.Raft.DataDir,
+		node:       node,
+		transport:  transport,
+	}
+}
+
+// start loads the saved state of the node, then accepts raft RPCs and runs
+// the node until the context is done.
+func (r *raftReplicator) start(ctx context.Context) error {
+	if r.dataDir != "" {
+		if err := r.node.Persist(r.dataDir); err != nil {
+			return fmt.Errorf("raft state: %w", err)
+		}
+	}
+
+	l, err := netaddr.Listen(r.address, r.socketMode)
+	if err != nil {
+		return fmt.Errorf("raft listen: %w", err)
+	}
+
+	r.log.Debug("ready to accept raft rpc", "address", r.address)
+
+	go raft.Serve(l, r.node)
+	go func() {
+		if err := r.node.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			r.log.Error("raft node stopped", "error", err.Error())
+		}
+		if err := l.Close(); err != nil {
+			r.log.Error("failed to close raft listener", "error", err.Error())
+		}
+		r.transport.Close()
+	}()
+
+	return nil
+}
+
+func (r *raftReplicator) Apply(ctx context.Context, c domain.Command) (string, error) {
+	data, err := json.Marshal(replicatedCommand{Command: c, Time: time.Now()})
+	if err != nil {
+		return "", fmt.Errorf("encode replicated command: %w", err)
+	}
+
+	res, err := r.node.Apply(ctx, data)
+	if err != nil {
+		return "", replicationError(err)
+	}
+
+	result := res.(commandResult)
+	return result.value, result.err
+}
+
+func (r *raftReplicator) AddMember(ctx context.Context, m raft.Member) error {
+	return replicationError(r.node.AddMember(ctx, m))
+}
+
+func (r *raftReplicator) RemoveMember(ctx context.Context, id string) error {
+	return replicationError(r.node.RemoveMember(ctx, id))
+}
+
+func (r *raftReplicator) Status() raft.Status {
+	return r.node.Status()
+}
+
+// replicationError redirects clients of a follower to the leader.
+func replicationError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var notLeader raft.NotLeaderError
+	if errors.As(err, &notLeader) {
+		if notLeader.Leader.ClientAddress == "" {
+			return domain.ErrNotLeader
+		}
+		return fmt.Errorf("%w %s", domain.ErrNotLeader, notLeader.Leader.ClientAddress)
+	}
+	return fmt.Errorf("replicate: %w", err)
+}
+
+func (a handler) doRaftCmd(ctx context.Context, c domain.Command) (string, error) {
+	if a.replicator == nil {
+		return "", errRaftDisabled
+	}
+
+	switch c.Args[0] {
+	case domain.RaftStatus:
+		return raftStatusResult(a.replicator.Status()), nil
+	case domain.RaftAdd:
+		m := raft.Member{ID: c.Args[1], Address: c.Args[2], ClientAddress: c.Args[3]}
+		return "", a.replicator.AddMember(ctx, m)
+	case domain.RaftRemove:
+		return "", a.replicator.RemoveMember(ctx, c.Args[1])
+	default:
+		return "", fmt.Errorf("invalid raft subcommand: %q", c.Args[0])
+	}
+}
+
+// raftStatusResult lists node state lines followed by a line per member.
+func raftStatusResult(s raft.Status) string {
+	lines := []string{
+		"id " + s.ID,
+		"state " + s.State.String(),
+		"term " + strconv.FormatUint(s.Term, 10),
+		"leader " + s.Leader,
+		"commit_index " + strconv.FormatUint(s.CommitIndex, 10),
+		"last_applied " + strconv.FormatUint(s.LastApplied, 10),
+	}
+	for _, m := range s.Members {
+		lines = append(lines, "member "+m.ID+" "+m.Address+" "+m.ClientAddress)
+	}
+	return multiResult(lines)
+}
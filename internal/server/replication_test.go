@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"github.com/tmvrus/key-value-storage/internal/raft"
+	"github.com/tmvrus/key-value-storage/internal/storage/engine/inmemory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Replication(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	tt := []struct {
+		name   string
+		in     string
+		expect func(st *MockstorageMockRecorder, r *MockreplicatorMockRecorder)
+		out    string
+		err    string
+	}{
+		{
+			name: "mutating command is replicated",
+			in:   "SET KEY VALUE",
+			expect: func(_ *MockstorageMockRecorder, r *MockreplicatorMockRecorder) {
+				r.Apply(gomock.Any(), domain.Command{Type: domain.CommandSet, Key: "KEY", Value: "VALUE"}).Return("", nil)
+			},
+		},
+		{
+			name: "follower redirects to the leader",
+			in:   "DELETE KEY",
+			expect: func(_ *MockstorageMockRecorder, r *MockreplicatorMockRecorder) {
+				r.Apply(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("%w 127.0.0.1:3224", domain.ErrNotLeader))
+			},
+			err: "NOTLEADER 127.0.0.1:3224",
+		},
+		{
+			name: "read is served locally",
+			in:   "GET KEY",
+			expect: func(st *MockstorageMockRecorder, _ *MockreplicatorMockRecorder) {
+				st.Get(gomock.Any(), "KEY").Return("VALUE", nil)
+			},
+			out: "VALUE",
+		},
+		{
+			name:   "blocking command is rejected",
+			in:     "BLPOP KEY 0",
+			expect: func(*MockstorageMockRecorder, *MockreplicatorMockRecorder) {},
+			err:    errBlockingReplicated.Error(),
+		},
+		{
+			name: "status lists members",
+			in:   "RAFT STATUS",
+			expect: func(_ *MockstorageMockRecorder, r *MockreplicatorMockRecorder) {
+				r.Status().Return(raft.Status{
+					ID:          "n1",
+					State:       raft.Leader,
+					Term:        2,
+					Leader:      "n1",
+					CommitIndex: 5,
+					LastApplied: 4,
+					Members:     []raft.Member{{ID: "n1", Address: "127.0.0.1:4001", ClientAddress: "127.0.0.1:3223"}},
+				})
+			},
+			out: "7\nid n1\nstate leader\nterm 2\nleader n1\ncommit_index 5\nlast_applied 4\nmember n1 127.0.0.1:4001 127.0.0.1:3223",
+		},
+		{
+			name: "member is added",
+			in:   "RAFT ADD n2 127.0.0.1:4002 127.0.0.1:3224",
+			expect: func(_ *MockstorageMockRecorder, r *MockreplicatorMockRecorder) {
+				r.AddMember(gomock.Any(), raft.Member{ID: "n2", Address: "127.0.0.1:4002", ClientAddress: "127.0.0.1:3224"}).Return(nil)
+			},
+		},
+	}
+
+	for _, c := range tt {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			storMock := NewMockstorage(ctrl)
+			replMock := NewMockreplicator(ctrl)
+			c.expect(storMock.EXPECT(), replMock.EXPECT())
+
+			h := newHandler(log, storMock, nil, handlerConfig{})
+			h.replicator = replMock
+
+			cmd, err := parser.Parse(c.in)
+			require.NoError(t, err)
+
+			out, err := h.doCmd(context.Background(), cmd)
+			if c.err != "" {
+				require.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.out, out)
+		})
+	}
+}
+
+func TestHandler_RaftDisabled(t *testing.T) {
+	t.Parallel()
+
+	h := newHandler(slog.New(slog.NewJSONHandler(os.Stdout, nil)), nil, nil, handlerConfig{})
+	_, err := h.doCmd(context.Background(), domain.Command{Type: domain.CommandRaft, Args: []string{domain.RaftStatus}})
+	require.ErrorIs(t, err, errRaftDisabled)
+}
+
+func TestRaftReplicator(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	network := raft.NewInmemNetwork()
+	members := []raft.Member{
+		{ID: "n1", ClientAddress: "127.0.0.1:3223"},
+		{ID: "n2", ClientAddress: "127.0.0.1:3224"},
+		{ID: "n3", ClientAddress: "127.0.0.1:3225"},
+	}
+
+	replicators := make([]*raftReplicator, 0, len(members))
+	storages := make([]storage, 0, len(members))
+	for _, m := range members {
+		st := inmemory.New()
+		node := raft.New(raft.Config{
+			ID:                m.ID,
+			Members:           members,
+			ElectionTimeout:   50 * time.Millisecond,
+			HeartbeatInterval: 10 * time.Millisecond,
+		}, commandFSM{executor: executor{storage: st}}, network.Transport(m.ID), log)
+		network.Register(m.ID, node)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go func() { _ = node.Run(ctx) }()
+
+		replicators = append(replicators, &raftReplicator{log: log, node: node})
+		storages = append(storages, st)
+	}
+
+	var leader, follower *raftReplicator
+	require.Eventually(t, func() bool {
+		for _, r := range replicators {
+			if r.Status().State == raft.Leader {
+				leader = r
+			}
+		}
+		return leader != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	for _, r := range replicators {
+		if r != leader {
+			follower = r
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := leader.Apply(ctx, domain.Command{Type: domain.CommandIncrBy, Key: "counter", Value: "5"})
+	require.NoError(t, err)
+	require.Equal(t, "5", out)
+
+	id, err := leader.Apply(ctx, domain.Command{Type: domain.CommandXAdd, Key: "events", Args: []string{"*", "f", "v"}})
+	require.NoError(t, err)
+
+	_, err = leader.Apply(ctx, domain.Command{Type: domain.CommandGet, Key: "missing"})
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.Eventually(t, func() bool {
+		return follower.Status().Leader == leader.Status().ID
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = follower.Apply(ctx, domain.Command{Type: domain.CommandSet, Key: "k", Value: "v"})
+	require.ErrorIs(t, err, domain.ErrNotLeader)
+	leaderMember := members[0]
+	for _, m := range members {
+		if m.ID == leader.Status().ID {
+			leaderMember = m
+		}
+	}
+	require.EqualError(t, err, "NOTLEADER "+leaderMember.ClientAddress)
+
+	for _, st := range storages {
+		require.Eventually(t, func() bool {
+			entries, err := st.XRange(context.Background(), "events", domain.StreamID{}, domain.MaxStreamID, 0)
+			return err == nil && len(entries) == 1 && entries[0].ID.String() == id
+		}, 5*time.Second, 10*time.Millisecond)
+
+		v, err := st.Get(context.Background(), "counter")
+		require.NoError(t, err)
+		require.Equal(t, "5", v)
+	}
+}
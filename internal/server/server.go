@@ -6,9 +6,16 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/tmvrus/key-value-storage/internal/audit"
+	"github.com/tmvrus/key-value-storage/internal/cluster"
 	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/netaddr"
+	"go.opentelemetry.io/otel"
 )
 
 type Server struct {
@@ -16,61 +23,230 @@ type Server struct {
 	storage storage
 	cfg     *config.Config
 
-	sessionLimiter chan struct{}
+	// live holds settings changed by Reload, cfg keeps the rest.
+	live           *atomic.Pointer[liveSettings]
+	sessionLimiter *sessionLimiter
+	// replicator is nil unless raft is enabled.
+	replicator *raftReplicator
+	watches    *watchHub
+	limiter    *rateLimiter
+	users      map[string]string
+	// slowLog is nil when disabled.
+	slowLog  *slowLog
+	monitors *monitorHub
+	clients  *clientRegistry
+	// ready is reported by the admin server.
+	ready *atomic.Bool
 }
 
 func New(cfg *config.Config, s storage, l *slog.Logger) Server {
-	return Server{
+	srv := Server{
 		log:            l,
 		storage:        s,
 		cfg:            cfg,
-		sessionLimiter: make(chan struct{}, cfg.Network.MaxConnections),
+		live:           &atomic.Pointer[liveSettings]{},
+		sessionLimiter: newSessionLimiter(int(cfg.Network.MaxConnections)),
+		watches:        newWatchHub(),
+		limiter:        newRateLimiter(cfg),
+		users:          newUsers(cfg.Auth.Users),
+		slowLog:        newSlowLog(cfg.SlowLog.Threshold, cfg.SlowLog.MaxLen),
+		monitors:       newMonitorHub(),
+		clients:        newClientRegistry(),
+		ready:          &atomic.Bool{},
 	}
+	srv.live.Store(newLiveSettings(cfg))
+	if cfg.Raft.Enabled {
+		srv.replicator = newRaftReplicator(cfg, executor{storage: s, watches: srv.watches}, l)
+	}
+	return srv
 }
 
 func (s Server) Run(ctx context.Context) error {
-	l, err := net.Listen("tcp", s.cfg.Network.Address)
+	// the admin server answers probes while the server starts and stops
+	if s.cfg.Admin.Enabled {
+		stop, err := s.serveAdmin()
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	var (
+		clusterState *cluster.Cluster
+		migrator     *cluster.Migrator
+	)
+	if s.cfg.Cluster.Enabled {
+		c, m, err := newMigratingCluster(s.cfg, s.storage, s.log)
+		if err != nil {
+			return err
+		}
+		clusterState, migrator = c, m
+	}
+
+	if s.replicator != nil {
+		if err := s.replicator.start(ctx); err != nil {
+			return err
+		}
+	}
+
+	var auditLog *audit.Log
+	if s.cfg.Audit.Enabled {
+		l, err := audit.Open(s.cfg)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := l.Close(); err != nil {
+				s.log.Error("failed to close audit log", "error", err.Error())
+			}
+		}()
+		auditLog = l
+	}
+
+	newSession := func(conn socket, client string) handler {
+		live := s.live.Load()
+		h := newHandler(s.log, s.storage, conn, handlerConfig{
+			timeout:    live.idleTimeout,
+			bufferSize: live.maxMessageSize,
+		})
+		if s.replicator != nil {
+			h.replicator = s.replicator
+		}
+		h.watches = s.watches
+		h.cluster = clusterState
+		h.migrator = migrator
+		h.serverCtx = ctx
+		h.limiter = s.limiter
+		h.users = s.users
+		h.slowLog = s.slowLog
+		h.monitors = s.monitors
+		h.audit = auditLog
+		if s.cfg.Tracing.Enabled {
+			h.tracer = otel.Tracer(tracerName)
+		}
+		if conn != nil {
+			h.clients = s.clients
+		}
+		h.session.client = client
+		return h
+	}
+
+	listeners, err := s.listen()
 	if err != nil {
-		return fmt.Errorf("net listen: %w", err)
+		return err
 	}
 
-	s.log.Debug("ready to accept connections", "address", s.cfg.Network.Address)
+	if s.cfg.HTTP.Enabled {
+		hl, err := net.Listen("tcp", s.cfg.HTTP.Address)
+		if err != nil {
+			return errors.Join(fmt.Errorf("http listen: %w", err), closeListeners(listeners))
+		}
 
-	go func() {
-		<-ctx.Done()
-		if err := l.Close(); err != nil {
-			s.log.Error("failed to close listener", "error", err.Error())
+		g := httpGateway{
+			log:            s.log,
+			newSession:     func(client string) handler { return newSession(nil, client) },
+			sessionLimiter: s.sessionLimiter,
+			maxBodySize:    int64(s.cfg.Network.MaxMessageSize.Int()),
 		}
-	}()
+		go g.serve(ctx, hl, s.cfg.Network.IdleTimeout)
 
-	for {
-		select {
-		case <-ctx.Done():
-			s.log.Debug("got context done, stop application")
-			return ctx.Err()
-		default:
+		s.log.Debug("http gateway is ready", "address", s.cfg.HTTP.Address)
+	}
+
+	if s.cfg.GRPC.Enabled {
+		gl, err := net.Listen("tcp", s.cfg.GRPC.Address)
+		if err != nil {
+			return errors.Join(fmt.Errorf("grpc listen: %w", err), closeListeners(listeners))
+		}
 
+		g := grpcService{
+			log:        s.log,
+			newSession: func(client string) handler { return newSession(nil, client) },
+			watches:    s.watches,
 		}
+		go g.serve(ctx, gl, s.cfg.Network.MaxMessageSize.Int())
+
+		s.log.Debug("grpc service is ready", "address", s.cfg.GRPC.Address)
+	}
 
+	var wg sync.WaitGroup
+	for _, l := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.accept(ctx, l, newSession)
+		}()
+	}
+
+	s.ready.Store(true)
+
+	<-ctx.Done()
+	s.ready.Store(false)
+	s.log.Debug("got context done, stop application")
+	if err := closeListeners(listeners); err != nil {
+		s.log.Error("failed to close listener", "error", err.Error())
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// serveAdmin starts the admin server, it runs until stop is called.
+func (s Server) serveAdmin() (stop func(), err error) {
+	l, err := net.Listen("tcp", s.cfg.Admin.Address)
+	if err != nil {
+		return nil, fmt.Errorf("admin listen: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := adminServer{
+		log:    s.log,
+		ready:  s.ready,
+		config: func() *config.Config { return s.live.Load().cfg },
+	}
+	go a.serve(ctx, l)
+
+	s.log.Debug("admin server is ready", "address", s.cfg.Admin.Address)
+	return cancel, nil
+}
+
+// listen opens a listener per configured address, unix sockets included.
+func (s Server) listen() ([]net.Listener, error) {
+	addresses := s.cfg.ListenAddresses()
+	listeners := make([]net.Listener, 0, len(addresses))
+	for _, address := range addresses {
+		l, err := netaddr.Listen(address, os.FileMode(s.cfg.Network.SocketMode))
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("net listen %s: %w", address, err), closeListeners(listeners))
+		}
+		listeners = append(listeners, l)
+
+		s.log.Debug("ready to accept connections", "address", address)
+	}
+	return listeners, nil
+}
+
+// accept serves connections of the listener until it is closed.
+func (s Server) accept(ctx context.Context, l net.Listener, newSession func(socket, string) handler) {
+	for {
 		conn, err := l.Accept()
 		if err != nil {
-			if !errors.Is(err, net.ErrClosed) {
-				s.log.Error("failed to accept connection", "error", err.Error())
+			if errors.Is(err, net.ErrClosed) {
+				return
 			}
+			s.log.Error("failed to accept connection", "error", err.Error())
 			continue
 		}
 
-		select {
-		case s.sessionLimiter <- struct{}{}:
-			s.log.Debug("start session", "src", conn.RemoteAddr().String())
-
-		default:
-			s.log.Debug("drop session due the limit", "src", conn.RemoteAddr().String())
+		src := remoteAddress(conn)
+		if !s.sessionLimiter.acquire() {
+			s.log.Debug("drop session due the limit", "src", src)
 			if err := conn.Close(); err != nil {
 				s.log.Error("failed to close connection", "error", err.Error())
 			}
 			continue
 		}
+		s.log.Debug("start session", "src", src)
 
 		go func() {
 			defer func() {
@@ -80,14 +256,26 @@ func (s Server) Run(ctx context.Context) error {
 			}()
 
 			start := time.Now()
-			cfg := handlerConfig{
-				timeout:    s.cfg.Network.IdleTimeout,
-				bufferSize: s.cfg.Network.MaxMessageSize.Int(),
-			}
-			newHandler(s.log, s.storage, conn, cfg).startHandling(ctx)
-			<-s.sessionLimiter
+			newSession(conn, src).startHandling(ctx)
+			s.sessionLimiter.release()
 
-			s.log.Debug("session finished", "src", conn.RemoteAddr().String(), "duration", time.Since(start).String())
+			s.log.Debug("session finished", "src", src, "duration", time.Since(start).String())
 		}()
 	}
 }
+
+func closeListeners(listeners []net.Listener) error {
+	var errs []error
+	for _, l := range listeners {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// remoteAddress describes the peer for logs, unix socket peers are usually unnamed.
+func remoteAddress(conn net.Conn) string {
+	if addr := conn.RemoteAddr(); addr != nil && addr.String() != "" {
+		return addr.String()
+	}
+	return conn.LocalAddr().Network() + ":" + conn.LocalAddr().String()
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -82,6 +83,10 @@ func TestApp_Run(t *testing.T) {
 		require.Error(t, err)
 		require.True(t, errors.Is(err, io.EOF))
 
+		// the server releases the session slot before closing the connection
+		require.NoError(t, conn1.(*net.TCPConn).CloseWrite())
+		_, err = io.ReadAll(conn1)
+		require.NoError(t, err)
 		require.NoError(t, conn1.Close())
 		require.NoError(t, conn2.Close())
 
@@ -93,6 +98,53 @@ func TestApp_Run(t *testing.T) {
 		cancel()
 		<-stopped
 	})
+
+	t.Run("listen on unix socket too", func(t *testing.T) {
+		t.Parallel()
+
+		socket := filepath.Join(t.TempDir(), "kvs.sock")
+		cfg := &config.Config{}
+		cfg.Network.Address = findFreePort(t)
+		cfg.Network.Addresses = []string{"unix://" + socket}
+		cfg.Network.SocketMode = 0o600
+		cfg.Network.MaxConnections = 2
+		cfg.Network.IdleTimeout = time.Minute
+		cfg.Network.MaxMessageSize = 1024
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+
+		cxt, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error)
+		go func() {
+			stopped <- New(cfg, storMock, log).Run(cxt)
+		}()
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		info, err := os.Stat(socket)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		unixConn, err := net.Dial("unix", socket)
+		require.NoError(t, err)
+		checkConnectionOK(t, unixConn, storMock)
+		require.NoError(t, unixConn.Close())
+
+		tcpConn, err := net.Dial("tcp", cfg.Network.Address)
+		require.NoError(t, err)
+		checkConnectionOK(t, tcpConn, storMock)
+		require.NoError(t, tcpConn.Close())
+
+		cancel()
+		require.ErrorIs(t, <-stopped, context.Canceled)
+
+		_, err = os.Stat(socket)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
 }
 
 func checkConnectionOK(t *testing.T, c net.Conn, mock *Mockstorage) {
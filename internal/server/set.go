@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e executor) doSetCmd(ctx context.Context, c domain.Command) (string, error) {
+	switch c.Type {
+	case domain.CommandSAdd:
+		n, err := e.storage.SAdd(ctx, c.Key, c.Args)
+		return strconv.Itoa(n), err
+	case domain.CommandSRem:
+		n, err := e.storage.SRem(ctx, c.Key, c.Args)
+		return strconv.Itoa(n), err
+	case domain.CommandSMembers:
+		return multiLines(e.storage.SMembers(ctx, c.Key))
+	case domain.CommandSIsMember:
+		ok, err := e.storage.SIsMember(ctx, c.Key, c.Args[0])
+		return boolResult(ok), err
+	case domain.CommandSInter:
+		return multiLines(e.storage.SInter(ctx, c.Args))
+	case domain.CommandSUnion:
+		return multiLines(e.storage.SUnion(ctx, c.Args))
+	case domain.CommandSDiff:
+		return multiLines(e.storage.SDiff(ctx, c.Args))
+	case domain.CommandSCard:
+		n, err := e.storage.SCard(ctx, c.Key)
+		return strconv.Itoa(n), err
+	default:
+		return "", fmt.Errorf("invalid set cmd type: %q", c.Type)
+	}
+}
+
+// multiLines formats lines returned by storage as a multi-line reply.
+func multiLines(lines []string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return multiResult(lines), nil
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const (
+	defaultSlowLogEntries = 10
+	// maxSlowLogArgs and maxSlowLogArgLen bound the memory an entry takes.
+	maxSlowLogArgs   = 32
+	maxSlowLogArgLen = 128
+)
+
+var errSlowLogDisabled = errors.New("slow log is disabled")
+
+type slowLogEntry struct {
+	id       uint64
+	time     time.Time
+	duration time.Duration
+	client   string
+	args     []string
+}
+
+// slowLog keeps the last commands executed longer than the threshold in a
+// ring buffer.
+type slowLog struct {
+	threshold time.Duration
+
+	mu      sync.Mutex
+	entries []slowLogEntry
+	// next is the position of the next entry once the buffer is full.
+	next   int
+	lastID uint64
+}
+
+// newSlowLog returns nil when the threshold or the size is not positive.
+func newSlowLog(threshold time.Duration, maxLen int) *slowLog {
+	if threshold <= 0 || maxLen <= 0 {
+		return nil
+	}
+	return &slowLog{threshold: threshold, entries: make([]slowLogEntry, 0, maxLen)}
+}
+
+// observe records the command started at the given time if it took too long.
+func (l *slowLog) observe(start time.Time, client string, c domain.Command) {
+	d := time.Since(start)
+	if d < l.threshold {
+		return
+	}
+	l.add(slowLogEntry{time: start, duration: d, client: client, args: truncateArgs(displayFields(c))})
+}
+
+func (l *slowLog) add(e slowLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastID++
+	e.id = l.lastID
+	if len(l.entries) < cap(l.entries) {
+		l.entries = append(l.entries, e)
+	} else {
+		l.entries[l.next] = e
+	}
+	l.next = (l.next + 1) % cap(l.entries)
+}
+
+// get returns up to n entries, the newest go first.
+func (l *slowLog) get(n int) []slowLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	size := len(l.entries)
+	res := make([]slowLogEntry, 0, min(n, size))
+	for i := 1; i <= min(n, size); i++ {
+		res = append(res, l.entries[(l.next-i+size)%size])
+	}
+	return res
+}
+
+func (l *slowLog) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.entries)
+}
+
+func (l *slowLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = l.entries[:0]
+	l.next = 0
+}
+
+func truncateArgs(args []string) []string {
+	res := make([]string, 0, min(len(args), maxSlowLogArgs))
+	for i, a := range args {
+		if i == maxSlowLogArgs-1 && len(args) > maxSlowLogArgs {
+			res = append(res, fmt.Sprintf("...(%d more arguments)", len(args)-i))
+			break
+		}
+		if len(a) > maxSlowLogArgLen {
+			a = fmt.Sprintf("%s...(%d more bytes)", a[:maxSlowLogArgLen], len(a)-maxSlowLogArgLen)
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+func (a handler) doSlowLogCmd(_ context.Context, c domain.Command) (string, error) {
+	if a.slowLog == nil {
+		return "", errSlowLogDisabled
+	}
+
+	switch c.Args[0] {
+	case domain.SlowLogGet:
+		n := defaultSlowLogEntries
+		if len(c.Args) == 2 {
+			var err error
+			if n, err = strconv.Atoi(c.Args[1]); err != nil {
+				return "", fmt.Errorf("invalid number of entries: %w", err)
+			}
+		}
+		return slowLogResult(a.slowLog.get(n)), nil
+	case domain.SlowLogLen:
+		return strconv.Itoa(a.slowLog.len()), nil
+	case domain.SlowLogReset:
+		a.slowLog.reset()
+		return "", nil
+	default:
+		return "", fmt.Errorf("invalid slowlog subcommand: %q", c.Args[0])
+	}
+}
+
+// slowLogResult lists a line per entry: ID, unix time, duration in
+// microseconds, client address and the command.
+func slowLogResult(entries []slowLogEntry) string {
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, strings.Join(append([]string{
+			strconv.FormatUint(e.id, 10),
+			strconv.FormatInt(e.time.Unix(), 10),
+			strconv.FormatInt(e.duration.Microseconds(), 10),
+			e.client,
+		}, e.args...), " "))
+	}
+	return multiResult(lines)
+}
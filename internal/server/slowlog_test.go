@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/compute/parser"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSlowLog(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, newSlowLog(0, 10))
+
+	l := newSlowLog(time.Millisecond, 3)
+	l.observe(time.Now(), "10.0.0.1:5000", domain.Command{Type: domain.CommandGet, Key: "fast"})
+	require.Zero(t, l.len())
+
+	start := time.Now().Add(-time.Second)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		l.observe(start, "10.0.0.1:5000", domain.Command{Type: domain.CommandGet, Key: key})
+	}
+	require.Equal(t, 3, l.len())
+
+	entries := l.get(10)
+	require.Len(t, entries, 3)
+	for i, key := range []string{"d", "c", "b"} {
+		require.Equal(t, uint64(4-i), entries[i].id)
+		require.Equal(t, []string{"GET", key}, entries[i].args)
+		require.Equal(t, "10.0.0.1:5000", entries[i].client)
+		require.GreaterOrEqual(t, entries[i].duration, time.Second)
+	}
+	require.Len(t, l.get(1), 1)
+
+	l.reset()
+	require.Zero(t, l.len())
+	require.Empty(t, l.get(10))
+
+	l.observe(start, "", domain.Command{Type: domain.CommandGet, Key: "e"})
+	require.Equal(t, uint64(5), l.get(1)[0].id)
+}
+
+func TestTruncateArgs(t *testing.T) {
+	t.Parallel()
+
+	args := make([]string, 40)
+	for i := range args {
+		args[i] = "a"
+	}
+	args[0] = strings.Repeat("x", 130)
+
+	res := truncateArgs(args)
+	require.Len(t, res, maxSlowLogArgs)
+	require.Equal(t, strings.Repeat("x", 128)+"...(2 more bytes)", res[0])
+	require.Equal(t, "...(9 more arguments)", res[maxSlowLogArgs-1])
+
+	require.Equal(t, []string{"GET", "k"}, truncateArgs([]string{"GET", "k"}))
+}
+
+func TestHandler_SlowLog(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	do := func(h handler, in string) (string, error) {
+		cmd, err := parser.Parse(in)
+		require.NoError(t, err)
+		return h.doCmd(context.Background(), cmd)
+	}
+
+	t.Run("slow log is disabled", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := do(newHandler(log, nil, nil, handlerConfig{}), "SLOWLOG LEN")
+		require.ErrorIs(t, err, errSlowLogDisabled)
+	})
+
+	t.Run("records slow commands", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		storMock := NewMockstorage(ctrl)
+		storMock.EXPECT().Set(gomock.Any(), "k", "v").DoAndReturn(func(context.Context, string, string) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+
+		h := newHandler(log, storMock, nil, handlerConfig{})
+		h.slowLog = newSlowLog(10*time.Millisecond, 10)
+		h.session.client = "10.0.0.1:5000"
+
+		_, err := do(h, "SET k v")
+		require.NoError(t, err)
+
+		out, err := do(h, "SLOWLOG LEN")
+		require.NoError(t, err)
+		require.Equal(t, "1", out)
+
+		out, err = do(h, "SLOWLOG GET 1")
+		require.NoError(t, err)
+		lines := strings.Split(out, "\n")
+		require.Len(t, lines, 2)
+		require.Equal(t, "1", lines[0])
+
+		fields := strings.Fields(lines[1])
+		require.Equal(t, "1", fields[0])
+		require.Equal(t, []string{"10.0.0.1:5000", "SET", "k", "v"}, fields[3:])
+
+		out, err = do(h, "SLOWLOG RESET")
+		require.NoError(t, err)
+		require.Empty(t, out)
+
+		out, err = do(h, "SLOWLOG GET")
+		require.NoError(t, err)
+		require.Equal(t, "0", out)
+	})
+}
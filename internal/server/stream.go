@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e executor) doStreamCmd(ctx context.Context, c domain.Command) (string, error) {
+	switch c.Type {
+	case domain.CommandXAdd:
+		var id domain.StreamID
+		if c.Args[0] != "*" {
+			var err error
+			if id, err = domain.ParseStreamID(c.Args[0], 0); err != nil {
+				return "", err
+			}
+		}
+		fields := make([]domain.KeyValue, 0, len(c.Args)/2)
+		for i := 1; i+1 < len(c.Args); i += 2 {
+			fields = append(fields, domain.KeyValue{Key: c.Args[i], Value: c.Args[i+1]})
+		}
+		id, err := e.storage.XAdd(ctx, c.Key, id, fields)
+		if err != nil {
+			return "", err
+		}
+		return id.String(), nil
+	case domain.CommandXRange:
+		return e.xRange(ctx, c)
+	case domain.CommandXRead:
+		count, block, offsets, err := readArgs(c.Args, "$")
+		if err != nil {
+			return "", err
+		}
+		return streamsResult(e.storage.XRead(ctx, offsets, count, block))
+	case domain.CommandXGroup:
+		var (
+			id     domain.StreamID
+			latest = c.Args[1] == "$"
+		)
+		if !latest {
+			var err error
+			if id, err = domain.ParseStreamID(c.Args[1], 0); err != nil {
+				return "", err
+			}
+		}
+		mkStream := len(c.Args) > 2
+		return "", e.storage.XGroupCreate(ctx, c.Key, c.Args[0], id, latest, mkStream)
+	case domain.CommandXReadGroup:
+		count, block, offsets, err := readArgs(c.Args[2:], ">")
+		if err != nil {
+			return "", err
+		}
+		return streamsResult(e.storage.XReadGroup(ctx, c.Args[0], c.Args[1], offsets, count, block))
+	case domain.CommandXAck:
+		ids := make([]domain.StreamID, 0, len(c.Args)-1)
+		for _, s := range c.Args[1:] {
+			id, err := domain.ParseStreamID(s, 0)
+			if err != nil {
+				return "", err
+			}
+			ids = append(ids, id)
+		}
+		n, err := e.storage.XAck(ctx, c.Key, c.Args[0], ids)
+		return strconv.Itoa(n), err
+	case domain.CommandXPending:
+		consumer := ""
+		if len(c.Args) > 1 {
+			consumer = c.Args[1]
+		}
+		pending, err := e.storage.XPending(ctx, c.Key, c.Args[0], consumer)
+		if err != nil {
+			return "", err
+		}
+		lines := make([]string, len(pending))
+		for i, p := range pending {
+			lines[i] = fmt.Sprintf("%s %s %d %d", p.ID, p.Consumer, p.Idle.Milliseconds(), p.Deliveries)
+		}
+		return multiResult(lines), nil
+	default:
+		return "", fmt.Errorf("invalid stream cmd type: %q", c.Type)
+	}
+}
+
+func (e executor) xRange(ctx context.Context, c domain.Command) (string, error) {
+	start, end := domain.StreamID{}, domain.MaxStreamID
+
+	var err error
+	if c.Args[0] != "-" {
+		if start, err = domain.ParseStreamID(c.Args[0], 0); err != nil {
+			return "", err
+		}
+	}
+	if c.Args[1] != "+" {
+		if end, err = domain.ParseStreamID(c.Args[1], math.MaxUint64); err != nil {
+			return "", err
+		}
+	}
+	count, err := strconv.Atoi(c.Args[2])
+	if err != nil {
+		return "", domain.ErrNotInteger
+	}
+
+	entries, err := e.storage.XRange(ctx, c.Key, start, end, count)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = formatStreamEntry(entry)
+	}
+	return multiResult(lines), nil
+}
+
+// readArgs parses count, block milliseconds and keys followed by IDs,
+// the latest ID stands for "$" or ">" depending on the command.
+func readArgs(args []string, latest string) (int, time.Duration, []domain.StreamOffset, error) {
+	count, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, 0, nil, domain.ErrNotInteger
+	}
+	blockMs, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, 0, nil, domain.ErrNotInteger
+	}
+
+	streams := args[2:]
+	keys, ids := streams[:len(streams)/2], streams[len(streams)/2:]
+
+	offsets := make([]domain.StreamOffset, len(keys))
+	for i := range keys {
+		offsets[i].Key = keys[i]
+		if ids[i] == latest {
+			offsets[i].Latest = true
+			continue
+		}
+		if offsets[i].ID, err = domain.ParseStreamID(ids[i], 0); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	return count, time.Duration(blockMs) * time.Millisecond, offsets, nil
+}
+
+// streamsResult formats entries as a multi-line reply, a line per entry
+// prefixed with the stream key, nil when there is nothing to return.
+func streamsResult(streams []domain.StreamEntries, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if len(streams) == 0 {
+		return nilResult, nil
+	}
+
+	var lines []string
+	for _, s := range streams {
+		for _, e := range s.Entries {
+			lines = append(lines, s.Key+" "+formatStreamEntry(e))
+		}
+	}
+	return multiResult(lines), nil
+}
+
+// formatStreamEntry formats the entry as its ID followed by fields and values.
+func formatStreamEntry(e domain.StreamEntry) string {
+	parts := make([]string, 0, 1+len(e.Fields)*2)
+	parts = append(parts, e.ID.String())
+	for _, f := range e.Fields {
+		parts = append(parts, f.Key, f.Value)
+	}
+	return strings.Join(parts, " ")
+}
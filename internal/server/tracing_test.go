@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Tracing(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctrl := gomock.NewController(t)
+	storMock := NewMockstorage(ctrl)
+	storMock.EXPECT().Set(gomock.Any(), "k", "v").Return(nil)
+	storMock.EXPECT().Get(gomock.Any(), "k").Return("v", nil)
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	h := newHandler(slog.New(slog.NewJSONHandler(os.Stdout, nil)), storMock, server, handlerConfig{timeout: time.Minute, bufferSize: 1024})
+	h.tracer = provider.Tracer(tracerName)
+	h.session.client = "10.0.0.1:5000"
+	done := make(chan struct{})
+	go func() {
+		h.startHandling(context.Background())
+		_ = server.Close()
+		close(done)
+	}()
+
+	reader := bufio.NewReader(client)
+	for _, in := range []string{
+		"traceparent=00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01 SET k v",
+		"GET k",
+		"UNKNOWN",
+	} {
+		_, err := client.Write([]byte(in + "\n"))
+		require.NoError(t, err)
+		_, err = reader.ReadString('\n')
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.Close())
+	<-done
+
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name()
+	}
+	require.Equal(t, []string{"parse", "SET", "parse", "GET", "parse", "session"}, names)
+
+	session := spans[5].SpanContext()
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", spans[1].SpanContext().TraceID().String())
+	require.Equal(t, "0102030405060708", spans[1].Parent().SpanID().String())
+	require.Equal(t, spans[1].Parent(), spans[0].Parent())
+	require.Equal(t, session.SpanID(), spans[2].Parent().SpanID())
+	require.Equal(t, session.SpanID(), spans[3].Parent().SpanID())
+	require.Equal(t, "Error", spans[4].Status().Code.String())
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// watchBufferSize is the number of events a watcher may lag behind before it is closed.
+const watchBufferSize = 128
+
+// keyEvent describes a key changed by a command.
+type keyEvent struct {
+	key     string
+	command domain.CommandType
+	// value is set for SET only.
+	value string
+}
+
+// watchHub delivers key events to watchers without blocking the publisher:
+// a watcher with a full buffer is closed and dropped.
+type watchHub struct {
+	lock     sync.Mutex
+	watchers map[*watcher]struct{}
+}
+
+type watcher struct {
+	keys   []string
+	prefix string
+	events chan keyEvent
+	// overflowed is set before events is closed when the watcher lagged behind.
+	overflowed bool
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{watchers: make(map[*watcher]struct{})}
+}
+
+// subscribe registers a watcher of the listed keys and keys with the prefix,
+// it watches all keys when both are empty.
+func (h *watchHub) subscribe(keys []string, prefix string) *watcher {
+	w := &watcher{keys: keys, prefix: prefix, events: make(chan keyEvent, watchBufferSize)}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.watchers[w] = struct{}{}
+	return w
+}
+
+func (h *watchHub) unsubscribe(w *watcher) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.watchers[w]; ok {
+		delete(h.watchers, w)
+		close(w.events)
+	}
+}
+
+// publish notifies watchers about keys changed by the command, nil hub does nothing.
+func (h *watchHub) publish(c domain.Command) {
+	if h == nil {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if len(h.watchers) == 0 {
+		return
+	}
+
+	for _, key := range c.Keys() {
+		e := keyEvent{key: key, command: c.Type}
+		if c.Type == domain.CommandSet {
+			e.value = c.Value
+		}
+
+		for w := range h.watchers {
+			if !w.matches(key) {
+				continue
+			}
+			select {
+			case w.events <- e:
+			default:
+				w.overflowed = true
+				delete(h.watchers, w)
+				close(w.events)
+			}
+		}
+	}
+}
+
+func (w *watcher) matches(key string) bool {
+	if len(w.keys) == 0 && w.prefix == "" {
+		return true
+	}
+	return slices.Contains(w.keys, key) || w.prefix != "" && strings.HasPrefix(key, w.prefix)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestWatchHub(t *testing.T) {
+	t.Parallel()
+
+	h := newWatchHub()
+	all := h.subscribe(nil, "")
+	users := h.subscribe([]string{"config"}, "user:")
+
+	h.publish(domain.Command{Type: domain.CommandSet, Key: "user:1", Value: "John"})
+	h.publish(domain.Command{Type: domain.CommandMDelete, Args: []string{"config", "other"}})
+
+	require.Equal(t, keyEvent{key: "user:1", command: domain.CommandSet, value: "John"}, <-all.events)
+	require.Equal(t, keyEvent{key: "config", command: domain.CommandMDelete}, <-all.events)
+	require.Equal(t, keyEvent{key: "other", command: domain.CommandMDelete}, <-all.events)
+
+	require.Equal(t, keyEvent{key: "user:1", command: domain.CommandSet, value: "John"}, <-users.events)
+	require.Equal(t, keyEvent{key: "config", command: domain.CommandMDelete}, <-users.events)
+	require.Empty(t, users.events)
+
+	h.unsubscribe(users)
+	_, ok := <-users.events
+	require.False(t, ok)
+
+	for range watchBufferSize + 1 {
+		h.publish(domain.Command{Type: domain.CommandDelete, Key: "k"})
+	}
+	for range watchBufferSize {
+		<-all.events
+	}
+	_, ok = <-all.events
+	require.False(t, ok)
+	require.True(t, all.overflowed)
+
+	// dropped watchers are not closed twice
+	h.unsubscribe(all)
+	var nilHub *watchHub
+	nilHub.publish(domain.Command{Type: domain.CommandDelete, Key: "k"})
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const withScoresFlag = "WITHSCORES"
+
+func (e executor) doSortedSetCmd(ctx context.Context, c domain.Command) (string, error) {
+	switch c.Type {
+	case domain.CommandZAdd:
+		members := make([]domain.ScoredMember, 0, len(c.Args)/2)
+		for i := 0; i+1 < len(c.Args); i += 2 {
+			score, err := strconv.ParseFloat(c.Args[i], 64)
+			if err != nil {
+				return "", domain.ErrNotFloat
+			}
+			members = append(members, domain.ScoredMember{Member: c.Args[i+1], Score: score})
+		}
+		n, err := e.storage.ZAdd(ctx, c.Key, members)
+		return strconv.Itoa(n), err
+	case domain.CommandZRem:
+		n, err := e.storage.ZRem(ctx, c.Key, c.Args)
+		return strconv.Itoa(n), err
+	case domain.CommandZScore:
+		score, ok, err := e.storage.ZScore(ctx, c.Key, c.Args[0])
+		return optionalResult(formatScore(score), ok, err)
+	case domain.CommandZRank:
+		rank, ok, err := e.storage.ZRank(ctx, c.Key, c.Args[0])
+		return optionalResult(strconv.Itoa(rank), ok, err)
+	case domain.CommandZRange:
+		start, stop, err := rangeArgs(c.Args)
+		if err != nil {
+			return "", err
+		}
+		members, err := e.storage.ZRange(ctx, c.Key, start, stop)
+		return scoredResult(members, len(c.Args) > 2 && c.Args[2] == withScoresFlag, err)
+	case domain.CommandZRangeByScore:
+		var (
+			r   domain.ScoreRange
+			err error
+		)
+		if r.Min, r.MinExclusive, err = domain.ParseScoreBound(c.Args[0]); err != nil {
+			return "", err
+		}
+		if r.Max, r.MaxExclusive, err = domain.ParseScoreBound(c.Args[1]); err != nil {
+			return "", err
+		}
+		members, err := e.storage.ZRangeByScore(ctx, c.Key, r)
+		return scoredResult(members, len(c.Args) > 2 && c.Args[2] == withScoresFlag, err)
+	case domain.CommandZIncrBy:
+		delta, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return "", domain.ErrNotFloat
+		}
+		score, err := e.storage.ZIncrBy(ctx, c.Key, c.Args[0], delta)
+		return formatScore(score), err
+	default:
+		return "", fmt.Errorf("invalid sorted set cmd type: %q", c.Type)
+	}
+}
+
+// scoredResult formats members as a multi-line reply, each member is followed
+// by its score when withScores is set.
+func scoredResult(members []domain.ScoredMember, withScores bool, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(members)*2)
+	for _, m := range members {
+		lines = append(lines, m.Member)
+		if withScores {
+			lines = append(lines, formatScore(m.Score))
+		}
+	}
+	return multiResult(lines), nil
+}
+
+func formatScore(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
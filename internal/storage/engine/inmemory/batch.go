@@ -0,0 +1,47 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e *engine) MGet(_ context.Context, keys []string) ([]*string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	values := make([]*string, len(keys))
+	for i, key := range keys {
+		// values of other types are reported as absent
+		if v, ok := e.data[key].(string); ok {
+			values[i] = &v
+		}
+	}
+
+	return values, nil
+}
+
+func (e *engine) MSet(_ context.Context, pairs []domain.KeyValue) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for _, p := range pairs {
+		e.data[p.Key] = p.Value
+	}
+	return nil
+}
+
+func (e *engine) MDelete(_ context.Context, keys []string) ([]bool, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	deleted := make([]bool, len(keys))
+	for i, key := range keys {
+		if _, ok := e.data[key]; ok {
+			delete(e.data, key)
+			deleted[i] = true
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,36 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_Batch(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	err := storage.MSet(nil, []domain.KeyValue{
+		{Key: "key1", Value: "value1"},
+		{Key: "key2", Value: "value2"},
+	})
+	require.NoError(t, err)
+
+	values, err := storage.MGet(nil, []string{"key1", "missing", "key2"})
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	require.Equal(t, "value1", *values[0])
+	require.Nil(t, values[1])
+	require.Equal(t, "value2", *values[2])
+
+	deleted, err := storage.MDelete(nil, []string{"key1", "missing", "key1"})
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false, false}, deleted)
+
+	values, err = storage.MGet(nil, []string{"key1", "key2"})
+	require.NoError(t, err)
+	require.Nil(t, values[0])
+	require.Equal(t, "value2", *values[1])
+}
@@ -0,0 +1,72 @@
+package inmemory
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e *engine) IncrBy(_ context.Context, key string, delta int64) (int64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	v, ok, err := e.stringValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := incrInt(v, ok, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	e.data[key] = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+func (e *engine) IncrByFloat(_ context.Context, key string, delta float64) (float64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	v, ok, err := e.stringValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	var current float64
+	if ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, domain.ErrNotFloat
+		}
+		current = f
+	}
+
+	current += delta
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return 0, domain.ErrOverflow
+	}
+
+	e.data[key] = strconv.FormatFloat(current, 'f', -1, 64)
+	return current, nil
+}
+
+// incrInt adds delta to the integer formatted in v, absent value is treated as zero.
+func incrInt(v string, exists bool, delta int64) (int64, error) {
+	var current int64
+	if exists {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, domain.ErrNotInteger
+		}
+		current = n
+	}
+
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, domain.ErrOverflow
+	}
+
+	return current + delta, nil
+}
@@ -0,0 +1,102 @@
+package inmemory
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_IncrBy(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	n, err := storage.IncrBy(nil, "counter", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	n, err = storage.IncrBy(nil, "counter", -5)
+	require.NoError(t, err)
+	require.Equal(t, int64(-4), n)
+
+	val, err := storage.Get(nil, "counter")
+	require.NoError(t, err)
+	require.Equal(t, "-4", val)
+
+	require.NoError(t, storage.Set(nil, "text", "value"))
+	_, err = storage.IncrBy(nil, "text", 1)
+	require.True(t, errors.Is(err, domain.ErrNotInteger))
+
+	require.NoError(t, storage.Set(nil, "max", strconv.FormatInt(math.MaxInt64, 10)))
+	_, err = storage.IncrBy(nil, "max", 1)
+	require.True(t, errors.Is(err, domain.ErrOverflow))
+
+	require.NoError(t, storage.Set(nil, "min", strconv.FormatInt(math.MinInt64, 10)))
+	_, err = storage.IncrBy(nil, "min", -1)
+	require.True(t, errors.Is(err, domain.ErrOverflow))
+
+	val, err = storage.Get(nil, "max")
+	require.NoError(t, err)
+	require.Equal(t, strconv.FormatInt(math.MaxInt64, 10), val)
+}
+
+func TestEngine_IncrByConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		workers    = 10
+		increments = 100
+	)
+
+	storage := New()
+
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range increments {
+				_, err := storage.IncrBy(nil, "counter", 1)
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	val, err := storage.Get(nil, "counter")
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa(workers*increments), val)
+}
+
+func TestEngine_IncrByFloat(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	f, err := storage.IncrByFloat(nil, "counter", 0.5)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, f)
+
+	require.NoError(t, storage.Set(nil, "int", "10"))
+	f, err = storage.IncrByFloat(nil, "int", 0.25)
+	require.NoError(t, err)
+	require.Equal(t, 10.25, f)
+
+	val, err := storage.Get(nil, "int")
+	require.NoError(t, err)
+	require.Equal(t, "10.25", val)
+
+	require.NoError(t, storage.Set(nil, "text", "value"))
+	_, err = storage.IncrByFloat(nil, "text", 1)
+	require.True(t, errors.Is(err, domain.ErrNotFloat))
+
+	_, err = storage.IncrByFloat(nil, "counter", math.MaxFloat64)
+	require.NoError(t, err)
+	_, err = storage.IncrByFloat(nil, "counter", math.MaxFloat64)
+	require.True(t, errors.Is(err, domain.ErrOverflow))
+}
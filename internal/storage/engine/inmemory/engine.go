@@ -2,18 +2,34 @@ package inmemory
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"sync"
 
 	"github.com/tmvrus/key-value-storage/internal/domain"
 )
 
+// engine keeps values of different types: string for plain values,
+// hash for field-value maps, list for queues, set and zset for
+// unordered and sorted sets, stream for append-only logs.
 type engine struct {
 	lock sync.RWMutex
-	data map[string]string
+	data map[string]any
+	// waiters keeps clients blocked on an empty list in arrival order.
+	waiters map[string][]*waiter
+	// streamAdded is closed and replaced on every stream entry added
+	// to wake up blocked stream readers.
+	streamAdded chan struct{}
 }
 
+type hash map[string]string
+
 func New() *engine {
-	return &engine{data: make(map[string]string)}
+	return &engine{
+		data:        make(map[string]any),
+		waiters:     make(map[string][]*waiter),
+		streamAdded: make(chan struct{}),
+	}
 }
 
 func (e *engine) Set(_ context.Context, key, value string) error {
@@ -28,7 +44,10 @@ func (e *engine) Get(_ context.Context, key string) (string, error) {
 	e.lock.RLock()
 	defer e.lock.RUnlock()
 
-	v, ok := e.data[key]
+	v, ok, err := e.stringValue(key)
+	if err != nil {
+		return "", err
+	}
 	if !ok {
 		return "", domain.ErrNotFound
 	}
@@ -48,3 +67,111 @@ func (e *engine) Delete(_ context.Context, key string) error {
 	delete(e.data, key)
 	return nil
 }
+
+func (e *engine) Exists(_ context.Context, key string) (bool, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	_, ok := e.data[key]
+	return ok, nil
+}
+
+// Keys returns all stored keys in lexicographical order.
+func (e *engine) Keys(_ context.Context) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	keys := slices.Collect(maps.Keys(e.data))
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// stringValue returns the string stored by key, the caller must hold the lock.
+func (e *engine) stringValue(key string) (string, bool, error) {
+	v, ok := e.data[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", false, domain.ErrWrongType
+	}
+
+	return s, true, nil
+}
+
+// hashValue returns the hash stored by key, the caller must hold the lock.
+func (e *engine) hashValue(key string) (hash, bool, error) {
+	v, ok := e.data[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	h, ok := v.(hash)
+	if !ok {
+		return nil, false, domain.ErrWrongType
+	}
+
+	return h, true, nil
+}
+
+// listValue returns the list stored by key, the caller must hold the lock.
+func (e *engine) listValue(key string) (*list, bool, error) {
+	v, ok := e.data[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	l, ok := v.(*list)
+	if !ok {
+		return nil, false, domain.ErrWrongType
+	}
+
+	return l, true, nil
+}
+
+// setValue returns the set stored by key, the caller must hold the lock.
+func (e *engine) setValue(key string) (set, bool, error) {
+	v, ok := e.data[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	s, ok := v.(set)
+	if !ok {
+		return nil, false, domain.ErrWrongType
+	}
+
+	return s, true, nil
+}
+
+// zsetValue returns the sorted set stored by key, the caller must hold the lock.
+func (e *engine) zsetValue(key string) (*zset, bool, error) {
+	v, ok := e.data[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	z, ok := v.(*zset)
+	if !ok {
+		return nil, false, domain.ErrWrongType
+	}
+
+	return z, true, nil
+}
+
+// streamValue returns the stream stored by key, the caller must hold the lock.
+func (e *engine) streamValue(key string) (*stream, bool, error) {
+	v, ok := e.data[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	s, ok := v.(*stream)
+	if !ok {
+		return nil, false, domain.ErrWrongType
+	}
+
+	return s, true, nil
+}
@@ -18,6 +18,10 @@ func TestEngine_DeleteSetGet(t *testing.T) {
 	err = storage.Set(nil, "key", "value")
 	require.NoError(t, err)
 
+	ok, err := storage.Exists(nil, "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+
 	val, err := storage.Get(nil, "key")
 	require.NoError(t, err)
 	require.Equal(t, "value", val)
@@ -28,4 +32,8 @@ func TestEngine_DeleteSetGet(t *testing.T) {
 	val, err = storage.Get(nil, "key")
 	require.True(t, errors.Is(err, domain.ErrNotFound))
 	require.Empty(t, val)
+
+	ok, err = storage.Exists(nil, "key")
+	require.NoError(t, err)
+	require.False(t, ok)
 }
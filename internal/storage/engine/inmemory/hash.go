@@ -0,0 +1,160 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e *engine) HSet(_ context.Context, key string, fields []domain.KeyValue) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	h, ok, err := e.hashValue(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		h = make(hash, len(fields))
+		e.data[key] = h
+	}
+
+	added := 0
+	for _, f := range fields {
+		if _, ok := h[f.Key]; !ok {
+			added++
+		}
+		h[f.Key] = f.Value
+	}
+
+	return added, nil
+}
+
+func (e *engine) HGet(_ context.Context, key, field string) (string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	h, _, err := e.hashValue(key)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := h[field]
+	if !ok {
+		return "", domain.ErrNotFound
+	}
+
+	return v, nil
+}
+
+func (e *engine) HDel(_ context.Context, key string, fields []string) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	h, _, err := e.hashValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, f := range fields {
+		if _, ok := h[f]; ok {
+			delete(h, f)
+			deleted++
+		}
+	}
+	if deleted > 0 && len(h) == 0 {
+		delete(e.data, key)
+	}
+
+	return deleted, nil
+}
+
+func (e *engine) HGetAll(_ context.Context, key string) ([]domain.KeyValue, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	h, _, err := e.hashValue(key)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]domain.KeyValue, 0, len(h))
+	for _, f := range h.sortedFields() {
+		fields = append(fields, domain.KeyValue{Key: f, Value: h[f]})
+	}
+
+	return fields, nil
+}
+
+func (e *engine) HKeys(_ context.Context, key string) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	h, _, err := e.hashValue(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.sortedFields(), nil
+}
+
+func (e *engine) HVals(_ context.Context, key string) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	h, _, err := e.hashValue(key)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(h))
+	for _, f := range h.sortedFields() {
+		values = append(values, h[f])
+	}
+
+	return values, nil
+}
+
+func (e *engine) HLen(_ context.Context, key string) (int, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	h, _, err := e.hashValue(key)
+	return len(h), err
+}
+
+func (e *engine) HIncrBy(_ context.Context, key, field string, delta int64) (int64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	h, ok, err := e.hashValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	v, exists := h[field]
+	n, err := incrInt(v, exists, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		h = make(hash, 1)
+		e.data[key] = h
+	}
+	h[field] = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// sortedFields returns hash fields in a stable order.
+func (h hash) sortedFields() []string {
+	fields := make([]string, 0, len(h))
+	for f := range h {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,109 @@
+package inmemory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_Hash(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	added, err := storage.HSet(nil, "user:1", []domain.KeyValue{
+		{Key: "name", Value: "john"},
+		{Key: "age", Value: "42"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, added)
+
+	added, err = storage.HSet(nil, "user:1", []domain.KeyValue{
+		{Key: "name", Value: "jane"},
+		{Key: "city", Value: "paris"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, added)
+
+	val, err := storage.HGet(nil, "user:1", "name")
+	require.NoError(t, err)
+	require.Equal(t, "jane", val)
+
+	_, err = storage.HGet(nil, "user:1", "missing")
+	require.True(t, errors.Is(err, domain.ErrNotFound))
+
+	n, err := storage.HLen(nil, "user:1")
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	all, err := storage.HGetAll(nil, "user:1")
+	require.NoError(t, err)
+	require.Equal(t, []domain.KeyValue{
+		{Key: "age", Value: "42"},
+		{Key: "city", Value: "paris"},
+		{Key: "name", Value: "jane"},
+	}, all)
+
+	keys, err := storage.HKeys(nil, "user:1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"age", "city", "name"}, keys)
+
+	values, err := storage.HVals(nil, "user:1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"42", "paris", "jane"}, values)
+
+	age, err := storage.HIncrBy(nil, "user:1", "age", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(43), age)
+
+	_, err = storage.HIncrBy(nil, "user:1", "name", 1)
+	require.True(t, errors.Is(err, domain.ErrNotInteger))
+
+	deleted, err := storage.HDel(nil, "user:1", []string{"age", "city", "name", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, 3, deleted)
+
+	_, err = storage.Get(nil, "user:1")
+	require.True(t, errors.Is(err, domain.ErrNotFound))
+
+	n, err = storage.HLen(nil, "user:1")
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestEngine_WrongType(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+	require.NoError(t, storage.Set(nil, "string", "value"))
+	_, err := storage.HSet(nil, "hash", []domain.KeyValue{{Key: "field", Value: "value"}})
+	require.NoError(t, err)
+
+	_, err = storage.HGet(nil, "string", "field")
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+
+	_, err = storage.HIncrBy(nil, "string", "field", 1)
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+
+	_, err = storage.Get(nil, "hash")
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+
+	_, err = storage.IncrBy(nil, "hash", 1)
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+
+	_, err = storage.Append(nil, "hash", "value")
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+
+	values, err := storage.MGet(nil, []string{"hash", "string"})
+	require.NoError(t, err)
+	require.Nil(t, values[0])
+	require.Equal(t, "value", *values[1])
+
+	// SET overwrites values of any type
+	require.NoError(t, storage.Set(nil, "hash", "value"))
+	val, err := storage.Get(nil, "hash")
+	require.NoError(t, err)
+	require.Equal(t, "value", val)
+}
@@ -0,0 +1,232 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+type list struct {
+	items []string
+}
+
+func (l *list) popLeft() string {
+	v := l.items[0]
+	l.items[0] = ""
+	l.items = l.items[1:]
+	return v
+}
+
+func (l *list) popRight() string {
+	v := l.items[len(l.items)-1]
+	l.items = l.items[:len(l.items)-1]
+	return v
+}
+
+// waiter is a client blocked by BLPOP or BRPOP until one of its keys gets an element.
+type waiter struct {
+	keys  []string
+	left  bool
+	ready chan domain.KeyValue
+}
+
+func (e *engine) LPush(_ context.Context, key string, values []string) (int, error) {
+	return e.push(key, values, true)
+}
+
+func (e *engine) RPush(_ context.Context, key string, values []string) (int, error) {
+	return e.push(key, values, false)
+}
+
+func (e *engine) LPop(_ context.Context, key string) (string, bool, error) {
+	return e.pop(key, true)
+}
+
+func (e *engine) RPop(_ context.Context, key string) (string, bool, error) {
+	return e.pop(key, false)
+}
+
+func (e *engine) LRange(_ context.Context, key string, start, stop int) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	l, ok, err := e.listValue(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	start, stop, ok = normalizeRange(start, stop, len(l.items))
+	if !ok {
+		return nil, nil
+	}
+
+	return slices.Clone(l.items[start : stop+1]), nil
+}
+
+func (e *engine) LLen(_ context.Context, key string) (int, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	l, ok, err := e.listValue(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return len(l.items), nil
+}
+
+func (e *engine) LTrim(_ context.Context, key string, start, stop int) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	l, ok, err := e.listValue(key)
+	if err != nil || !ok {
+		return err
+	}
+
+	start, stop, ok = normalizeRange(start, stop, len(l.items))
+	if !ok {
+		delete(e.data, key)
+		return nil
+	}
+
+	l.items = slices.Clone(l.items[start : stop+1])
+	return nil
+}
+
+// BLPop pops the first element of the first non-empty list among keys, blocking until
+// an element arrives, timeout expires or ctx is done. Zero timeout blocks forever.
+func (e *engine) BLPop(ctx context.Context, keys []string, timeout time.Duration) (domain.KeyValue, bool, error) {
+	return e.blockingPop(ctx, keys, timeout, true)
+}
+
+// BRPop is BLPop popping from the tail of the list.
+func (e *engine) BRPop(ctx context.Context, keys []string, timeout time.Duration) (domain.KeyValue, bool, error) {
+	return e.blockingPop(ctx, keys, timeout, false)
+}
+
+func (e *engine) push(key string, values []string, left bool) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	l, ok, err := e.listValue(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		l = &list{}
+		e.data[key] = l
+	}
+
+	if left {
+		head := slices.Clone(values)
+		slices.Reverse(head)
+		l.items = append(head, l.items...)
+	} else {
+		l.items = append(l.items, values...)
+	}
+
+	n := len(l.items)
+	e.serveWaiters(key, l)
+	return n, nil
+}
+
+func (e *engine) pop(key string, left bool) (string, bool, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	l, ok, err := e.listValue(key)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	return e.popFrom(key, l, left), true, nil
+}
+
+// popFrom removes an element from the non-empty list, the caller must hold the lock.
+func (e *engine) popFrom(key string, l *list, left bool) string {
+	var v string
+	if left {
+		v = l.popLeft()
+	} else {
+		v = l.popRight()
+	}
+
+	if len(l.items) == 0 {
+		delete(e.data, key)
+	}
+	return v
+}
+
+func (e *engine) blockingPop(ctx context.Context, keys []string, timeout time.Duration, left bool) (domain.KeyValue, bool, error) {
+	e.lock.Lock()
+	for _, key := range keys {
+		l, ok, err := e.listValue(key)
+		if err != nil {
+			e.lock.Unlock()
+			return domain.KeyValue{}, false, err
+		}
+		if ok {
+			v := e.popFrom(key, l, left)
+			e.lock.Unlock()
+			return domain.KeyValue{Key: key, Value: v}, true, nil
+		}
+	}
+
+	w := &waiter{keys: keys, left: left, ready: make(chan domain.KeyValue, 1)}
+	for _, key := range keys {
+		e.waiters[key] = append(e.waiters[key], w)
+	}
+	e.lock.Unlock()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	select {
+	case kv := <-w.ready:
+		return kv, true, nil
+	case <-expired:
+	case <-ctx.Done():
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.removeWaiter(w)
+	// the element could be delivered while the lock was being acquired
+	select {
+	case kv := <-w.ready:
+		return kv, true, nil
+	default:
+	}
+
+	return domain.KeyValue{}, false, ctx.Err()
+}
+
+// serveWaiters hands elements of the list to the clients blocked on key
+// in FIFO order, the caller must hold the lock.
+func (e *engine) serveWaiters(key string, l *list) {
+	for len(e.waiters[key]) > 0 && len(l.items) > 0 {
+		w := e.waiters[key][0]
+		e.removeWaiter(w)
+		w.ready <- domain.KeyValue{Key: key, Value: e.popFrom(key, l, w.left)}
+	}
+}
+
+// removeWaiter drops the waiter from queues of all its keys, the caller must hold the lock.
+func (e *engine) removeWaiter(w *waiter) {
+	for _, key := range w.keys {
+		queue := slices.DeleteFunc(e.waiters[key], func(v *waiter) bool { return v == w })
+		if len(queue) == 0 {
+			delete(e.waiters, key)
+		} else {
+			e.waiters[key] = queue
+		}
+	}
+}
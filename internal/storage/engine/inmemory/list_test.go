@@ -0,0 +1,165 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_List(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	n, err := storage.RPush(nil, "queue", []string{"b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	n, err = storage.LPush(nil, "queue", []string{"a", "z"})
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+
+	items, err := storage.LRange(nil, "queue", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"z", "a", "b", "c"}, items)
+
+	v, ok, err := storage.LPop(nil, "queue")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "z", v)
+
+	v, ok, err = storage.RPop(nil, "queue")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "c", v)
+
+	n, err = storage.LLen(nil, "queue")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	require.NoError(t, storage.LTrim(nil, "queue", 1, -1))
+	items, err = storage.LRange(nil, "queue", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, items)
+
+	require.NoError(t, storage.LTrim(nil, "queue", 5, 10))
+	_, ok, err = storage.LPop(nil, "queue")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, storage.Set(nil, "string", "value"))
+	_, err = storage.LPush(nil, "string", []string{"a"})
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+}
+
+func TestEngine_BlockingPop(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pop existing element without blocking", func(t *testing.T) {
+		t.Parallel()
+
+		storage := New()
+		_, err := storage.RPush(nil, "queue", []string{"a", "b"})
+		require.NoError(t, err)
+
+		kv, ok, err := storage.BRPop(context.Background(), []string{"empty", "queue"}, time.Second)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, domain.KeyValue{Key: "queue", Value: "b"}, kv)
+	})
+
+	t.Run("return nothing on timeout", func(t *testing.T) {
+		t.Parallel()
+
+		storage := New()
+		_, ok, err := storage.BLPop(context.Background(), []string{"queue"}, 10*time.Millisecond)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Empty(t, storage.waiters)
+	})
+
+	t.Run("return error on context done", func(t *testing.T) {
+		t.Parallel()
+
+		storage := New()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, ok, err := storage.BLPop(ctx, []string{"queue"}, 0)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.False(t, ok)
+	})
+
+	t.Run("wake waiters in FIFO order", func(t *testing.T) {
+		t.Parallel()
+
+		storage := New()
+		results := make([]chan string, 3)
+
+		for i := range results {
+			results[i] = make(chan string, 1)
+			go func() {
+				kv, ok, err := storage.BLPop(context.Background(), []string{"other", "queue"}, time.Minute)
+				require.NoError(t, err)
+				require.True(t, ok)
+				results[i] <- kv.Value
+			}()
+
+			// make sure the waiters are queued one after another
+			require.Eventually(t, func() bool {
+				storage.lock.RLock()
+				defer storage.lock.RUnlock()
+				return len(storage.waiters["queue"]) == i+1
+			}, time.Second, time.Millisecond)
+		}
+
+		n, err := storage.RPush(nil, "queue", []string{"1", "2", "3", "4"})
+		require.NoError(t, err)
+		require.Equal(t, 4, n)
+
+		require.Equal(t, "1", <-results[0])
+		require.Equal(t, "2", <-results[1])
+		require.Equal(t, "3", <-results[2])
+
+		items, err := storage.LRange(nil, "queue", 0, -1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"4"}, items)
+		require.Empty(t, storage.waiters)
+	})
+
+	t.Run("wake waiters on restore", func(t *testing.T) {
+		t.Parallel()
+
+		source := New()
+		_, err := source.RPush(nil, "queue", []string{"a", "b"})
+		require.NoError(t, err)
+		snapshot, err := source.Snapshot(nil)
+		require.NoError(t, err)
+
+		storage := New()
+		result := make(chan domain.KeyValue, 1)
+		go func() {
+			kv, ok, err := storage.BLPop(context.Background(), []string{"queue"}, time.Minute)
+			require.NoError(t, err)
+			require.True(t, ok)
+			result <- kv
+		}()
+		require.Eventually(t, func() bool {
+			storage.lock.RLock()
+			defer storage.lock.RUnlock()
+			return len(storage.waiters["queue"]) == 1
+		}, time.Second, time.Millisecond)
+
+		require.NoError(t, storage.Restore(nil, snapshot))
+		require.Equal(t, domain.KeyValue{Key: "queue", Value: "a"}, <-result)
+
+		items, err := storage.LRange(nil, "queue", 0, -1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"b"}, items)
+		require.Empty(t, storage.waiters)
+	})
+}
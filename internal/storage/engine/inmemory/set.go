@@ -0,0 +1,169 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sort"
+)
+
+type set map[string]struct{}
+
+func (s set) sortedMembers() []string {
+	members := make([]string, 0, len(s))
+	for m := range s {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func (e *engine) SAdd(_ context.Context, key string, members []string) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s, ok, err := e.setValue(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		s = make(set, len(members))
+		e.data[key] = s
+	}
+
+	added := 0
+	for _, m := range members {
+		if _, ok := s[m]; !ok {
+			s[m] = struct{}{}
+			added++
+		}
+	}
+
+	return added, nil
+}
+
+func (e *engine) SRem(_ context.Context, key string, members []string) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s, _, err := e.setValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, m := range members {
+		if _, ok := s[m]; ok {
+			delete(s, m)
+			removed++
+		}
+	}
+	if removed > 0 && len(s) == 0 {
+		delete(e.data, key)
+	}
+
+	return removed, nil
+}
+
+func (e *engine) SMembers(_ context.Context, key string) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	s, _, err := e.setValue(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sortedMembers(), nil
+}
+
+func (e *engine) SIsMember(_ context.Context, key, member string) (bool, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	s, _, err := e.setValue(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := s[member]
+	return ok, nil
+}
+
+func (e *engine) SCard(_ context.Context, key string) (int, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	s, _, err := e.setValue(key)
+	return len(s), err
+}
+
+func (e *engine) SInter(_ context.Context, keys []string) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	sets, err := e.setValues(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(set)
+	for m := range sets[0] {
+		if slices.IndexFunc(sets[1:], func(s set) bool { _, ok := s[m]; return !ok }) == -1 {
+			result[m] = struct{}{}
+		}
+	}
+
+	return result.sortedMembers(), nil
+}
+
+func (e *engine) SUnion(_ context.Context, keys []string) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	sets, err := e.setValues(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(set)
+	for _, s := range sets {
+		for m := range s {
+			result[m] = struct{}{}
+		}
+	}
+
+	return result.sortedMembers(), nil
+}
+
+func (e *engine) SDiff(_ context.Context, keys []string) ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	sets, err := e.setValues(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(set)
+	for m := range sets[0] {
+		if slices.IndexFunc(sets[1:], func(s set) bool { _, ok := s[m]; return ok }) == -1 {
+			result[m] = struct{}{}
+		}
+	}
+
+	return result.sortedMembers(), nil
+}
+
+// setValues returns sets stored by keys, absent keys are treated as empty sets.
+// The caller must hold the lock.
+func (e *engine) setValues(keys []string) ([]set, error) {
+	sets := make([]set, len(keys))
+	for i, key := range keys {
+		s, _, err := e.setValue(key)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = s
+	}
+	return sets, nil
+}
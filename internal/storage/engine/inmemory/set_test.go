@@ -0,0 +1,65 @@
+package inmemory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_Set(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	added, err := storage.SAdd(nil, "tags:1", []string{"go", "redis", "go"})
+	require.NoError(t, err)
+	require.Equal(t, 2, added)
+
+	_, err = storage.SAdd(nil, "tags:2", []string{"go", "raft"})
+	require.NoError(t, err)
+
+	ok, err := storage.SIsMember(nil, "tags:1", "redis")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = storage.SIsMember(nil, "tags:1", "raft")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	n, err := storage.SCard(nil, "tags:1")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	members, err := storage.SMembers(nil, "tags:1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"go", "redis"}, members)
+
+	members, err = storage.SInter(nil, []string{"tags:1", "tags:2"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"go"}, members)
+
+	members, err = storage.SInter(nil, []string{"tags:1", "missing"})
+	require.NoError(t, err)
+	require.Empty(t, members)
+
+	members, err = storage.SUnion(nil, []string{"tags:1", "tags:2", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"go", "raft", "redis"}, members)
+
+	members, err = storage.SDiff(nil, []string{"tags:1", "tags:2"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"redis"}, members)
+
+	removed, err := storage.SRem(nil, "tags:1", []string{"go", "redis", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+
+	_, err = storage.Get(nil, "tags:1")
+	require.True(t, errors.Is(err, domain.ErrNotFound))
+
+	require.NoError(t, storage.Set(nil, "string", "value"))
+	_, err = storage.SUnion(nil, []string{"tags:2", "string"})
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+}
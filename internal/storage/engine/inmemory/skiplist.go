@@ -0,0 +1,193 @@
+package inmemory
+
+import (
+	"math/rand/v2"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+const (
+	skipListMaxLevel = 32
+	skipListP        = 0.25
+)
+
+type skipLevel struct {
+	forward *skipNode
+	// span is the number of nodes between the current node and forward.
+	span int
+}
+
+type skipNode struct {
+	member   string
+	score    float64
+	backward *skipNode
+	levels   []skipLevel
+}
+
+// skipList keeps members ordered by score and then by member, spans allow
+// to find members by rank in logarithmic time.
+type skipList struct {
+	header *skipNode
+	tail   *skipNode
+	length int
+	level  int
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		header: &skipNode{levels: make([]skipLevel, skipListMaxLevel)},
+		level:  1,
+	}
+}
+
+func randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && rand.Float64() < skipListP {
+		level++
+	}
+	return level
+}
+
+func (n *skipNode) before(score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+func (s *skipList) insert(score float64, member string) {
+	var (
+		update [skipListMaxLevel]*skipNode
+		rank   [skipListMaxLevel]int
+	)
+
+	x := s.header
+	for i := s.level - 1; i >= 0; i-- {
+		if i != s.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].forward != nil && x.levels[i].forward.before(score, member) {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = s.header
+			update[i].levels[i].span = s.length
+		}
+		s.level = level
+	}
+
+	x = &skipNode{member: member, score: score, levels: make([]skipLevel, level)}
+	for i := 0; i < level; i++ {
+		x.levels[i].forward = update[i].levels[i].forward
+		update[i].levels[i].forward = x
+
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+	for i := level; i < s.level; i++ {
+		update[i].levels[i].span++
+	}
+
+	if update[0] != s.header {
+		x.backward = update[0]
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x
+	} else {
+		s.tail = x
+	}
+	s.length++
+}
+
+func (s *skipList) delete(score float64, member string) bool {
+	var update [skipListMaxLevel]*skipNode
+
+	x := s.header
+	for i := s.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.before(score, member) {
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	x = x.levels[0].forward
+	if x == nil || x.score != score || x.member != member {
+		return false
+	}
+
+	for i := 0; i < s.level; i++ {
+		if update[i].levels[i].forward == x {
+			update[i].levels[i].span += x.levels[i].span - 1
+			update[i].levels[i].forward = x.levels[i].forward
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x.backward
+	} else {
+		s.tail = x.backward
+	}
+	for s.level > 1 && s.header.levels[s.level-1].forward == nil {
+		s.level--
+	}
+	s.length--
+	return true
+}
+
+// rank returns zero based position of the member.
+func (s *skipList) rank(score float64, member string) (int, bool) {
+	rank := 0
+	x := s.header
+	for i := s.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && !x.levels[i].forward.after(score, member) {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if x != s.header && x.member == member {
+			return rank - 1, true
+		}
+	}
+	return 0, false
+}
+
+func (n *skipNode) after(score float64, member string) bool {
+	return n.score > score || (n.score == score && n.member > member)
+}
+
+// byRank returns the node at zero based rank.
+func (s *skipList) byRank(rank int) *skipNode {
+	traversed := 0
+	x := s.header
+	for i := s.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && traversed+x.levels[i].span <= rank+1 {
+			traversed += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if traversed == rank+1 {
+			return x
+		}
+	}
+	return nil
+}
+
+// firstInRange returns the first node with the score inside the range.
+func (s *skipList) firstInRange(r domain.ScoreRange) *skipNode {
+	x := s.header
+	for i := s.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && !r.AboveMin(x.levels[i].forward.score) {
+			x = x.levels[i].forward
+		}
+	}
+
+	x = x.levels[0].forward
+	if x == nil || !r.BelowMax(x.score) {
+		return nil
+	}
+	return x
+}
@@ -0,0 +1,70 @@
+package inmemory
+
+import (
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestSkipList(t *testing.T) {
+	t.Parallel()
+
+	list := newSkipList()
+	expected := make(map[string]float64)
+
+	for i := range 1000 {
+		member := strconv.Itoa(rand.IntN(200))
+		if score, ok := expected[member]; ok && i%3 == 0 {
+			require.True(t, list.delete(score, member))
+			delete(expected, member)
+			continue
+		}
+		if score, ok := expected[member]; ok {
+			require.True(t, list.delete(score, member))
+		}
+
+		score := float64(rand.IntN(50))
+		list.insert(score, member)
+		expected[member] = score
+	}
+
+	sorted := make([]domain.ScoredMember, 0, len(expected))
+	for m, s := range expected {
+		sorted = append(sorted, domain.ScoredMember{Member: m, Score: s})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Score == sorted[j].Score {
+			return sorted[i].Member < sorted[j].Member
+		}
+		return sorted[i].Score < sorted[j].Score
+	})
+
+	require.Equal(t, len(sorted), list.length)
+	for i, m := range sorted {
+		rank, ok := list.rank(m.Score, m.Member)
+		require.True(t, ok)
+		require.Equal(t, i, rank)
+
+		node := list.byRank(i)
+		require.NotNil(t, node)
+		require.Equal(t, m.Member, node.member)
+	}
+	require.Nil(t, list.byRank(len(sorted)))
+
+	require.False(t, list.delete(-1, "missing"))
+	_, ok := list.rank(-1, "missing")
+	require.False(t, ok)
+
+	r := domain.ScoreRange{Min: 10, Max: 20, MinExclusive: true}
+	first := list.firstInRange(r)
+	for _, m := range sorted {
+		if r.AboveMin(m.Score) && r.BelowMax(m.Score) {
+			require.Equal(t, m.Member, first.member)
+			break
+		}
+	}
+}
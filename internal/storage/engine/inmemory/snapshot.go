@@ -0,0 +1,226 @@
+package inmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+type valueType uint8
+
+const (
+	typeString valueType = iota
+	typeHash
+	typeList
+	typeSet
+	typeSortedSet
+	typeStream
+)
+
+// valueDump is the serializable form of a value of any type.
+type valueDump struct {
+	Type      valueType
+	String    string
+	Hash      map[string]string
+	List      []string
+	Set       []string
+	SortedSet []domain.ScoredMember
+	Stream    *streamDump
+}
+
+type streamDump struct {
+	Entries []domain.StreamEntry
+	LastID  domain.StreamID
+	Groups  map[string]groupDump
+}
+
+type groupDump struct {
+	LastDelivered domain.StreamID
+	Pending       []pendingDump
+}
+
+type pendingDump struct {
+	ID          domain.StreamID
+	Consumer    string
+	DeliveredAt time.Time
+	Deliveries  int
+}
+
+// Snapshot serializes all keys at once, so the snapshot is consistent.
+func (e *engine) Snapshot(_ context.Context) ([]byte, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	dump := make(map[string]valueDump, len(e.data))
+	for key, v := range e.data {
+		dump[key] = dumpValue(v)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(dump); err != nil {
+		return nil, fmt.Errorf("encode snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Restore replaces all keys with the snapshot content, clients blocked on
+// restored lists get their elements.
+func (e *engine) Restore(_ context.Context, data []byte) error {
+	var dump map[string]valueDump
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&dump); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.data = make(map[string]any, len(dump))
+	for key, d := range dump {
+		e.data[key] = restoreValue(d)
+	}
+	for _, key := range slices.Sorted(maps.Keys(e.waiters)) {
+		if l, ok := e.data[key].(*list); ok {
+			e.serveWaiters(key, l)
+		}
+	}
+
+	close(e.streamAdded)
+	e.streamAdded = make(chan struct{})
+
+	return nil
+}
+
+// Dump serializes the value stored by the key.
+func (e *engine) Dump(_ context.Context, key string) ([]byte, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	v, ok := e.data[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(dumpValue(v)); err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RestoreKey replaces the value stored by the key with the dumped one.
+func (e *engine) RestoreKey(_ context.Context, key string, data []byte) error {
+	var d valueDump
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&d); err != nil {
+		return fmt.Errorf("decode value: %w", err)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	v := restoreValue(d)
+	e.data[key] = v
+	if l, ok := v.(*list); ok {
+		e.serveWaiters(key, l)
+	}
+
+	close(e.streamAdded)
+	e.streamAdded = make(chan struct{})
+
+	return nil
+}
+
+func dumpValue(v any) valueDump {
+	switch v := v.(type) {
+	case string:
+		return valueDump{Type: typeString, String: v}
+	case hash:
+		return valueDump{Type: typeHash, Hash: maps.Clone(v)}
+	case *list:
+		return valueDump{Type: typeList, List: slices.Clone(v.items)}
+	case set:
+		return valueDump{Type: typeSet, Set: slices.Collect(maps.Keys(v))}
+	case *zset:
+		members := make([]domain.ScoredMember, 0, len(v.scores))
+		for member, score := range v.scores {
+			members = append(members, domain.ScoredMember{Member: member, Score: score})
+		}
+		return valueDump{Type: typeSortedSet, SortedSet: members}
+	case *stream:
+		return valueDump{Type: typeStream, Stream: dumpStream(v)}
+	default:
+		panic(fmt.Sprintf("unexpected value type %T", v))
+	}
+}
+
+func dumpStream(s *stream) *streamDump {
+	d := &streamDump{
+		Entries: slices.Clone(s.entries),
+		LastID:  s.lastID,
+		Groups:  make(map[string]groupDump, len(s.groups)),
+	}
+
+	for name, g := range s.groups {
+		gd := groupDump{LastDelivered: g.lastDelivered}
+		for id, p := range g.pending {
+			gd.Pending = append(gd.Pending, pendingDump{
+				ID:          id,
+				Consumer:    p.consumer,
+				DeliveredAt: p.deliveredAt,
+				Deliveries:  p.deliveries,
+			})
+		}
+		d.Groups[name] = gd
+	}
+	return d
+}
+
+func restoreValue(d valueDump) any {
+	switch d.Type {
+	case typeHash:
+		h := make(hash, len(d.Hash))
+		maps.Copy(h, d.Hash)
+		return h
+	case typeList:
+		return &list{items: d.List}
+	case typeSet:
+		s := make(set, len(d.Set))
+		for _, member := range d.Set {
+			s[member] = struct{}{}
+		}
+		return s
+	case typeSortedSet:
+		z := newZSet()
+		for _, m := range d.SortedSet {
+			z.set(m.Member, m.Score)
+		}
+		return z
+	case typeStream:
+		return restoreStream(d.Stream)
+	default:
+		return d.String
+	}
+}
+
+func restoreStream(d *streamDump) *stream {
+	s := newStream()
+	s.entries = d.Entries
+	s.lastID = d.LastID
+
+	for name, gd := range d.Groups {
+		g := &consumerGroup{
+			lastDelivered: gd.LastDelivered,
+			pending:       make(map[domain.StreamID]*pendingEntry, len(gd.Pending)),
+		}
+		for _, p := range gd.Pending {
+			g.pending[p.ID] = &pendingEntry{consumer: p.Consumer, deliveredAt: p.DeliveredAt, deliveries: p.Deliveries}
+		}
+		s.groups[name] = g
+	}
+	return s
+}
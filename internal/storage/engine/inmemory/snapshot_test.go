@@ -0,0 +1,112 @@
+package inmemory
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_SnapshotRestore(t *testing.T) {
+	t.Parallel()
+
+	source := New()
+	require.NoError(t, source.Set(nil, "string", "value"))
+	_, err := source.HSet(nil, "hash", []domain.KeyValue{{Key: "f", Value: "v"}})
+	require.NoError(t, err)
+	_, err = source.RPush(nil, "list", []string{"a", "b"})
+	require.NoError(t, err)
+	_, err = source.SAdd(nil, "set", []string{"x"})
+	require.NoError(t, err)
+	_, err = source.ZAdd(nil, "zset", []domain.ScoredMember{{Member: "m", Score: 1.5}, {Member: "top", Score: math.Inf(1)}})
+	require.NoError(t, err)
+	_, err = source.XAdd(nil, "stream", domain.StreamID{Ms: 1}, []domain.KeyValue{{Key: "f", Value: "v"}})
+	require.NoError(t, err)
+	require.NoError(t, source.XGroupCreate(nil, "stream", "group", domain.StreamID{}, false, false))
+	_, err = source.XReadGroup(nil, "group", "consumer", []domain.StreamOffset{{Key: "stream", Latest: true}}, 0, -1)
+	require.NoError(t, err)
+
+	data, err := source.Snapshot(nil)
+	require.NoError(t, err)
+
+	target := New()
+	require.NoError(t, target.Set(nil, "stale", "value"))
+	require.NoError(t, target.Restore(nil, data))
+
+	_, err = target.Get(nil, "stale")
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	v, err := target.Get(nil, "string")
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+
+	fields, err := target.HGetAll(nil, "hash")
+	require.NoError(t, err)
+	require.Equal(t, []domain.KeyValue{{Key: "f", Value: "v"}}, fields)
+
+	items, err := target.LRange(nil, "list", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, items)
+
+	members, err := target.SMembers(nil, "set")
+	require.NoError(t, err)
+	require.Equal(t, []string{"x"}, members)
+
+	scored, err := target.ZRange(nil, "zset", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []domain.ScoredMember{{Member: "m", Score: 1.5}, {Member: "top", Score: math.Inf(1)}}, scored)
+
+	pending, err := target.XPending(nil, "stream", "group", "")
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "consumer", pending[0].Consumer)
+
+	id, err := target.XAdd(nil, "stream", domain.StreamID{}, []domain.KeyValue{{Key: "f", Value: "v"}})
+	require.NoError(t, err)
+	require.True(t, domain.StreamID{Ms: 1}.Less(id))
+}
+
+func TestEngine_StreamIDFromContextTime(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+	ctx := domain.ContextWithNow(context.Background(), time.UnixMilli(1000))
+
+	id, err := storage.XAdd(ctx, "stream", domain.StreamID{}, []domain.KeyValue{{Key: "f", Value: "v"}})
+	require.NoError(t, err)
+	require.Equal(t, domain.StreamID{Ms: 1000}, id)
+
+	id, err = storage.XAdd(ctx, "stream", domain.StreamID{}, []domain.KeyValue{{Key: "f", Value: "v"}})
+	require.NoError(t, err)
+	require.Equal(t, domain.StreamID{Ms: 1000, Seq: 1}, id)
+}
+
+func TestEngine_DumpRestoreKey(t *testing.T) {
+	t.Parallel()
+
+	source := New()
+	_, err := source.RPush(nil, "list", []string{"a", "b"})
+	require.NoError(t, err)
+	require.NoError(t, source.Set(nil, "string", "value"))
+
+	keys, err := source.Keys(nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"list", "string"}, keys)
+
+	_, err = source.Dump(nil, "missing")
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	data, err := source.Dump(nil, "list")
+	require.NoError(t, err)
+
+	target := New()
+	require.NoError(t, target.Set(nil, "list", "old"))
+	require.NoError(t, target.RestoreKey(nil, "list", data))
+
+	items, err := target.LRange(nil, "list", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, items)
+}
@@ -0,0 +1,350 @@
+package inmemory
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// stream is an append-only log of entries ordered by ID.
+type stream struct {
+	entries []domain.StreamEntry
+	lastID  domain.StreamID
+	groups  map[string]*consumerGroup
+}
+
+type consumerGroup struct {
+	lastDelivered domain.StreamID
+	// pending keeps entries delivered to consumers but not acknowledged yet.
+	pending map[domain.StreamID]*pendingEntry
+}
+
+type pendingEntry struct {
+	consumer    string
+	deliveredAt time.Time
+	deliveries  int
+}
+
+func newStream() *stream {
+	return &stream{groups: make(map[string]*consumerGroup)}
+}
+
+// after returns up to count entries with IDs greater than id, zero count means no limit.
+func (s *stream) after(id domain.StreamID, count int) []domain.StreamEntry {
+	i := sort.Search(len(s.entries), func(i int) bool { return id.Less(s.entries[i].ID) })
+	entries := s.entries[i:]
+	if count > 0 && len(entries) > count {
+		entries = entries[:count]
+	}
+	return slices.Clone(entries)
+}
+
+func (s *stream) entry(id domain.StreamID) (domain.StreamEntry, bool) {
+	i := sort.Search(len(s.entries), func(i int) bool { return !s.entries[i].ID.Less(id) })
+	if i == len(s.entries) || s.entries[i].ID != id {
+		return domain.StreamEntry{}, false
+	}
+	return s.entries[i], true
+}
+
+func (e *engine) XAdd(ctx context.Context, key string, id domain.StreamID, fields []domain.KeyValue) (domain.StreamID, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s, ok, err := e.streamValue(key)
+	if err != nil {
+		return domain.StreamID{}, err
+	}
+	if !ok {
+		s = newStream()
+	}
+
+	if id.IsZero() {
+		if id, err = nextStreamID(s.lastID, uint64(domain.Now(ctx).UnixMilli())); err != nil {
+			return domain.StreamID{}, err
+		}
+	} else if !s.lastID.Less(id) {
+		return domain.StreamID{}, domain.ErrStreamIDTooSmall
+	}
+
+	e.data[key] = s
+	s.entries = append(s.entries, domain.StreamEntry{ID: id, Fields: slices.Clone(fields)})
+	s.lastID = id
+
+	// wake up blocked readers
+	close(e.streamAdded)
+	e.streamAdded = make(chan struct{})
+
+	return id, nil
+}
+
+func (e *engine) XRange(_ context.Context, key string, start, end domain.StreamID, count int) ([]domain.StreamEntry, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	s, ok, err := e.streamValue(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	i := sort.Search(len(s.entries), func(i int) bool { return !s.entries[i].ID.Less(start) })
+
+	var entries []domain.StreamEntry
+	for ; i < len(s.entries) && !end.Less(s.entries[i].ID); i++ {
+		if count > 0 && len(entries) == count {
+			break
+		}
+		entries = append(entries, s.entries[i])
+	}
+
+	return entries, nil
+}
+
+// XRead returns entries added after offsets, blocking up to block duration when there is
+// nothing to return. Zero block waits forever, negative block does not wait at all.
+func (e *engine) XRead(ctx context.Context, offsets []domain.StreamOffset, count int, block time.Duration) ([]domain.StreamEntries, error) {
+	e.lock.RLock()
+	offsets = slices.Clone(offsets)
+	for i, o := range offsets {
+		if !o.Latest {
+			continue
+		}
+		s, _, err := e.streamValue(o.Key)
+		if err != nil {
+			e.lock.RUnlock()
+			return nil, err
+		}
+		if s != nil {
+			offsets[i].ID = s.lastID
+		}
+		offsets[i].Latest = false
+	}
+	e.lock.RUnlock()
+
+	return e.blockingRead(ctx, block, func() ([]domain.StreamEntries, error) {
+		e.lock.RLock()
+		defer e.lock.RUnlock()
+
+		var result []domain.StreamEntries
+		for _, o := range offsets {
+			s, ok, err := e.streamValue(o.Key)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if entries := s.after(o.ID, count); len(entries) > 0 {
+				result = append(result, domain.StreamEntries{Key: o.Key, Entries: entries})
+			}
+		}
+		return result, nil
+	})
+}
+
+func (e *engine) XGroupCreate(_ context.Context, key, group string, id domain.StreamID, latest, mkStream bool) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s, ok, err := e.streamValue(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if !mkStream {
+			return domain.ErrNoGroup
+		}
+		s = newStream()
+		e.data[key] = s
+	}
+
+	if _, ok := s.groups[group]; ok {
+		return domain.ErrGroupExists
+	}
+	if latest {
+		id = s.lastID
+	}
+
+	s.groups[group] = &consumerGroup{
+		lastDelivered: id,
+		pending:       make(map[domain.StreamID]*pendingEntry),
+	}
+	return nil
+}
+
+// XReadGroup delivers entries to the consumer of the group. Latest offsets return entries
+// never delivered to the group and block as XRead does, other offsets return the
+// consumer's pending entries with IDs greater than the offset ID.
+func (e *engine) XReadGroup(ctx context.Context, group, consumer string, offsets []domain.StreamOffset, count int, block time.Duration) ([]domain.StreamEntries, error) {
+	if slices.ContainsFunc(offsets, func(o domain.StreamOffset) bool { return !o.Latest }) {
+		block = -1
+	}
+
+	return e.blockingRead(ctx, block, func() ([]domain.StreamEntries, error) {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+
+		var result []domain.StreamEntries
+		for _, o := range offsets {
+			s, ok, err := e.streamValue(o.Key)
+			if err != nil {
+				return nil, err
+			}
+			if !ok || s.groups[group] == nil {
+				return nil, domain.ErrNoGroup
+			}
+
+			var entries []domain.StreamEntry
+			if o.Latest {
+				entries = s.deliver(group, consumer, count, domain.Now(ctx))
+			} else {
+				entries = s.history(group, consumer, o.ID, count)
+			}
+			if len(entries) > 0 {
+				result = append(result, domain.StreamEntries{Key: o.Key, Entries: entries})
+			}
+		}
+		return result, nil
+	})
+}
+
+func (e *engine) XAck(_ context.Context, key, group string, ids []domain.StreamID) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s, ok, err := e.streamValue(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	g, ok := s.groups[group]
+	if !ok {
+		return 0, nil
+	}
+
+	acked := 0
+	for _, id := range ids {
+		if _, ok := g.pending[id]; ok {
+			delete(g.pending, id)
+			acked++
+		}
+	}
+
+	return acked, nil
+}
+
+// XPending returns pending entries of the group ordered by ID, empty consumer means all consumers.
+func (e *engine) XPending(_ context.Context, key, group, consumer string) ([]domain.PendingEntry, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	s, ok, err := e.streamValue(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || s.groups[group] == nil {
+		return nil, domain.ErrNoGroup
+	}
+
+	now := time.Now()
+	var pending []domain.PendingEntry
+	for id, p := range s.groups[group].pending {
+		if consumer != "" && p.consumer != consumer {
+			continue
+		}
+		pending = append(pending, domain.PendingEntry{
+			ID:         id,
+			Consumer:   p.consumer,
+			Idle:       now.Sub(p.deliveredAt),
+			Deliveries: p.deliveries,
+		})
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID.Less(pending[j].ID) })
+	return pending, nil
+}
+
+// deliver hands entries never delivered to the group to the consumer.
+func (s *stream) deliver(group, consumer string, count int, now time.Time) []domain.StreamEntry {
+	g := s.groups[group]
+	entries := s.after(g.lastDelivered, count)
+
+	for _, entry := range entries {
+		g.pending[entry.ID] = &pendingEntry{consumer: consumer, deliveredAt: now, deliveries: 1}
+		g.lastDelivered = entry.ID
+	}
+	return entries
+}
+
+// history returns entries pending for the consumer with IDs greater than id.
+func (s *stream) history(group, consumer string, id domain.StreamID, count int) []domain.StreamEntry {
+	g := s.groups[group]
+
+	var ids []domain.StreamID
+	for pendingID, p := range g.pending {
+		if p.consumer == consumer && id.Less(pendingID) {
+			ids = append(ids, pendingID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Less(ids[j]) })
+	if count > 0 && len(ids) > count {
+		ids = ids[:count]
+	}
+
+	entries := make([]domain.StreamEntry, 0, len(ids))
+	for _, pendingID := range ids {
+		if entry, ok := s.entry(pendingID); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// blockingRead calls read until it returns entries, block expires or ctx is done.
+func (e *engine) blockingRead(ctx context.Context, block time.Duration, read func() ([]domain.StreamEntries, error)) ([]domain.StreamEntries, error) {
+	var expired <-chan time.Time
+	if block > 0 {
+		t := time.NewTimer(block)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	for {
+		// take the signal before reading to not miss entries added in between
+		e.lock.RLock()
+		added := e.streamAdded
+		e.lock.RUnlock()
+
+		result, err := read()
+		if err != nil || len(result) > 0 || block < 0 {
+			return result, err
+		}
+
+		select {
+		case <-added:
+		case <-expired:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// nextStreamID generates the ID following the last one for the entry created at
+// nowMs, the sequence overflows into the next millisecond.
+func nextStreamID(last domain.StreamID, nowMs uint64) (domain.StreamID, error) {
+	switch {
+	case nowMs > last.Ms:
+		return domain.StreamID{Ms: nowMs}, nil
+	case last.Seq < math.MaxUint64:
+		return domain.StreamID{Ms: last.Ms, Seq: last.Seq + 1}, nil
+	case last.Ms < math.MaxUint64:
+		return domain.StreamID{Ms: last.Ms + 1}, nil
+	default:
+		return domain.StreamID{}, domain.ErrStreamExhausted
+	}
+}
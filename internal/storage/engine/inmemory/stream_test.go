@@ -0,0 +1,167 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_StreamAddRange(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+	fields := []domain.KeyValue{{Key: "event", Value: "login"}}
+
+	id1, err := storage.XAdd(nil, "events", domain.StreamID{}, fields)
+	require.NoError(t, err)
+	id2, err := storage.XAdd(nil, "events", domain.StreamID{}, fields)
+	require.NoError(t, err)
+	require.True(t, id1.Less(id2))
+
+	_, err = storage.XAdd(nil, "events", id2, fields)
+	require.True(t, errors.Is(err, domain.ErrStreamIDTooSmall))
+
+	id3 := domain.StreamID{Ms: id2.Ms + 1000, Seq: 5}
+	added, err := storage.XAdd(nil, "events", id3, fields)
+	require.NoError(t, err)
+	require.Equal(t, id3, added)
+
+	entries, err := storage.XRange(nil, "events", domain.StreamID{}, domain.MaxStreamID, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, []domain.StreamID{id1, id2, id3}, []domain.StreamID{entries[0].ID, entries[1].ID, entries[2].ID})
+	require.Equal(t, fields, entries[0].Fields)
+
+	entries, err = storage.XRange(nil, "events", id2, domain.MaxStreamID, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, id2, entries[0].ID)
+
+	entries, err = storage.XRange(nil, "missing", domain.StreamID{}, domain.MaxStreamID, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	for _, tc := range []struct {
+		last  domain.StreamID
+		nowMs uint64
+		next  domain.StreamID
+	}{
+		{domain.StreamID{Ms: 10, Seq: 2}, 5, domain.StreamID{Ms: 10, Seq: 3}},
+		{domain.StreamID{Ms: 10, Seq: 2}, 11, domain.StreamID{Ms: 11}},
+		{domain.StreamID{Ms: 10, Seq: math.MaxUint64}, 10, domain.StreamID{Ms: 11}},
+	} {
+		next, err := nextStreamID(tc.last, tc.nowMs)
+		require.NoError(t, err)
+		require.Equal(t, tc.next, next)
+	}
+
+	_, err = storage.XAdd(nil, "exhausted", domain.MaxStreamID, fields)
+	require.NoError(t, err)
+	_, err = storage.XAdd(nil, "exhausted", domain.StreamID{}, fields)
+	require.ErrorIs(t, err, domain.ErrStreamExhausted)
+}
+
+func TestEngine_StreamRead(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+	fields := []domain.KeyValue{{Key: "n", Value: "1"}}
+
+	id1, err := storage.XAdd(nil, "events", domain.StreamID{}, fields)
+	require.NoError(t, err)
+
+	result, err := storage.XRead(context.Background(), []domain.StreamOffset{{Key: "events"}, {Key: "missing"}}, 0, -1)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.Equal(t, "events", result[0].Key)
+	require.Equal(t, id1, result[0].Entries[0].ID)
+
+	result, err = storage.XRead(context.Background(), []domain.StreamOffset{{Key: "events", Latest: true}}, 0, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	done := make(chan []domain.StreamEntries)
+	go func() {
+		result, err := storage.XRead(context.Background(), []domain.StreamOffset{{Key: "events", Latest: true}}, 0, 0)
+		require.NoError(t, err)
+		done <- result
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	id2, err := storage.XAdd(nil, "events", domain.StreamID{}, fields)
+	require.NoError(t, err)
+
+	result = <-done
+	require.Len(t, result, 1)
+	require.Len(t, result[0].Entries, 1)
+	require.Equal(t, id2, result[0].Entries[0].ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = storage.XRead(ctx, []domain.StreamOffset{{Key: "events", Latest: true}}, 0, 0)
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestEngine_StreamGroups(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+	ctx := context.Background()
+	fields := []domain.KeyValue{{Key: "job", Value: "1"}}
+
+	err := storage.XGroupCreate(nil, "jobs", "workers", domain.StreamID{}, false, false)
+	require.True(t, errors.Is(err, domain.ErrNoGroup))
+
+	require.NoError(t, storage.XGroupCreate(nil, "jobs", "workers", domain.StreamID{}, true, true))
+	err = storage.XGroupCreate(nil, "jobs", "workers", domain.StreamID{}, true, true)
+	require.True(t, errors.Is(err, domain.ErrGroupExists))
+
+	id1, err := storage.XAdd(nil, "jobs", domain.StreamID{}, fields)
+	require.NoError(t, err)
+	id2, err := storage.XAdd(nil, "jobs", domain.StreamID{}, fields)
+	require.NoError(t, err)
+
+	latest := []domain.StreamOffset{{Key: "jobs", Latest: true}}
+
+	result, err := storage.XReadGroup(ctx, "workers", "alice", latest, 1, -1)
+	require.NoError(t, err)
+	require.Equal(t, id1, result[0].Entries[0].ID)
+
+	result, err = storage.XReadGroup(ctx, "workers", "bob", latest, 0, -1)
+	require.NoError(t, err)
+	require.Len(t, result[0].Entries, 1)
+	require.Equal(t, id2, result[0].Entries[0].ID)
+
+	result, err = storage.XReadGroup(ctx, "workers", "bob", latest, 0, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	pending, err := storage.XPending(nil, "jobs", "workers", "")
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, "alice", pending[0].Consumer)
+	require.Equal(t, id1, pending[0].ID)
+	require.Equal(t, 1, pending[0].Deliveries)
+	require.Equal(t, "bob", pending[1].Consumer)
+
+	history, err := storage.XReadGroup(ctx, "workers", "alice", []domain.StreamOffset{{Key: "jobs"}}, 0, -1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, id1, history[0].Entries[0].ID)
+
+	acked, err := storage.XAck(nil, "jobs", "workers", []domain.StreamID{id1, id1, {Ms: 1}})
+	require.NoError(t, err)
+	require.Equal(t, 1, acked)
+
+	pending, err = storage.XPending(nil, "jobs", "workers", "alice")
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	_, err = storage.XReadGroup(ctx, "missing", "alice", latest, 0, -1)
+	require.True(t, errors.Is(err, domain.ErrNoGroup))
+}
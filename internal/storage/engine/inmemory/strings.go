@@ -0,0 +1,128 @@
+package inmemory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func (e *engine) Append(_ context.Context, key, value string) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	v, _, err := e.stringValue(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) > domain.MaxStringSize-len(value) {
+		return 0, domain.ErrOutOfRange
+	}
+
+	v += value
+	e.data[key] = v
+	return len(v), nil
+}
+
+func (e *engine) GetRange(_ context.Context, key string, start, end int) (string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	v, _, err := e.stringValue(key)
+	if err != nil {
+		return "", err
+	}
+
+	start, end, ok := normalizeRange(start, end, len(v))
+	if !ok {
+		return "", nil
+	}
+
+	return v[start : end+1], nil
+}
+
+func (e *engine) SetRange(_ context.Context, key string, offset int, value string) (int, error) {
+	// compared this way offset+len(value) cannot overflow
+	if offset < 0 || offset > domain.MaxStringSize-len(value) {
+		return 0, domain.ErrOutOfRange
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	v, _, err := e.stringValue(key)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return len(v), nil
+	}
+
+	if offset > len(v) {
+		v += strings.Repeat("\x00", offset-len(v))
+	}
+
+	tail := ""
+	if offset+len(value) < len(v) {
+		tail = v[offset+len(value):]
+	}
+
+	v = v[:offset] + value + tail
+	e.data[key] = v
+	return len(v), nil
+}
+
+func (e *engine) StrLen(_ context.Context, key string) (int, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	v, _, err := e.stringValue(key)
+	return len(v), err
+}
+
+func (e *engine) GetSet(_ context.Context, key, value string) (string, bool, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	old, ok, err := e.stringValue(key)
+	if err != nil {
+		return "", false, err
+	}
+
+	e.data[key] = value
+	return old, ok, nil
+}
+
+func (e *engine) SetNX(_ context.Context, key, value string) (bool, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if _, ok := e.data[key]; ok {
+		return false, nil
+	}
+
+	e.data[key] = value
+	return true, nil
+}
+
+// normalizeRange converts inclusive start and end indexes, which may be negative
+// to count from the end, into valid bounds for a sequence of the given length.
+func normalizeRange(start, end, length int) (int, int, bool) {
+	if start < 0 {
+		start += length
+	}
+	if end < 0 {
+		end += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end >= length {
+		end = length - 1
+	}
+	if length == 0 || start > end {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
@@ -0,0 +1,129 @@
+package inmemory
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_AppendStrLen(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	n, err := storage.StrLen(nil, "log")
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = storage.Append(nil, "log", "Hello")
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+
+	n, err = storage.Append(nil, "log", "World")
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+
+	val, err := storage.Get(nil, "log")
+	require.NoError(t, err)
+	require.Equal(t, "HelloWorld", val)
+
+	n, err = storage.StrLen(nil, "log")
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+
+	// a value of the max size without allocating it, APPEND must not read it
+	buf := []byte("x")
+	require.NoError(t, storage.Set(nil, "big", unsafe.String(&buf[0], domain.MaxStringSize)))
+	_, err = storage.Append(nil, "big", "x")
+	require.True(t, errors.Is(err, domain.ErrOutOfRange))
+}
+
+func TestEngine_GetRange(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+	require.NoError(t, storage.Set(nil, "key", "This is a string"))
+
+	tt := []struct {
+		start, end int
+		out        string
+	}{
+		{0, 3, "This"},
+		{-3, -1, "ing"},
+		{0, -1, "This is a string"},
+		{10, 100, "string"},
+		{5, 3, ""},
+		{100, 200, ""},
+	}
+
+	for _, c := range tt {
+		val, err := storage.GetRange(nil, "key", c.start, c.end)
+		require.NoError(t, err)
+		require.Equalf(t, c.out, val, "range %d %d", c.start, c.end)
+	}
+
+	val, err := storage.GetRange(nil, "missing", 0, -1)
+	require.NoError(t, err)
+	require.Empty(t, val)
+}
+
+func TestEngine_SetRange(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+	require.NoError(t, storage.Set(nil, "key", "Hello World"))
+
+	n, err := storage.SetRange(nil, "key", 6, "Redis")
+	require.NoError(t, err)
+	require.Equal(t, 11, n)
+
+	val, err := storage.Get(nil, "key")
+	require.NoError(t, err)
+	require.Equal(t, "Hello Redis", val)
+
+	n, err = storage.SetRange(nil, "padded", 3, "abc")
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+
+	val, err = storage.Get(nil, "padded")
+	require.NoError(t, err)
+	require.Equal(t, "\x00\x00\x00abc", val)
+
+	_, err = storage.SetRange(nil, "key", domain.MaxStringSize, "x")
+	require.True(t, errors.Is(err, domain.ErrOutOfRange))
+
+	_, err = storage.SetRange(nil, "key", math.MaxInt-1, "ab")
+	require.True(t, errors.Is(err, domain.ErrOutOfRange))
+}
+
+func TestEngine_GetSetSetNX(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	ok, err := storage.SetNX(nil, "lock", "owner-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = storage.SetNX(nil, "lock", "owner-2")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	old, existed, err := storage.GetSet(nil, "lock", "owner-3")
+	require.NoError(t, err)
+	require.True(t, existed)
+	require.Equal(t, "owner-1", old)
+
+	old, existed, err = storage.GetSet(nil, "new", "value")
+	require.NoError(t, err)
+	require.False(t, existed)
+	require.Empty(t, old)
+
+	val, err := storage.Get(nil, "lock")
+	require.NoError(t, err)
+	require.Equal(t, "owner-3", val)
+}
@@ -0,0 +1,181 @@
+package inmemory
+
+import (
+	"context"
+	"math"
+
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+// zset is a sorted set: the map gives member scores in constant time,
+// the skip list keeps members ordered.
+type zset struct {
+	scores map[string]float64
+	list   *skipList
+}
+
+func newZSet() *zset {
+	return &zset{scores: make(map[string]float64), list: newSkipList()}
+}
+
+func (z *zset) set(member string, score float64) bool {
+	old, ok := z.scores[member]
+	if ok {
+		if old == score {
+			return false
+		}
+		z.list.delete(old, member)
+	}
+
+	z.scores[member] = score
+	z.list.insert(score, member)
+	return !ok
+}
+
+func (e *engine) ZAdd(_ context.Context, key string, members []domain.ScoredMember) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	z, ok, err := e.zsetValue(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		z = newZSet()
+		e.data[key] = z
+	}
+
+	added := 0
+	for _, m := range members {
+		if z.set(m.Member, m.Score) {
+			added++
+		}
+	}
+
+	return added, nil
+}
+
+func (e *engine) ZRem(_ context.Context, key string, members []string) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	z, _, err := e.zsetValue(key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, m := range members {
+		score, ok := z.scores[m]
+		if !ok {
+			continue
+		}
+		delete(z.scores, m)
+		z.list.delete(score, m)
+		removed++
+	}
+	if removed > 0 && len(z.scores) == 0 {
+		delete(e.data, key)
+	}
+
+	return removed, nil
+}
+
+func (e *engine) ZScore(_ context.Context, key, member string) (float64, bool, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	z, _, err := e.zsetValue(key)
+	if err != nil || z == nil {
+		return 0, false, err
+	}
+
+	score, ok := z.scores[member]
+	return score, ok, nil
+}
+
+func (e *engine) ZRank(_ context.Context, key, member string) (int, bool, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	z, _, err := e.zsetValue(key)
+	if err != nil || z == nil {
+		return 0, false, err
+	}
+
+	score, ok := z.scores[member]
+	if !ok {
+		return 0, false, nil
+	}
+
+	rank, ok := z.list.rank(score, member)
+	return rank, ok, nil
+}
+
+func (e *engine) ZRange(_ context.Context, key string, start, stop int) ([]domain.ScoredMember, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	z, _, err := e.zsetValue(key)
+	if err != nil || z == nil {
+		return nil, err
+	}
+
+	start, stop, ok := normalizeRange(start, stop, z.list.length)
+	if !ok {
+		return nil, nil
+	}
+
+	members := make([]domain.ScoredMember, 0, stop-start+1)
+	for x := z.list.byRank(start); x != nil && len(members) < stop-start+1; x = x.levels[0].forward {
+		members = append(members, domain.ScoredMember{Member: x.member, Score: x.score})
+	}
+
+	return members, nil
+}
+
+func (e *engine) ZRangeByScore(_ context.Context, key string, r domain.ScoreRange) ([]domain.ScoredMember, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	z, _, err := e.zsetValue(key)
+	if err != nil || z == nil {
+		return nil, err
+	}
+
+	var members []domain.ScoredMember
+	for x := z.list.firstInRange(r); x != nil && r.BelowMax(x.score); x = x.levels[0].forward {
+		members = append(members, domain.ScoredMember{Member: x.member, Score: x.score})
+	}
+
+	return members, nil
+}
+
+func (e *engine) ZIncrBy(_ context.Context, key, member string, delta float64) (float64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	z, ok, err := e.zsetValue(key)
+	if err != nil {
+		return 0, err
+	}
+
+	score := z.scoreOf(member) + delta
+	if math.IsNaN(score) {
+		return 0, domain.ErrNotFloat
+	}
+
+	if !ok {
+		z = newZSet()
+		e.data[key] = z
+	}
+	z.set(member, score)
+	return score, nil
+}
+
+func (z *zset) scoreOf(member string) float64 {
+	if z == nil {
+		return 0
+	}
+	return z.scores[member]
+}
@@ -0,0 +1,96 @@
+package inmemory
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/domain"
+)
+
+func TestEngine_SortedSet(t *testing.T) {
+	t.Parallel()
+
+	storage := New()
+
+	added, err := storage.ZAdd(nil, "board", []domain.ScoredMember{
+		{Member: "alice", Score: 100},
+		{Member: "bob", Score: 50},
+		{Member: "carol", Score: 75},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, added)
+
+	added, err = storage.ZAdd(nil, "board", []domain.ScoredMember{
+		{Member: "bob", Score: 150},
+		{Member: "dave", Score: 75},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, added)
+
+	score, ok, err := storage.ZScore(nil, "board", "bob")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 150.0, score)
+
+	_, ok, err = storage.ZScore(nil, "board", "missing")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	rank, ok, err := storage.ZRank(nil, "board", "dave")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, rank)
+
+	members, err := storage.ZRange(nil, "board", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, []domain.ScoredMember{
+		{Member: "carol", Score: 75},
+		{Member: "dave", Score: 75},
+		{Member: "alice", Score: 100},
+		{Member: "bob", Score: 150},
+	}, members)
+
+	members, err = storage.ZRange(nil, "board", -2, -1)
+	require.NoError(t, err)
+	require.Equal(t, []domain.ScoredMember{
+		{Member: "alice", Score: 100},
+		{Member: "bob", Score: 150},
+	}, members)
+
+	members, err = storage.ZRangeByScore(nil, "board", domain.ScoreRange{Min: 75, Max: 150, MinExclusive: true})
+	require.NoError(t, err)
+	require.Equal(t, []domain.ScoredMember{
+		{Member: "alice", Score: 100},
+		{Member: "bob", Score: 150},
+	}, members)
+
+	members, err = storage.ZRangeByScore(nil, "board", domain.ScoreRange{Min: math.Inf(-1), Max: 75})
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	score, err = storage.ZIncrBy(nil, "board", "carol", 100)
+	require.NoError(t, err)
+	require.Equal(t, 175.0, score)
+
+	rank, _, err = storage.ZRank(nil, "board", "carol")
+	require.NoError(t, err)
+	require.Equal(t, 3, rank)
+
+	score, err = storage.ZIncrBy(nil, "new", "member", 1.5)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, score)
+
+	removed, err := storage.ZRem(nil, "board", []string{"alice", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	_, ok, err = storage.ZRank(nil, "board", "alice")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, storage.Set(nil, "string", "value"))
+	_, err = storage.ZAdd(nil, "string", []domain.ScoredMember{{Member: "m", Score: 1}})
+	require.True(t, errors.Is(err, domain.ErrWrongType))
+}
@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/tmvrus/key-value-storage/internal/config"
+	"github.com/tmvrus/key-value-storage/internal/domain"
 	"github.com/tmvrus/key-value-storage/internal/storage/engine/inmemory"
 )
 
@@ -11,6 +13,113 @@ type Storage interface {
 	Set(cxt context.Context, key, value string) error
 	Get(cxt context.Context, key string) (string, error)
 	Delete(cxt context.Context, key string) error
+	// Exists reports whether the key holds a value of any type.
+	Exists(cxt context.Context, key string) (bool, error)
+	// Keys returns all stored keys in lexicographical order.
+	Keys(cxt context.Context) ([]string, error)
+
+	IncrBy(cxt context.Context, key string, delta int64) (int64, error)
+	IncrByFloat(cxt context.Context, key string, delta float64) (float64, error)
+
+	Append(cxt context.Context, key, value string) (int, error)
+	GetRange(cxt context.Context, key string, start, end int) (string, error)
+	SetRange(cxt context.Context, key string, offset int, value string) (int, error)
+	StrLen(cxt context.Context, key string) (int, error)
+	GetSet(cxt context.Context, key, value string) (old string, existed bool, err error)
+	SetNX(cxt context.Context, key, value string) (bool, error)
+
+	// MGet returns values in the order of keys, nil for the absent ones.
+	MGet(cxt context.Context, keys []string) ([]*string, error)
+	// MSet stores all pairs atomically.
+	MSet(cxt context.Context, pairs []domain.KeyValue) error
+	// MDelete reports for every key whether it was deleted.
+	MDelete(cxt context.Context, keys []string) ([]bool, error)
+
+	Hashes
+	Lists
+	Sets
+	SortedSets
+	Streams
+	Snapshots
+}
+
+type Hashes interface {
+	// HSet returns the number of fields added to the hash.
+	HSet(cxt context.Context, key string, fields []domain.KeyValue) (int, error)
+	HGet(cxt context.Context, key, field string) (string, error)
+	// HDel returns the number of fields removed from the hash.
+	HDel(cxt context.Context, key string, fields []string) (int, error)
+	HGetAll(cxt context.Context, key string) ([]domain.KeyValue, error)
+	HKeys(cxt context.Context, key string) ([]string, error)
+	HVals(cxt context.Context, key string) ([]string, error)
+	HLen(cxt context.Context, key string) (int, error)
+	HIncrBy(cxt context.Context, key, field string, delta int64) (int64, error)
+}
+
+type Lists interface {
+	// LPush and RPush return the length of the list after the push.
+	LPush(cxt context.Context, key string, values []string) (int, error)
+	RPush(cxt context.Context, key string, values []string) (int, error)
+	LPop(cxt context.Context, key string) (string, bool, error)
+	RPop(cxt context.Context, key string) (string, bool, error)
+	LRange(cxt context.Context, key string, start, stop int) ([]string, error)
+	LLen(cxt context.Context, key string) (int, error)
+	LTrim(cxt context.Context, key string, start, stop int) error
+	// BLPop and BRPop block until an element is pushed to one of keys, timeout expires
+	// or cxt is done. Zero timeout means no limit.
+	BLPop(cxt context.Context, keys []string, timeout time.Duration) (domain.KeyValue, bool, error)
+	BRPop(cxt context.Context, keys []string, timeout time.Duration) (domain.KeyValue, bool, error)
+}
+
+type Sets interface {
+	// SAdd returns the number of members added to the set.
+	SAdd(cxt context.Context, key string, members []string) (int, error)
+	// SRem returns the number of members removed from the set.
+	SRem(cxt context.Context, key string, members []string) (int, error)
+	SMembers(cxt context.Context, key string) ([]string, error)
+	SIsMember(cxt context.Context, key, member string) (bool, error)
+	SInter(cxt context.Context, keys []string) ([]string, error)
+	SUnion(cxt context.Context, keys []string) ([]string, error)
+	SDiff(cxt context.Context, keys []string) ([]string, error)
+	SCard(cxt context.Context, key string) (int, error)
+}
+
+type SortedSets interface {
+	// ZAdd returns the number of new members, scores of existing ones are updated.
+	ZAdd(cxt context.Context, key string, members []domain.ScoredMember) (int, error)
+	ZRem(cxt context.Context, key string, members []string) (int, error)
+	ZScore(cxt context.Context, key, member string) (float64, bool, error)
+	ZRank(cxt context.Context, key, member string) (int, bool, error)
+	ZRange(cxt context.Context, key string, start, stop int) ([]domain.ScoredMember, error)
+	ZRangeByScore(cxt context.Context, key string, r domain.ScoreRange) ([]domain.ScoredMember, error)
+	ZIncrBy(cxt context.Context, key, member string, delta float64) (float64, error)
+}
+
+type Streams interface {
+	// XAdd appends the entry with the given ID to the stream, zero ID is generated
+	// automatically. The ID of the added entry is returned.
+	XAdd(cxt context.Context, key string, id domain.StreamID, fields []domain.KeyValue) (domain.StreamID, error)
+	// XRange returns up to count entries with IDs in the inclusive range, zero count means no limit.
+	XRange(cxt context.Context, key string, start, end domain.StreamID, count int) ([]domain.StreamEntry, error)
+	// XRead returns entries added after offsets, blocking up to block duration when there is
+	// nothing to return. Zero block waits forever, negative block does not wait at all.
+	XRead(cxt context.Context, offsets []domain.StreamOffset, count int, block time.Duration) ([]domain.StreamEntries, error)
+	XGroupCreate(cxt context.Context, key, group string, id domain.StreamID, latest, mkStream bool) error
+	XReadGroup(cxt context.Context, group, consumer string, offsets []domain.StreamOffset, count int, block time.Duration) ([]domain.StreamEntries, error)
+	// XAck returns the number of acknowledged pending entries.
+	XAck(cxt context.Context, key, group string, ids []domain.StreamID) (int, error)
+	// XPending returns pending entries of the group, empty consumer means all consumers.
+	XPending(cxt context.Context, key, group, consumer string) ([]domain.PendingEntry, error)
+}
+
+type Snapshots interface {
+	// Snapshot serializes all stored data consistently.
+	Snapshot(cxt context.Context) ([]byte, error)
+	// Restore replaces all stored data with the snapshot content.
+	Restore(cxt context.Context, data []byte) error
+	// Dump serializes a single key value, RestoreKey replaces the key value with the dumped one.
+	Dump(cxt context.Context, key string) ([]byte, error)
+	RestoreKey(cxt context.Context, key string, data []byte) error
 }
 
 func New(cfg *config.Config) Storage {
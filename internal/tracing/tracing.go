@@ -0,0 +1,106 @@
+// Package tracing sets up OpenTelemetry tracing and carries the trace context
+// over the text protocol. A command line may start with the W3C traceparent
+// header written as traceparent=00-<trace id>-<span id>-<flags>.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tmvrus/key-value-storage/internal/config"
+)
+
+const (
+	traceParentKey = "traceparent"
+	headerPrefix   = traceParentKey + "="
+)
+
+// Setup installs the tracer provider of the config as the global one, spans
+// of the stdout exporter are written to w. Tracing stays a no-op when it is
+// disabled. The returned func flushes spans and stops the provider.
+func Setup(ctx context.Context, cfg *config.Config, w io.Writer) (func(context.Context) error, error) {
+	if !cfg.Tracing.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Tracing.Exporter {
+	case config.TraceExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case config.TraceExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Tracing.Endpoint)}
+		if cfg.Tracing.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.Tracing.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Header returns the protocol header carrying the span context of ctx, it is
+// empty when ctx has no valid span context.
+func Header(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if v := carrier.Get(traceParentKey); v != "" {
+		return headerPrefix + v
+	}
+	return ""
+}
+
+// SplitHeader splits the leading header off the command line, the header is
+// empty when the line has none.
+func SplitHeader(line string) (header, rest string) {
+	if !strings.HasPrefix(line, headerPrefix) {
+		return "", line
+	}
+	header, rest, _ = strings.Cut(line, " ")
+	return header, rest
+}
+
+// Extract returns ctx carrying the remote span context of the header, ctx is
+// returned as is when the header is empty or invalid.
+func Extract(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{traceParentKey: strings.TrimPrefix(header, headerPrefix)}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
+
+// End ends the span recording the error it failed with.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
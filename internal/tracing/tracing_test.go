@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHeader(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, Header(context.Background()))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	})
+	header := Header(trace.ContextWithSpanContext(context.Background(), sc))
+	require.Equal(t, "traceparent=00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01", header)
+
+	h, rest := SplitHeader(header + " SET k v")
+	require.Equal(t, header, h)
+	require.Equal(t, "SET k v", rest)
+
+	h, rest = SplitHeader("SET k traceparent=v")
+	require.Empty(t, h)
+	require.Equal(t, "SET k traceparent=v", rest)
+
+	remote := trace.SpanContextFromContext(Extract(context.Background(), h+header))
+	require.Equal(t, sc.TraceID(), remote.TraceID())
+	require.Equal(t, sc.SpanID(), remote.SpanID())
+	require.True(t, remote.IsRemote())
+
+	require.False(t, trace.SpanContextFromContext(Extract(context.Background(), "traceparent=garbage")).IsValid())
+}
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+
+	shutdown, err := Setup(ctx, config.NewConfigWithDefaults(), nil)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(ctx))
+
+	cfg := config.NewConfigWithDefaults()
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.Exporter = config.TraceExporterStdout
+	var out bytes.Buffer
+	shutdown, err = Setup(ctx, cfg, &out)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(ctx, "operation")
+	span.End()
+	require.NoError(t, shutdown(ctx))
+	require.Contains(t, out.String(), `"Name":"operation"`)
+	require.Contains(t, out.String(), `"Value":"key-value-storage"`)
+}
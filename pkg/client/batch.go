@@ -0,0 +1,52 @@
+package client
+
+// MGet returns values in the order of keys, nil for the absent ones. The text
+// protocol replies (nil) for an absent key and "" for an empty value, so stored
+// values spelled exactly like that can not be told apart from them, use
+// GRPCClient when values may be arbitrary.
+func (c *Client) MGet(keys ...string) ([]*string, error) {
+	lines, err := c.callMulti(append([]string{"MGET"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*string, len(lines))
+	for i, l := range lines {
+		switch l {
+		case nilResult:
+		case emptyResult:
+			values[i] = new(string)
+		default:
+			values[i] = &l
+		}
+	}
+
+	return values, nil
+}
+
+// MSet stores all pairs atomically.
+func (c *Client) MSet(pairs map[string]string) error {
+	args := make([]string, 0, 1+len(pairs)*2)
+	args = append(args, "MSET")
+	for k, v := range pairs {
+		args = append(args, k, v)
+	}
+
+	_, err := c.call(args...)
+	return err
+}
+
+// MDelete reports for every key whether it was deleted.
+func (c *Client) MDelete(keys ...string) ([]bool, error) {
+	lines, err := c.callMulti(append([]string{"MDELETE"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make([]bool, len(lines))
+	for i, l := range lines {
+		deleted[i] = l == "1"
+	}
+
+	return deleted, nil
+}
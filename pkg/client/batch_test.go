@@ -0,0 +1,118 @@
+package client
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_ClientBatch(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("MGet parses multi-line reply", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("MGET a b c\n")}).Return(0, nil)
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "3\nA\n(nil)\n\"\"\n"), nil
+			})
+
+		values, err := NewClient(socketMock, log).MGet("a", "b", "c")
+		require.NoError(t, err)
+		require.Len(t, values, 3)
+		require.Equal(t, "A", *values[0])
+		require.Nil(t, values[1])
+		require.Equal(t, "", *values[2])
+	})
+
+	t.Run("MGet rejects negative lines number", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("MGET a\n")}).Return(0, nil)
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "-1\n"), nil
+			})
+
+		_, err := NewClient(socketMock, log).MGet("a")
+		require.ErrorContains(t, err, "negative lines number -1")
+	})
+
+	t.Run("MSet sends pairs", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("MSET a 1\n")}).Return(0, nil)
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "OK\n"), nil
+			})
+
+		require.NoError(t, NewClient(socketMock, log).MSet(map[string]string{"a": "1"}))
+	})
+
+	t.Run("MDelete returns server error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("MDELETE a b\n")}).Return(0, nil)
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "ERROR: storage failure\n"), nil
+			})
+
+		_, err := NewClient(socketMock, log).MDelete("a", "b")
+		var serverErr ServerError
+		require.True(t, errors.As(err, &serverErr))
+		require.Equal(t, "storage failure", serverErr.Message)
+	})
+
+	t.Run("MDelete parses per key results", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("MDELETE a b\n")}).Return(0, nil)
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "2\n1\n0\n"), nil
+			})
+
+		deleted, err := NewClient(socketMock, log).MDelete("a", "b")
+		require.NoError(t, err)
+		require.Equal(t, []bool{true, false}, deleted)
+	})
+}
@@ -2,18 +2,52 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
+	"strconv"
+	"strings"
 	"syscall"
+
+	"github.com/tmvrus/key-value-storage/internal/netaddr"
+	"github.com/tmvrus/key-value-storage/internal/tracing"
 )
 
 const defaultReadBufferSize = 1024
 
+const (
+	errorPrefix = "ERROR: "
+	nilResult   = "(nil)"
+	emptyResult = `""`
+)
+
+// ServerError is returned when the server replies with an error line.
+type ServerError struct {
+	Message string
+}
+
+func (e ServerError) Error() string {
+	return e.Message
+}
+
 type Client struct {
 	socket readerWriter
+	reader *bufio.Reader
 	log    *slog.Logger
+	// ctx is set by WithContext.
+	ctx context.Context
+}
+
+// WithContext returns a client sending commands over the same connection
+// along with the trace context of ctx, so server spans join the caller trace.
+// Commands are not canceled with ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
 }
 
 func (c *Client) execute(cmd []byte) ([]byte, error) {
@@ -31,6 +65,76 @@ func (c *Client) execute(cmd []byte) ([]byte, error) {
 	return result[:n], nil
 }
 
+// call sends a command built from args and reads a single line reply.
+func (c *Client) call(args ...string) (string, error) {
+	line := strings.Join(args, " ")
+	if c.ctx != nil {
+		if header := tracing.Header(c.ctx); header != "" {
+			line = header + " " + line
+		}
+	}
+
+	_, err := c.socket.Write([]byte(line + "\n"))
+	if err != nil {
+		return "", fmt.Errorf("write command: %w", err)
+	}
+
+	return c.readLine()
+}
+
+// Do sends the command built from args and returns its single line reply,
+// it is meant for commands without a dedicated method.
+func (c *Client) Do(args ...string) (string, error) {
+	return c.call(args...)
+}
+
+// Ping checks the server answers on the connection.
+func (c *Client) Ping() error {
+	_, err := c.call("PING")
+	return err
+}
+
+// callMulti sends a command built from args and reads a multi-line reply,
+// which starts with the number of the following lines. Nil reply gives no lines.
+func (c *Client) callMulti(args ...string) ([]string, error) {
+	line, err := c.call(args...)
+	if err != nil || line == nilResult {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(line)
+	if err != nil {
+		return nil, fmt.Errorf("parse lines number %q: %w", line, err)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("negative lines number %d", n)
+	}
+
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i], err = c.readLine()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
+
+func (c *Client) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("read result: %w", err)
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	if strings.HasPrefix(line, errorPrefix) {
+		return "", ServerError{Message: strings.TrimPrefix(line, errorPrefix)}
+	}
+
+	return line, nil
+}
+
 func (c *Client) StartInteractionLoop(in io.Reader, out io.Writer) {
 	const eolByte byte = '\n'
 
@@ -75,7 +179,12 @@ func (c *Client) StartInteractionLoop(in io.Reader, out io.Writer) {
 }
 
 func NewClient(i readerWriter, log *slog.Logger) *Client {
-	return &Client{socket: i, log: log}
+	return &Client{socket: i, reader: bufio.NewReader(i), log: log}
+}
+
+// Dial connects to host:port, tcp://host:port or unix:///path/to.sock.
+func Dial(address string) (net.Conn, error) {
+	return netaddr.Dial(address, 0)
 }
 
 func criticalError(err error) bool {
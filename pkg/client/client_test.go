@@ -1,11 +1,14 @@
 package client
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"os"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/mock/gomock"
 )
 
@@ -14,6 +17,55 @@ func Test_Client(t *testing.T) {
 
 	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
+	t.Run("send trace header of context", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		want := "traceparent=00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01 GET k\n"
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte(want)}).Return(0, nil)
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("GET k\n")}).Return(0, nil)
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "v\n"), nil
+			}).Times(2)
+
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+			TraceFlags: trace.FlagsSampled,
+		}))
+		c := NewClient(socketMock, log)
+		v, err := c.WithContext(ctx).Get("k")
+		require.NoError(t, err)
+		require.Equal(t, "v", v)
+
+		_, err = c.Get("k")
+		require.NoError(t, err)
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("PING\n")}).Return(0, nil)
+		socketMock.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "PONG\n"), nil
+			})
+
+		require.NoError(t, NewClient(socketMock, log).Ping())
+	})
+
 	t.Run("loop stopped when got io.EOF error from readerWriter", func(t *testing.T) {
 		t.Parallel()
 
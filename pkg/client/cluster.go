@@ -0,0 +1,227 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/tmvrus/key-value-storage/internal/cluster"
+)
+
+const maxRedirects = 5
+
+var ErrTooManyRedirects = errors.New("too many cluster redirects")
+
+// ClusterClient sends commands to the node owning the key slot. It caches the
+// slot map loaded with CLUSTER SLOTS, updates it on MOVED and follows ASK for
+// a single command. Like Client, it is not safe for concurrent use.
+type ClusterClient struct {
+	log   *slog.Logger
+	dial  func(address string) (net.Conn, error)
+	seeds []string
+
+	slots [cluster.SlotCount]string
+	conns map[string]nodeConn
+}
+
+type nodeConn struct {
+	conn   net.Conn
+	client *Client
+}
+
+type redirect struct {
+	ask     bool
+	slot    int
+	address string
+}
+
+// NewClusterClient loads the slot map from the first seed address that answers.
+func NewClusterClient(log *slog.Logger, seeds ...string) (*ClusterClient, error) {
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("no seed addresses")
+	}
+
+	c := &ClusterClient{
+		log:   log,
+		dial:  Dial,
+		seeds: seeds,
+		conns: make(map[string]nodeConn),
+	}
+
+	if err := c.RefreshSlots(); err != nil {
+		return nil, errors.Join(err, c.Close())
+	}
+	return c, nil
+}
+
+// RefreshSlots reloads the slot map from the first seed address that answers.
+func (c *ClusterClient) RefreshSlots() error {
+	var errs []error
+	for _, address := range c.seeds {
+		cl, err := c.client(address)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		lines, err := cl.callMulti("CLUSTER", "SLOTS")
+		if err != nil {
+			errs = append(errs, c.connError(address, err))
+			continue
+		}
+		return c.setSlots(lines)
+	}
+
+	return fmt.Errorf("load slots: %w", errors.Join(errs...))
+}
+
+func (c *ClusterClient) Get(key string) (v string, err error) {
+	err = c.do(key, func(cl *Client) error {
+		v, err = cl.Get(key)
+		return err
+	})
+	return
+}
+
+func (c *ClusterClient) Set(key, value string) error {
+	return c.do(key, func(cl *Client) error {
+		return cl.Set(key, value)
+	})
+}
+
+func (c *ClusterClient) Delete(key string) error {
+	return c.do(key, func(cl *Client) error {
+		return cl.Delete(key)
+	})
+}
+
+// Do sends the command with a single line reply to the node owning the key.
+func (c *ClusterClient) Do(key string, args ...string) (v string, err error) {
+	err = c.do(key, func(cl *Client) error {
+		v, err = cl.call(args...)
+		return err
+	})
+	return
+}
+
+func (c *ClusterClient) Close() error {
+	var errs []error
+	for address, nc := range c.conns {
+		errs = append(errs, nc.conn.Close())
+		delete(c.conns, address)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *ClusterClient) do(key string, f func(*Client) error) error {
+	slot := cluster.KeySlot(key)
+	address := c.slots[slot]
+	if address == "" {
+		// any node redirects to the owner
+		address = c.seeds[0]
+	}
+
+	asking := false
+	for i := 0; i <= maxRedirects; i++ {
+		cl, err := c.client(address)
+		if err != nil {
+			return err
+		}
+
+		if asking {
+			if _, err = cl.call("ASKING"); err != nil {
+				return c.connError(address, err)
+			}
+		}
+
+		err = f(cl)
+		r, ok := parseRedirect(err)
+		if !ok {
+			return c.connError(address, err)
+		}
+
+		c.log.Debug("cluster redirect", "slot", r.slot, "address", r.address, "ask", r.ask)
+		if !r.ask {
+			c.slots[r.slot] = r.address
+		}
+		asking = r.ask
+		address = r.address
+	}
+
+	return ErrTooManyRedirects
+}
+
+func (c *ClusterClient) client(address string) (*Client, error) {
+	if nc, ok := c.conns[address]; ok {
+		return nc.client, nil
+	}
+
+	conn, err := c.dial(address)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", address, err)
+	}
+
+	nc := nodeConn{conn: conn, client: NewClient(conn, c.log)}
+	c.conns[address] = nc
+	return nc.client, nil
+}
+
+// connError closes the connection after a network error, so it is dialed again next time.
+func (c *ClusterClient) connError(address string, err error) error {
+	var serverErr ServerError
+	if err == nil || errors.As(err, &serverErr) {
+		return err
+	}
+
+	if nc, ok := c.conns[address]; ok {
+		delete(c.conns, address)
+		if closeErr := nc.conn.Close(); closeErr != nil {
+			c.log.Error("failed to close connection", "address", address, "error", closeErr.Error())
+		}
+	}
+	return err
+}
+
+// setSlots fills the slot map from CLUSTER SLOTS lines: start, end, node ID and address.
+func (c *ClusterClient) setSlots(lines []string) error {
+	var slots [cluster.SlotCount]string
+	for _, l := range lines {
+		parts := strings.Fields(l)
+		if len(parts) != 4 {
+			return fmt.Errorf("invalid slots line %q", l)
+		}
+
+		r, err := cluster.ParseSlotRange(parts[0] + "-" + parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid slots line %q: %w", l, err)
+		}
+		for slot := r.Start; slot <= r.End; slot++ {
+			slots[slot] = parts[3]
+		}
+	}
+
+	c.slots = slots
+	return nil
+}
+
+func parseRedirect(err error) (redirect, bool) {
+	var serverErr ServerError
+	if !errors.As(err, &serverErr) {
+		return redirect{}, false
+	}
+
+	parts := strings.Fields(serverErr.Message)
+	if len(parts) != 3 || (parts[0] != "MOVED" && parts[0] != "ASK") {
+		return redirect{}, false
+	}
+
+	slot, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return redirect{}, false
+	}
+
+	return redirect{ask: parts[0] == "ASK", slot: slot, address: parts[2]}, true
+}
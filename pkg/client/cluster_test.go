@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode replies to every received line with the reply func result.
+type fakeNode struct {
+	address string
+
+	lock     sync.Mutex
+	received []string
+}
+
+func newFakeNode(t *testing.T, reply func(line string) string) *fakeNode {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	n := &fakeNode{address: l.Addr().String()}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				input := bufio.NewScanner(conn)
+				for input.Scan() {
+					n.lock.Lock()
+					n.received = append(n.received, input.Text())
+					n.lock.Unlock()
+
+					if _, err := conn.Write([]byte(reply(input.Text()) + "\n")); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return n
+}
+
+func (n *fakeNode) lines() []string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return append([]string(nil), n.received...)
+}
+
+func Test_ClusterClient(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("follows MOVED and caches the new owner", func(t *testing.T) {
+		t.Parallel()
+
+		b := newFakeNode(t, func(string) string { return "VALUE" })
+		var a *fakeNode
+		a = newFakeNode(t, func(line string) string {
+			if line == "CLUSTER SLOTS" {
+				return "1\n0 16383 a " + a.address
+			}
+			return "ERROR: MOVED 12182 " + b.address
+		})
+
+		c, err := NewClusterClient(log, a.address)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+
+		v, err := c.Get("foo")
+		require.NoError(t, err)
+		require.Equal(t, "VALUE", v)
+
+		v, err = c.Get("foo")
+		require.NoError(t, err)
+		require.Equal(t, "VALUE", v)
+
+		require.Equal(t, []string{"CLUSTER SLOTS", "GET foo"}, a.lines())
+		require.Equal(t, []string{"GET foo", "GET foo"}, b.lines())
+	})
+
+	t.Run("follows ASK for a single command", func(t *testing.T) {
+		t.Parallel()
+
+		b := newFakeNode(t, func(string) string { return "OK" })
+		var a *fakeNode
+		a = newFakeNode(t, func(line string) string {
+			if line == "CLUSTER SLOTS" {
+				return "1\n0 16383 a " + a.address
+			}
+			return "ERROR: ASK 12182 " + b.address
+		})
+
+		c, err := NewClusterClient(log, a.address)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+
+		require.NoError(t, c.Set("foo", "1"))
+		require.NoError(t, c.Set("foo", "2"))
+
+		require.Equal(t, []string{"CLUSTER SLOTS", "SET foo 1", "SET foo 2"}, a.lines())
+		require.Equal(t, []string{"ASKING", "SET foo 1", "ASKING", "SET foo 2"}, b.lines())
+	})
+
+	t.Run("stops after too many redirects", func(t *testing.T) {
+		t.Parallel()
+
+		var a *fakeNode
+		a = newFakeNode(t, func(line string) string {
+			if line == "CLUSTER SLOTS" {
+				return "0"
+			}
+			return "ERROR: MOVED 12182 " + a.address
+		})
+
+		c, err := NewClusterClient(log, a.address)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+
+		require.ErrorIs(t, c.Delete("foo"), ErrTooManyRedirects)
+	})
+
+	t.Run("returns server errors", func(t *testing.T) {
+		t.Parallel()
+
+		a := newFakeNode(t, func(line string) string {
+			if line == "CLUSTER SLOTS" {
+				return "0"
+			}
+			return "ERROR: not found"
+		})
+
+		c, err := NewClusterClient(log, a.address)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+
+		_, err = c.Get("foo")
+		require.Equal(t, ServerError{Message: "not found"}, err)
+	})
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/tmvrus/key-value-storage/pkg/kvspb"
+)
+
+// GRPCClient wraps the generated gRPC client with plain Go types, it is safe
+// for concurrent use. Errors are gRPC statuses, codes.NotFound for absent keys.
+type GRPCClient struct {
+	conn *grpc.ClientConn
+	api  kvspb.KeyValueClient
+}
+
+// WatchEvent is a change of a watched key.
+type WatchEvent struct {
+	Key string
+	// Command is the command which changed the key, like SET or LPUSH.
+	Command string
+	// Value is set for SET only.
+	Value string
+}
+
+// NewGRPCClient connects without TLS unless options say otherwise.
+func NewGRPCClient(address string, opts ...grpc.DialOption) (*GRPCClient, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("grpc client: %w", err)
+	}
+
+	return &GRPCClient{conn: conn, api: kvspb.NewKeyValueClient(conn)}, nil
+}
+
+func (c *GRPCClient) Get(ctx context.Context, key string) (string, error) {
+	resp, err := c.api.Get(ctx, &kvspb.GetRequest{Key: key})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetValue(), nil
+}
+
+func (c *GRPCClient) Set(ctx context.Context, key, value string) error {
+	_, err := c.api.Set(ctx, &kvspb.SetRequest{Key: key, Value: value})
+	return err
+}
+
+func (c *GRPCClient) Delete(ctx context.Context, key string) error {
+	_, err := c.api.Delete(ctx, &kvspb.DeleteRequest{Key: key})
+	return err
+}
+
+// MGet returns values in the order of keys, nil for the absent ones.
+func (c *GRPCClient) MGet(ctx context.Context, keys ...string) ([]*string, error) {
+	resp, err := c.api.MGet(ctx, &kvspb.MGetRequest{Keys: keys})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*string, len(resp.GetValues()))
+	for i, v := range resp.GetValues() {
+		if v.GetFound() {
+			values[i] = &v.Value
+		}
+	}
+	return values, nil
+}
+
+// MSet stores all pairs atomically.
+func (c *GRPCClient) MSet(ctx context.Context, pairs map[string]string) error {
+	req := &kvspb.MSetRequest{Pairs: make([]*kvspb.KeyValuePair, 0, len(pairs))}
+	for k, v := range pairs {
+		req.Pairs = append(req.Pairs, &kvspb.KeyValuePair{Key: k, Value: v})
+	}
+
+	_, err := c.api.MSet(ctx, req)
+	return err
+}
+
+// MDelete reports for every key whether it was deleted.
+func (c *GRPCClient) MDelete(ctx context.Context, keys ...string) ([]bool, error) {
+	resp, err := c.api.MDelete(ctx, &kvspb.MDeleteRequest{Keys: keys})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetDeleted(), nil
+}
+
+// Watch calls f for every change of the listed keys and keys with the prefix,
+// all keys are watched when both are empty. It returns when ctx is done, f
+// fails or the stream breaks.
+func (c *GRPCClient) Watch(ctx context.Context, prefix string, keys []string, f func(WatchEvent) error) error {
+	stream, err := c.api.Watch(ctx, &kvspb.WatchRequest{Keys: keys, Prefix: prefix})
+	if err != nil {
+		return err
+	}
+
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if err = f(WatchEvent{Key: e.GetKey(), Command: e.GetCommand(), Value: e.GetValue()}); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmvrus/key-value-storage/pkg/kvspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeKeyValue answers with fixed replies and records requests.
+type fakeKeyValue struct {
+	kvspb.UnimplementedKeyValueServer
+
+	mset *kvspb.MSetRequest
+}
+
+func (f *fakeKeyValue) Get(_ context.Context, req *kvspb.GetRequest) (*kvspb.GetResponse, error) {
+	return &kvspb.GetResponse{Value: "value of " + req.GetKey()}, nil
+}
+
+func (f *fakeKeyValue) MGet(_ context.Context, req *kvspb.MGetRequest) (*kvspb.MGetResponse, error) {
+	return &kvspb.MGetResponse{Values: []*kvspb.OptionalValue{{Found: true, Value: "1"}, {}}}, nil
+}
+
+func (f *fakeKeyValue) MSet(_ context.Context, req *kvspb.MSetRequest) (*kvspb.MSetResponse, error) {
+	f.mset = req
+	return &kvspb.MSetResponse{}, nil
+}
+
+func (f *fakeKeyValue) Watch(req *kvspb.WatchRequest, stream grpc.ServerStreamingServer[kvspb.WatchEvent]) error {
+	for _, k := range req.GetKeys() {
+		if err := stream.Send(&kvspb.WatchEvent{Key: k, Command: "DELETE"}); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestGRPCClient(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeKeyValue{}
+	srv := grpc.NewServer()
+	kvspb.RegisterKeyValueServer(srv, fake)
+
+	l := bufconn.Listen(1024 * 1024)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+
+	c, err := NewGRPCClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, c.Close()) })
+
+	ctx := context.Background()
+
+	v, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "value of a", v)
+
+	values, err := c.MGet(ctx, "a", "b")
+	require.NoError(t, err)
+	require.Equal(t, "1", *values[0])
+	require.Nil(t, values[1])
+
+	require.NoError(t, c.MSet(ctx, map[string]string{"a": "1"}))
+	require.Equal(t, "a", fake.mset.GetPairs()[0].GetKey())
+	require.Equal(t, "1", fake.mset.GetPairs()[0].GetValue())
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	var events []WatchEvent
+	err = c.Watch(watchCtx, "", []string{"a", "b"}, func(e WatchEvent) error {
+		events = append(events, e)
+		if len(events) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, []WatchEvent{{Key: "a", Command: "DELETE"}, {Key: "b", Command: "DELETE"}}, events)
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FieldValue struct {
+	Field string
+	Value string
+}
+
+type StreamEntry struct {
+	Stream string
+	ID     string
+	Fields []FieldValue
+}
+
+// StreamOffset points to the position to read the stream from: "$" for XRead and ">"
+// for XReadGroup stand for new entries, any other ID returns entries after it.
+type StreamOffset struct {
+	Key string
+	ID  string
+}
+
+type PendingEntry struct {
+	ID         string
+	Consumer   string
+	Idle       time.Duration
+	Deliveries int
+}
+
+// XAdd appends the entry with automatically generated ID to the stream.
+func (c *Client) XAdd(key string, fields ...FieldValue) (string, error) {
+	args := make([]string, 0, 3+len(fields)*2)
+	args = append(args, "XADD", key, "*")
+	for _, f := range fields {
+		args = append(args, f.Field, f.Value)
+	}
+
+	return c.call(args...)
+}
+
+// XRange returns entries with IDs between start and end, "-" and "+" stand for the
+// minimum and maximum IDs. Zero count means no limit.
+func (c *Client) XRange(key, start, end string, count int) ([]StreamEntry, error) {
+	args := []string{"XRANGE", key, start, end}
+	if count > 0 {
+		args = append(args, "COUNT", strconv.Itoa(count))
+	}
+
+	lines, err := c.callMulti(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamEntry, len(lines))
+	for i, l := range lines {
+		if entries[i], err = parseStreamEntry(key + " " + l); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// XRead returns entries added after offsets. Negative block does not wait for entries,
+// zero block waits forever.
+func (c *Client) XRead(count int, block time.Duration, offsets ...StreamOffset) ([]StreamEntry, error) {
+	args := append([]string{"XREAD"}, readOptions(count, block, offsets)...)
+	return c.readStreams(args)
+}
+
+func (c *Client) XGroupCreate(key, group, id string, mkStream bool) error {
+	args := []string{"XGROUP", "CREATE", key, group, id}
+	if mkStream {
+		args = append(args, "MKSTREAM")
+	}
+
+	_, err := c.call(args...)
+	return err
+}
+
+// XReadGroup reads entries on behalf of the consumer of the group, see XRead for
+// count and block meaning.
+func (c *Client) XReadGroup(group, consumer string, count int, block time.Duration, offsets ...StreamOffset) ([]StreamEntry, error) {
+	args := append([]string{"XREADGROUP", "GROUP", group, consumer}, readOptions(count, block, offsets)...)
+	return c.readStreams(args)
+}
+
+// XAck returns the number of acknowledged entries.
+func (c *Client) XAck(key, group string, ids ...string) (int, error) {
+	line, err := c.call(append([]string{"XACK", key, group}, ids...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(line)
+}
+
+func (c *Client) XPending(key, group string) ([]PendingEntry, error) {
+	lines, err := c.callMulti("XPENDING", key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]PendingEntry, len(lines))
+	for i, l := range lines {
+		parts := strings.Split(l, " ")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("invalid pending entry %q", l)
+		}
+		idle, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("parse idle %q: %w", l, err)
+		}
+		deliveries, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return nil, fmt.Errorf("parse deliveries %q: %w", l, err)
+		}
+
+		pending[i] = PendingEntry{
+			ID:         parts[0],
+			Consumer:   parts[1],
+			Idle:       time.Duration(idle) * time.Millisecond,
+			Deliveries: deliveries,
+		}
+	}
+	return pending, nil
+}
+
+func (c *Client) readStreams(args []string) ([]StreamEntry, error) {
+	lines, err := c.callMulti(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamEntry, len(lines))
+	for i, l := range lines {
+		if entries[i], err = parseStreamEntry(l); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func readOptions(count int, block time.Duration, offsets []StreamOffset) []string {
+	var args []string
+	if count > 0 {
+		args = append(args, "COUNT", strconv.Itoa(count))
+	}
+	if block >= 0 {
+		args = append(args, "BLOCK", strconv.FormatInt(block.Milliseconds(), 10))
+	}
+
+	args = append(args, "STREAMS")
+	for _, o := range offsets {
+		args = append(args, o.Key)
+	}
+	for _, o := range offsets {
+		args = append(args, o.ID)
+	}
+	return args
+}
+
+// parseStreamEntry parses the line of stream key, entry ID, fields and values.
+func parseStreamEntry(line string) (StreamEntry, error) {
+	parts := strings.Split(line, " ")
+	if len(parts) < 2 || len(parts)%2 != 0 {
+		return StreamEntry{}, fmt.Errorf("invalid stream entry %q", line)
+	}
+
+	entry := StreamEntry{Stream: parts[0], ID: parts[1]}
+	for i := 2; i+1 < len(parts); i += 2 {
+		entry.Fields = append(entry.Fields, FieldValue{Field: parts[i], Value: parts[i+1]})
+	}
+	return entry, nil
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_ClientStream(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	reply := func(m *MockreaderWriter, s string) {
+		m.
+			EXPECT().
+			Read(gomock.Any()).
+			DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, s), nil
+			})
+	}
+
+	t.Run("XAdd returns generated ID", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("XADD events * user 1\n")}).Return(0, nil)
+		reply(socketMock, "10-0\n")
+
+		id, err := NewClient(socketMock, log).XAdd("events", FieldValue{Field: "user", Value: "1"})
+		require.NoError(t, err)
+		require.Equal(t, "10-0", id)
+	})
+
+	t.Run("XRange parses entries", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("XRANGE events - + COUNT 2\n")}).Return(0, nil)
+		reply(socketMock, "2\n10-0 user 1\n11-0 user 2 action login\n")
+
+		entries, err := NewClient(socketMock, log).XRange("events", "-", "+", 2)
+		require.NoError(t, err)
+		require.Equal(t, []StreamEntry{
+			{Stream: "events", ID: "10-0", Fields: []FieldValue{{"user", "1"}}},
+			{Stream: "events", ID: "11-0", Fields: []FieldValue{{"user", "2"}, {"action", "login"}}},
+		}, entries)
+	})
+
+	t.Run("XReadGroup returns nothing on timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		want := "XREADGROUP GROUP workers alice COUNT 1 BLOCK 100 STREAMS jobs logs > >\n"
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte(want)}).Return(0, nil)
+		reply(socketMock, "(nil)\n")
+
+		entries, err := NewClient(socketMock, log).XReadGroup("workers", "alice", 1, 100*time.Millisecond,
+			StreamOffset{Key: "jobs", ID: ">"}, StreamOffset{Key: "logs", ID: ">"})
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("XPending parses pending entries", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		socketMock := NewMockreaderWriter(ctrl)
+
+		socketMock.EXPECT().Write(byteMatcher{t: t, want: []byte("XPENDING jobs workers\n")}).Return(0, nil)
+		reply(socketMock, "1\n10-0 alice 1500 2\n")
+
+		pending, err := NewClient(socketMock, log).XPending("jobs", "workers")
+		require.NoError(t, err)
+		require.Equal(t, []PendingEntry{{ID: "10-0", Consumer: "alice", Idle: 1500 * time.Millisecond, Deliveries: 2}}, pending)
+	})
+}
@@ -0,0 +1,21 @@
+package client
+
+// Get returns the value stored by the key, ServerError when it is absent. A
+// value stored as "" reads as the empty string, see MGet.
+func (c *Client) Get(key string) (string, error) {
+	v, err := c.call("GET", key)
+	if v == emptyResult {
+		return "", err
+	}
+	return v, err
+}
+
+func (c *Client) Set(key, value string) error {
+	_, err := c.call("SET", key, value)
+	return err
+}
+
+func (c *Client) Delete(key string) error {
+	_, err := c.call("DELETE", key)
+	return err
+}
@@ -0,0 +1,4 @@
+// Package kvspb holds the generated gRPC API of the storage.
+package kvspb
+
+//go:generate sh -c "cd ../../proto && buf generate"